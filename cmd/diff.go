@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/StinkyLord/cpp-sbom-builder/internal/bom"
+)
+
+var (
+	flagDiffFormat string
+	flagDiffOutput string
+	flagDiffFailOn []string
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff old.json new.json",
+	Short: "Compare two SBOMs and report dependency changes",
+	Long: `Compare two CycloneDX JSON SBOMs produced by cpp-sbom-builder and report
+which dependencies were added or removed, which changed version and which
+moved between direct and transitive. Both the standard components/dependencies
+layout and the legacy dependencyTree layout are understood.
+
+With --fail-on, the command exits with status 1 when any change of the listed
+kinds is found, so it can gate a release pipeline.
+
+Examples:
+  cpp-sbom-builder diff release-1.0.json release-1.1.json
+  cpp-sbom-builder diff old.json new.json --format json --output changes.json
+  cpp-sbom-builder diff old.json new.json --fail-on added,version`,
+	Args: cobra.ExactArgs(2),
+	RunE: runDiff,
+}
+
+func init() {
+	diffCmd.Flags().StringVarP(&flagDiffFormat, "format", "f", "text", "Report format: text, json")
+	diffCmd.Flags().StringVarP(&flagDiffOutput, "output", "o", "-", "Report file path (use '-' for stdout)")
+	diffCmd.Flags().StringSliceVar(&flagDiffFailOn, "fail-on", nil,
+		"Exit with status 1 when changes of these kinds are found: "+strings.Join(bom.ChangeKinds, ", ")+", or any")
+
+	rootCmd.AddCommand(diffCmd)
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	failOn, err := parseFailOn(flagDiffFailOn)
+	if err != nil {
+		return err
+	}
+	if flagDiffFormat != "text" && flagDiffFormat != "json" {
+		return fmt.Errorf("unsupported format %q (supported: text, json)", flagDiffFormat)
+	}
+	cmd.SilenceUsage = true
+
+	oldBOM, err := bom.ReadCycloneDX(args[0])
+	if err != nil {
+		return err
+	}
+	newBOM, err := bom.ReadCycloneDX(args[1])
+	if err != nil {
+		return err
+	}
+	report := bom.Diff(oldBOM, newBOM)
+
+	if err := writeDiffReport(report); err != nil {
+		return err
+	}
+
+	var found []string
+	for _, kind := range failOn {
+		if n := report.Count(kind); n > 0 {
+			found = append(found, fmt.Sprintf("%d %s", n, kind))
+		}
+	}
+	if len(found) > 0 {
+		return &exitError{code: 1, msg: "Changes found: " + strings.Join(found, ", ")}
+	}
+	return nil
+}
+
+func writeDiffReport(report *bom.DiffReport) error {
+	if flagDiffOutput == "-" {
+		return encodeDiffReport(os.Stdout, report)
+	}
+	f, err := os.Create(flagDiffOutput)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", flagDiffOutput, err)
+	}
+	if err := encodeDiffReport(f, report); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write diff report: %w", err)
+	}
+	return nil
+}
+
+func encodeDiffReport(w io.Writer, report *bom.DiffReport) error {
+	var err error
+	if flagDiffFormat == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(report)
+	} else {
+		err = report.WriteText(w)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write diff report: %w", err)
+	}
+	return nil
+}
+
+// parseFailOn validates the --fail-on kinds, expanding "any" to all of them.
+func parseFailOn(values []string) ([]string, error) {
+	var kinds []string
+	for _, v := range values {
+		v = strings.ToLower(strings.TrimSpace(v))
+		if v == "any" {
+			return bom.ChangeKinds, nil
+		}
+		valid := false
+		for _, k := range bom.ChangeKinds {
+			if v == k {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, fmt.Errorf("unknown change kind %q for --fail-on (supported: %s, any)",
+				v, strings.Join(bom.ChangeKinds, ", "))
+		}
+		kinds = append(kinds, v)
+	}
+	return kinds, nil
+}
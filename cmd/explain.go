@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/StinkyLord/cpp-sbom-builder/internal/model"
+	"github.com/StinkyLord/cpp-sbom-builder/internal/scanner"
+)
+
+var (
+	flagExplainDir            string
+	flagExplainConanGraph     bool
+	flagExplainCMakeConfigure bool
+	flagExplainLdd            bool
+	flagExplainStrategies     strategyFlags
+)
+
+var explainCmd = &cobra.Command{
+	Use:   "explain <name>",
+	Short: "Show why a component was reported",
+	Long: `Scan a C/C++ project and explain how one component was detected: every
+strategy that reported it, with the file and line (or library path) that
+triggered it and the fingerprint entry that identified the library, and why
+it was classified as a direct or transitive dependency.
+
+The name is matched the way the SBOM deduplicates components, so
+"nlohmann_json" finds "nlohmann-json". Use name@version to pick one version
+when several are present.
+
+Examples:
+  cpp-sbom-builder explain openssl --dir /path/to/project
+  cpp-sbom-builder explain zlib@1.2.13 --conan-graph`,
+	Args: cobra.ExactArgs(1),
+	RunE: runExplain,
+}
+
+func init() {
+	explainCmd.Flags().StringVarP(&flagExplainDir, "dir", "d", ".", "Path to the C++ project root directory")
+	explainCmd.Flags().BoolVar(&flagExplainConanGraph, "conan-graph", false, "Run 'conan graph info' as in scan")
+	explainCmd.Flags().BoolVar(&flagExplainCMakeConfigure, "cmake-configure", false, "Run a CMake configure as in scan")
+	explainCmd.Flags().BoolVar(&flagExplainLdd, "ldd", false, "Use ldd results as in scan")
+	flagExplainStrategies.register(explainCmd)
+
+	rootCmd.AddCommand(explainCmd)
+}
+
+func runExplain(cmd *cobra.Command, args []string) error {
+	absDir, err := filepath.Abs(flagExplainDir)
+	if err != nil {
+		return fmt.Errorf("cannot resolve directory %q: %w", flagExplainDir, err)
+	}
+	if err := flagExplainStrategies.check(); err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+
+	result, err := quietScan(absDir, flagExplainConanGraph, flagExplainCMakeConfigure, flagExplainLdd, &flagExplainStrategies)
+	if err != nil {
+		return err
+	}
+
+	matches := findComponents(result.Components, args[0])
+	if len(matches) == 0 {
+		return fmt.Errorf("no component named %q was detected in %s", args[0], absDir)
+	}
+	for i, c := range matches {
+		if i > 0 {
+			fmt.Println()
+		}
+		printExplanation(c, result.Components, absDir)
+	}
+	return nil
+}
+
+// quietScan scans dir without progress output, for commands that report on
+// the result rather than writing an SBOM. strategies must have been checked.
+func quietScan(dir string, conanGraph, cmakeConfigure, ldd bool, strategies *strategyFlags) (*scanner.Result, error) {
+	s := scanner.New(dir, nil)
+	s.ConanGraph = conanGraph
+	s.CMakeConfigure = cmakeConfigure
+	s.UseLdd = ldd
+	s.Reproducible = true
+	strategies.apply(s)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	result, err := s.ScanContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("scan failed: %w", err)
+	}
+	return result, nil
+}
+
+// findComponents returns the components whose normalized name, or
+// name@version key, matches query.
+func findComponents(components []*model.Component, query string) []*model.Component {
+	name, version, hasVersion := strings.Cut(query, "@")
+	key := model.NormalizeName(name)
+	var out []*model.Component
+	for _, c := range components {
+		if c.NameKey() == key && (!hasVersion || c.Version == version) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func printExplanation(c *model.Component, all []*model.Component, root string) {
+	fmt.Printf("%s %s\n", c.Name, c.Version)
+	if c.PURL != "" {
+		fmt.Printf("  purl:        %s\n", c.PURL)
+	}
+	fmt.Printf("  reported as: %s (highest-confidence source)\n", c.DetectionSource)
+	fmt.Printf("  dependency:  %s — %s\n", c.DependencyType(), c.DependencyTypeReason)
+
+	var parents []string
+	for _, p := range all {
+		for _, child := range p.Dependencies {
+			if model.NormalizeName(child) == c.NameKey() {
+				parents = append(parents, p.Name)
+				break
+			}
+		}
+	}
+	if len(parents) > 0 {
+		fmt.Printf("  required by: %s\n", strings.Join(parents, ", "))
+	}
+	if len(c.Dependencies) > 0 {
+		fmt.Printf("  requires:    %s\n", strings.Join(c.Dependencies, ", "))
+	}
+
+	if len(c.Evidence) == 0 {
+		fmt.Println("  evidence:    none recorded")
+		return
+	}
+	fmt.Println("  evidence:")
+	strategy := ""
+	for _, e := range c.Evidence {
+		if e.Strategy != strategy {
+			strategy = e.Strategy
+			fmt.Printf("    %s\n", strategy)
+		}
+		fmt.Printf("      %s: %s\n", evidenceLocation(e, root), e.Text)
+		if e.Fingerprint != "" {
+			fmt.Printf("        fingerprint: %s\n", e.Fingerprint)
+		}
+	}
+}
+
+// evidenceLocation formats the file and line of e, relative to the project
+// root when the file is inside it.
+func evidenceLocation(e model.Evidence, root string) string {
+	file := e.File
+	if rel, err := filepath.Rel(root, file); err == nil && !strings.HasPrefix(rel, "..") {
+		file = rel
+	}
+	file = filepath.ToSlash(file)
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d", file, e.Line)
+	}
+	return file
+}
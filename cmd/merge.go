@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/StinkyLord/cpp-sbom-builder/internal/bom"
+	"github.com/StinkyLord/cpp-sbom-builder/internal/model"
+	"github.com/StinkyLord/cpp-sbom-builder/internal/output"
+)
+
+var (
+	flagMergeOutput         string
+	flagMergeProjectName    string
+	flagMergeProjectVersion string
+	flagMergeSpecVersion    string
+	flagMergeReproducible   bool
+)
+
+var mergeCmd = &cobra.Command{
+	Use:   "merge a.json b.json [...]",
+	Short: "Merge several SBOMs into one product SBOM",
+	Long: `Merge CycloneDX JSON SBOMs produced by cpp-sbom-builder, typically one per
+repository, into a single SBOM for the product built from them.
+
+Components are deduplicated by normalized name, exactly as a scan merges the
+results of its strategies, and their dependency edges are combined. Each input
+is kept as a sub-assembly of a new root component, depending on its own direct
+dependencies. Libraries that appear at different versions in different inputs
+are kept once per version and reported.
+
+Examples:
+  cpp-sbom-builder merge core.json ui.json net.json -o product.json
+  cpp-sbom-builder merge repos/*.json --project-name product --project-version 4.2.0 -o -`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runMerge,
+}
+
+func init() {
+	mergeCmd.Flags().StringVarP(&flagMergeOutput, "output", "o", "sbom.json", "Output file path (use '-' for stdout)")
+	mergeCmd.Flags().StringVar(&flagMergeProjectName, "project-name", "product", "Name of the root component the inputs are nested under")
+	mergeCmd.Flags().StringVar(&flagMergeProjectVersion, "project-version", "", "Version of the root component")
+	mergeCmd.Flags().StringVar(&flagMergeSpecVersion, "spec-version", output.DefaultSpecVersion,
+		"CycloneDX specification version: "+strings.Join(output.SupportedSpecVersions, ", "))
+	mergeCmd.Flags().BoolVar(&flagMergeReproducible, "reproducible", false,
+		"Derive the serial number from the content and take the timestamp from SOURCE_DATE_EPOCH")
+
+	rootCmd.AddCommand(mergeCmd)
+}
+
+func runMerge(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	var inputs []bom.Input
+	for _, path := range args {
+		b, err := bom.ReadCycloneDX(path)
+		if err != nil {
+			return err
+		}
+		inputs = append(inputs, bom.Input{Source: path, BOM: b})
+	}
+
+	project := &model.Component{
+		Name:    flagMergeProjectName,
+		Version: flagMergeProjectVersion,
+	}
+	if project.Version == "" {
+		project.Version = "unknown"
+	}
+	project.PURL = "pkg:generic/" + strings.ToLower(project.Name)
+	if project.Version != "unknown" {
+		project.PURL += "@" + project.Version
+	}
+
+	result, conflicts := bom.Merge(inputs, project)
+
+	fmt.Fprintf(os.Stderr, "Merged %d SBOM(s): %d component(s)\n", len(inputs), len(result.Components))
+	for _, c := range conflicts {
+		var versions []string
+		for _, v := range c.Versions {
+			versions = append(versions, fmt.Sprintf("%s (%s)", v.Version, strings.Join(v.Sources, ", ")))
+		}
+		fmt.Fprintf(os.Stderr, "Version conflict: %s: %s\n", c.Name, strings.Join(versions, " vs "))
+	}
+
+	opts := output.CycloneDXOptions{
+		ToolVersion:  toolVersion,
+		SpecVersion:  flagMergeSpecVersion,
+		Reproducible: flagMergeReproducible,
+	}
+	if err := output.WriteCycloneDX(result, flagMergeOutput, opts); err != nil {
+		return fmt.Errorf("failed to write merged SBOM: %w", err)
+	}
+	if flagMergeOutput != "-" {
+		fmt.Fprintf(os.Stderr, "SBOM written to: %s\n", flagMergeOutput)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/StinkyLord/cpp-sbom-builder/internal/bom"
+	"github.com/StinkyLord/cpp-sbom-builder/internal/policy"
+	"github.com/StinkyLord/cpp-sbom-builder/internal/scanner"
+)
+
+var (
+	flagPolicyDir            string
+	flagPolicySBOM           string
+	flagPolicyFormat         string
+	flagPolicyOutput         string
+	flagPolicyConanGraph     bool
+	flagPolicyCMakeConfigure bool
+	flagPolicyLdd            bool
+	flagPolicyStrategies     strategyFlags
+)
+
+var policyCmd = &cobra.Command{
+	Use:   "policy policy.json",
+	Short: "Check detected components against a policy",
+	Long: `Evaluate a policy file against a fresh scan of --dir, or against an
+existing CycloneDX SBOM with --sbom, and list every violation.
+
+A policy is a JSON file of rules. Each rule sets one or more conditions
+(licenses, unknownVersion, version, detectedOnlyBy), optionally narrowed to
+some components or to direct or transitive dependencies, and a severity.
+See the README for the format.
+
+Exit status:
+  0  no violations
+  1  the policy or the scan could not be processed
+  2  warnings only
+  3  at least one error-severity violation
+
+Examples:
+  cpp-sbom-builder policy policy.json --dir /path/to/project
+  cpp-sbom-builder policy policy.json --sbom sbom.json --format sarif --output policy.sarif`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPolicy,
+}
+
+func init() {
+	policyCmd.Flags().StringVarP(&flagPolicyDir, "dir", "d", ".", "Path to the C++ project root directory")
+	policyCmd.Flags().StringVar(&flagPolicySBOM, "sbom", "", "Evaluate this CycloneDX JSON SBOM instead of scanning")
+	policyCmd.Flags().StringVarP(&flagPolicyFormat, "format", "f", "text", "Report format: "+strings.Join(policy.Formats, ", "))
+	policyCmd.Flags().StringVarP(&flagPolicyOutput, "output", "o", "-", "Report file path (use '-' for stdout)")
+	policyCmd.Flags().BoolVar(&flagPolicyConanGraph, "conan-graph", false, "Run 'conan graph info' as in scan")
+	policyCmd.Flags().BoolVar(&flagPolicyCMakeConfigure, "cmake-configure", false, "Run a CMake configure as in scan")
+	policyCmd.Flags().BoolVar(&flagPolicyLdd, "ldd", false, "Use ldd results as in scan")
+	flagPolicyStrategies.register(policyCmd)
+
+	rootCmd.AddCommand(policyCmd)
+}
+
+func runPolicy(cmd *cobra.Command, args []string) error {
+	if err := flagPolicyStrategies.check(); err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+	if err := checkPolicyFormat(flagPolicyFormat); err != nil {
+		return err
+	}
+
+	p, err := policy.Load(args[0])
+	if err != nil {
+		return err
+	}
+
+	var result *scanner.Result
+	root := ""
+	if flagPolicySBOM != "" {
+		b, err := bom.ReadCycloneDX(flagPolicySBOM)
+		if err != nil {
+			return err
+		}
+		result = &scanner.Result{Project: b.Project, Components: b.Components}
+	} else {
+		if root, err = filepath.Abs(flagPolicyDir); err != nil {
+			return fmt.Errorf("cannot resolve directory %q: %w", flagPolicyDir, err)
+		}
+		if result, err = quietScan(root, flagPolicyConanGraph, flagPolicyCMakeConfigure, flagPolicyLdd, &flagPolicyStrategies); err != nil {
+			return err
+		}
+	}
+
+	report := policy.Evaluate(p, result, root)
+	if err := writePolicyReport(report, flagPolicyFormat, flagPolicyOutput, os.Stdout); err != nil {
+		return err
+	}
+	if code := report.ExitCode(); code != policy.ExitOK {
+		return &exitError{code: code}
+	}
+	return nil
+}
+
+func checkPolicyFormat(format string) error {
+	for _, f := range policy.Formats {
+		if f == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported policy report format %q (supported: %s)", format, strings.Join(policy.Formats, ", "))
+}
+
+// writePolicyReport writes report to path, or to console when path is "-".
+func writePolicyReport(report *policy.Report, format, path string, console io.Writer) error {
+	if path == "-" {
+		return report.Write(console, format, toolVersion)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("cannot create policy report: %w", err)
+	}
+	if err := report.Write(f, format, toolVersion); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -1,34 +1,60 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/StinkyLord/cpp-sbom-builder/internal/logging"
 	"github.com/StinkyLord/cpp-sbom-builder/internal/output"
+	"github.com/StinkyLord/cpp-sbom-builder/internal/policy"
 	"github.com/StinkyLord/cpp-sbom-builder/internal/scanner"
+	"github.com/StinkyLord/cpp-sbom-builder/internal/validate"
+	"github.com/StinkyLord/cpp-sbom-builder/internal/vulns"
 )
 
 const toolVersion = "1.0.0"
 
 var (
-	flagDir            string
-	flagOutput         string
-	flagFormat         string
-	flagVerbose        bool
-	flagShowStrategies bool
-	flagConanGraph     bool
-	flagCMakeConfigure bool
-	flagLdd            bool
+	flagDir              string
+	flagOutput           string
+	flagFormat           string
+	flagVerbose          bool
+	flagLogLevel         string
+	flagLogFormat        string
+	flagShowStrategies   bool
+	flagConanGraph       bool
+	flagCMakeConfigure   bool
+	flagLdd              bool
+	flagScanStrategies   strategyFlags
+	flagProgress         bool
+	flagDepTree          bool
+	flagSpecVersion      string
+	flagReproducible     bool
+	flagProjectName      string
+	flagProjectVersion   string
+	flagValidate         bool
+	flagVulns            string
+	flagPolicy           string
+	flagScanPolicyFormat string
+	flagScanPolicyOutput string
+	flagDiagnostics      string
+	flagDiagnosticsFmt   string
 )
 
 var rootCmd = &cobra.Command{
 	Use:   "cpp-sbom-builder",
 	Short: "C++ SBOM Generation Engine",
 	Long: `cpp-sbom-builder scans a C++ project directory and produces a Software
-Bill of Materials (SBOM) in CycloneDX JSON format.
+Bill of Materials (SBOM) in CycloneDX (JSON or XML) or SPDX format.
 
 It uses multiple detection strategies to identify third-party dependencies:
   • compile_commands.json  — compiler-level include paths and link flags
@@ -44,21 +70,39 @@ It uses multiple detection strategies to identify third-party dependencies:
 var scanCmd = &cobra.Command{
 	Use:   "scan",
 	Short: "Scan a C++ project and generate an SBOM",
-	Long: `Scan a C++ project directory for third-party dependencies and produce
-a CycloneDX 1.4 JSON SBOM file.
+	Long: `Scan a C++ project directory for third-party dependencies and write an
+SBOM or a report of them. --format selects the output:
+
+  cyclonedx      CycloneDX JSON (default)
+  cyclonedx-xml  CycloneDX XML
+  spdx           SPDX 2.3 JSON
+  spdx3          SPDX 3.0 JSON-LD, with build information
+  deptree        the dependency tree as JSON
+  dot, mermaid   the dependency graph for Graphviz or Mermaid
+  html           a self-contained HTML report
+  sarif          SARIF 2.1.0 code-scanning findings
+
+--spec-version picks the CycloneDX specification version, 1.4 (default), 1.5
+or 1.6, for both CycloneDX formats.
 
 Examples:
   cpp-sbom-builder scan --dir /path/to/project --output sbom.json
   cpp-sbom-builder scan --dir . --output - --verbose
-  cpp-sbom-builder scan --dir /path/to/project --output sbom.json --show-strategies`,
+  cpp-sbom-builder scan --dir /path/to/project --output sbom.json --show-strategies
+  cpp-sbom-builder scan --dir . --spec-version 1.6 --output sbom.json
+  cpp-sbom-builder scan --dir . --format spdx --output sbom.spdx.json`,
 	RunE: runScan,
 }
 
 func init() {
 	scanCmd.Flags().StringVarP(&flagDir, "dir", "d", ".", "Path to the C++ project root directory")
 	scanCmd.Flags().StringVarP(&flagOutput, "output", "o", "sbom.json", "Output file path (use '-' for stdout)")
-	scanCmd.Flags().StringVarP(&flagFormat, "format", "f", "cyclonedx", "Output format: cyclonedx, deptree")
-	scanCmd.Flags().BoolVarP(&flagVerbose, "verbose", "v", false, "Enable verbose output")
+	scanCmd.Flags().StringVarP(&flagFormat, "format", "f", "cyclonedx", "Output format: cyclonedx, cyclonedx-xml, spdx, spdx3, deptree, dot, mermaid, html, sarif")
+	scanCmd.Flags().BoolVarP(&flagVerbose, "verbose", "v", false, "Log what each strategy does (same as --log-level debug)")
+	scanCmd.Flags().StringVar(&flagLogLevel, "log-level", "warn",
+		"Log messages of this level and above to stderr: "+strings.Join(logging.Levels, ", "))
+	scanCmd.Flags().StringVar(&flagLogFormat, "log-format", "text",
+		"Log format: "+strings.Join(logging.Formats, ", "))
 	scanCmd.Flags().BoolVar(&flagShowStrategies, "show-strategies", false, "Print which strategies fired after scanning")
 	scanCmd.Flags().BoolVar(&flagConanGraph, "conan-graph", false,
 		"Walk the project tree for conanfile.py/txt files (at any depth) and run\n"+
@@ -75,12 +119,75 @@ func init() {
 			"Linux only. Designed to run inside the Docker image.\n"+
 			"Reads ldd-results.json if pre-generated, or the SBOM_LDD_RESULTS env var.")
 
+	flagScanStrategies.register(scanCmd)
+	scanCmd.Flags().BoolVar(&flagProgress, "progress", false, "Print each strategy to stderr as it starts and finishes")
+
+	scanCmd.Flags().StringVar(&flagSpecVersion, "spec-version", output.DefaultSpecVersion,
+		"CycloneDX specification version: "+strings.Join(output.SupportedSpecVersions, ", "))
+	scanCmd.Flags().BoolVar(&flagDepTree, "dependency-tree", false,
+		"Also emit the legacy nested 'dependencyTree' field in CycloneDX output.\n"+
+			"The standard 'components' and 'dependencies' arrays are always written.")
+	scanCmd.Flags().BoolVar(&flagReproducible, "reproducible", false,
+		"Produce byte-identical output for identical inputs: derive the serial number\n"+
+			"from the content, take the timestamp from SOURCE_DATE_EPOCH (or the Unix epoch)\n"+
+			"and sort components, edges and arrays.")
+	scanCmd.Flags().StringVar(&flagProjectName, "project-name", "",
+		"Name of the scanned project recorded in metadata.component\n"+
+			"(default: detected from CMakeLists.txt, meson.build or conanfile.py)")
+	scanCmd.Flags().StringVar(&flagProjectVersion, "project-version", "",
+		"Version of the scanned project recorded in metadata.component\n"+
+			"(default: detected alongside the project name)")
+	scanCmd.Flags().BoolVar(&flagValidate, "validate", false,
+		"Check the output against the embedded CycloneDX or SPDX schema and refuse to\n"+
+			"write it when invalid (formats: cyclonedx, spdx, spdx3)")
+	scanCmd.Flags().StringVar(&flagVulns, "vulns", "",
+		"Match components against a local OSV database export (a directory of advisory\n"+
+			"JSON files or an all.zip archive) and record the advisories that affect them\n"+
+			"as CycloneDX vulnerabilities (formats: cyclonedx, cyclonedx-xml)")
+	scanCmd.Flags().StringVar(&flagPolicy, "policy", "",
+		"Evaluate this policy file after writing the SBOM and exit with 2 on warnings\n"+
+			"or 3 on error-severity violations (see the policy command)")
+	scanCmd.Flags().StringVar(&flagScanPolicyFormat, "policy-format", "text",
+		"Policy report format: "+strings.Join(policy.Formats, ", "))
+	scanCmd.Flags().StringVar(&flagScanPolicyOutput, "policy-output", "-",
+		"Policy report file path ('-' for stderr, keeping stdout for the SBOM)")
+	scanCmd.Flags().StringVar(&flagDiagnostics, "diagnostics", "",
+		"Write the problems met while scanning (unreadable or unparsable files,\n"+
+			"failed tools) to this file ('-' for stderr)")
+	scanCmd.Flags().StringVar(&flagDiagnosticsFmt, "diagnostics-format", "text",
+		"Diagnostics report format: "+strings.Join(output.DiagnosticsFormats, ", "))
+
 	rootCmd.AddCommand(scanCmd)
 }
 
+// exitError ends a command with a status other than the 1 that any other
+// error gives, such as a policy gate's. msg, when set, is printed in place
+// of an error; the command has usually reported the details already.
+type exitError struct {
+	code int
+	msg  string
+}
+
+func (e *exitError) Error() string {
+	if e.msg != "" {
+		return e.msg
+	}
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+// Execute runs the command line and exits with its status.
 func Execute() {
+	// Errors are printed here, once, so exit errors can stay silent.
+	rootCmd.SilenceErrors = true
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
+		var exit *exitError
+		if errors.As(err, &exit) {
+			if exit.msg != "" {
+				fmt.Fprintln(os.Stderr, exit.msg)
+			}
+			os.Exit(exit.code)
+		}
+		fmt.Fprintln(os.Stderr, "Error:", err)
 		os.Exit(1)
 	}
 }
@@ -100,19 +207,93 @@ func runScan(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("%q is not a directory", absDir)
 	}
 
+	if flagValidate && !validatedFormats[flagFormat] {
+		return fmt.Errorf("--validate is not supported for format %q (supported: cyclonedx, spdx, spdx3)", flagFormat)
+	}
+	if flagVulns != "" {
+		switch flagFormat {
+		case "cyclonedx", "cdx", "cyclonedx-xml", "cdx-xml":
+		default:
+			return fmt.Errorf("--vulns is not supported for format %q (supported: cyclonedx, cyclonedx-xml)", flagFormat)
+		}
+	}
+
+	if err := flagScanStrategies.check(); err != nil {
+		return err
+	}
+	if flagDiagnostics != "" {
+		if err := checkDiagnosticsFormat(flagDiagnosticsFmt); err != nil {
+			return err
+		}
+	}
+	logLevel := flagLogLevel
+	if flagVerbose && !cmd.Flags().Changed("log-level") {
+		logLevel = "debug"
+	}
+	logger, err := logging.New(os.Stderr, logLevel, flagLogFormat)
+	if err != nil {
+		return err
+	}
+
+	// The flags are checked: from here on, errors are not about usage.
+	cmd.SilenceUsage = true
+
+	// Load the vulnerability database and the policy before scanning, so a
+	// bad path fails fast.
+	var vulnDB *vulns.DB
+	if flagVulns != "" {
+		if vulnDB, err = vulns.Load(flagVulns); err != nil {
+			return err
+		}
+	}
+	var pol *policy.Policy
+	if flagPolicy != "" {
+		if err := checkPolicyFormat(flagScanPolicyFormat); err != nil {
+			return err
+		}
+		if pol, err = policy.Load(flagPolicy); err != nil {
+			return err
+		}
+	}
+
 	fmt.Fprintf(os.Stderr, "cpp-sbom-builder v%s\n", toolVersion)
 	fmt.Fprintf(os.Stderr, "Scanning: %s\n", absDir)
 
-	s := scanner.New(absDir, flagVerbose)
+	s := scanner.New(absDir, logger)
 	s.ConanGraph = flagConanGraph
 	s.CMakeConfigure = flagCMakeConfigure
 	s.UseLdd = flagLdd
-	result, err := s.Scan()
+	s.Reproducible = flagReproducible
+	s.ProjectName = flagProjectName
+	s.ProjectVersion = flagProjectVersion
+	s.CollectBuildInfo = flagFormat == "spdx3" || flagFormat == "spdx3-jsonld"
+	flagScanStrategies.apply(s)
+	if flagProgress {
+		s.Progress = printProgress
+	}
+
+	// Ctrl-C stops running strategies, killing any conan process.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	result, err := s.ScanContext(ctx)
 	if err != nil {
 		return fmt.Errorf("scan failed: %w", err)
 	}
 
 	fmt.Fprintf(os.Stderr, "Found %d component(s)\n", len(result.Components))
+	if errs, warns := output.CountDiagnostics(result.Diagnostics); errs+warns > 0 {
+		fmt.Fprintf(os.Stderr, "Diagnostics: %d error(s), %d warning(s)", errs, warns)
+		if flagDiagnostics == "" {
+			fmt.Fprint(os.Stderr, " (see --diagnostics)")
+		}
+		fmt.Fprintln(os.Stderr)
+	}
+
+	if vulnDB != nil {
+		result.Vulnerabilities = vulnDB.Match(result.Components)
+		fmt.Fprintf(os.Stderr, "Found %d vulnerability advisory(ies) affecting components (of %d loaded)\n",
+			len(result.Vulnerabilities), vulnDB.Len())
+	}
 
 	if flagShowStrategies || flagVerbose {
 		if len(result.StrategiesUsed) > 0 {
@@ -123,22 +304,266 @@ func runScan(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	var report *policy.Report
+	if pol != nil {
+		report = policy.Evaluate(pol, result, absDir)
+	}
+
+	if flagValidate {
+		if err := writeValidated(result, absDir, report); err != nil {
+			return err
+		}
+	} else if err := writeScanOutput(result, absDir, flagOutput, report); err != nil {
+		return err
+	}
+
+	if flagOutput != "-" {
+		fmt.Fprintf(os.Stderr, "SBOM written to: %s\n", flagOutput)
+	}
+
+	if flagDiagnostics != "" {
+		if err := writeDiagnostics(result, flagDiagnosticsFmt, flagDiagnostics, os.Stderr); err != nil {
+			return err
+		}
+	}
+
+	if report != nil {
+		if err := writePolicyReport(report, flagScanPolicyFormat, flagScanPolicyOutput, os.Stderr); err != nil {
+			return err
+		}
+		if code := report.ExitCode(); code != policy.ExitOK {
+			return &exitError{code: code}
+		}
+	}
+
+	return nil
+}
+
+func checkDiagnosticsFormat(format string) error {
+	for _, f := range output.DiagnosticsFormats {
+		if f == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported diagnostics format %q (supported: %s)", format, strings.Join(output.DiagnosticsFormats, ", "))
+}
+
+// writeDiagnostics writes the diagnostics of result to path, or to console
+// when path is "-".
+func writeDiagnostics(result *scanner.Result, format, path string, console io.Writer) error {
+	if path == "-" {
+		return output.WriteDiagnostics(console, result.Diagnostics, format)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("cannot create diagnostics report: %w", err)
+	}
+	if err := output.WriteDiagnostics(f, result.Diagnostics, format); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// strategyFlags are the flags that choose strategies and limit their time,
+// shared by the commands that scan.
+type strategyFlags struct {
+	strategies       []string
+	skip             []string
+	timeout          time.Duration
+	strategyTimeouts []string
+
+	parsedTimeouts map[string]time.Duration // set by check
+}
+
+func (f *strategyFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&f.strategies, "strategies", nil,
+		"Run only these strategies (comma-separated). Naming cmake-configure or ldd\n"+
+			"enables it without its flag. Available:\n"+strings.Join(scanner.StrategyNames(), ", "))
+	cmd.Flags().StringSliceVar(&f.skip, "skip-strategies", nil,
+		"Do not run these strategies (comma-separated), e.g. header-scan")
+	cmd.Flags().DurationVar(&f.timeout, "timeout", 0,
+		"Fail the scan if it takes longer than this (e.g. 10m); 0 means no limit")
+	cmd.Flags().StringSliceVar(&f.strategyTimeouts, "strategy-timeout", nil,
+		"Per-strategy time limits as name=duration (comma-separated), e.g. conan-graph=2m.\n"+
+			"A strategy that runs out of time is skipped and the scan goes on without it")
+}
+
+// check validates the strategy names and parses the per-strategy timeouts.
+func (f *strategyFlags) check() error {
+	for _, names := range [][]string{f.strategies, f.skip} {
+		if err := scanner.CheckStrategyNames(names); err != nil {
+			return err
+		}
+	}
+	timeouts, err := parseStrategyTimeouts(f.strategyTimeouts)
+	if err != nil {
+		return err
+	}
+	f.parsedTimeouts = timeouts
+	return nil
+}
+
+// apply sets the checked flags on s.
+func (f *strategyFlags) apply(s *scanner.Scanner) {
+	s.Strategies = f.strategies
+	s.SkipStrategies = f.skip
+	s.Timeout = f.timeout
+	s.StrategyTimeouts = f.parsedTimeouts
+}
+
+// parseStrategyTimeouts parses --strategy-timeout name=duration pairs.
+func parseStrategyTimeouts(specs []string) (map[string]time.Duration, error) {
+	timeouts := map[string]time.Duration{}
+	for _, spec := range specs {
+		name, value, ok := strings.Cut(spec, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid --strategy-timeout %q (want name=duration, e.g. conan-graph=2m)", spec)
+		}
+		if err := scanner.CheckStrategyNames([]string{name}); err != nil {
+			return nil, err
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --strategy-timeout %q: %w", spec, err)
+		}
+		timeouts[name] = d
+	}
+	return timeouts, nil
+}
+
+// printProgress reports strategy progress on stderr, keeping stdout for the
+// SBOM.
+func printProgress(e scanner.ProgressEvent) {
+	elapsed := e.Elapsed.Round(time.Millisecond)
+	switch {
+	case !e.Finished:
+		fmt.Fprintf(os.Stderr, "  %s: running\n", e.Strategy)
+	case e.Err != nil:
+		fmt.Fprintf(os.Stderr, "  %s: failed after %s: %v\n", e.Strategy, elapsed, e.Err)
+	default:
+		fmt.Fprintf(os.Stderr, "  %s: %d component(s) in %s\n", e.Strategy, e.Components, elapsed)
+	}
+}
+
+// writeScanOutput renders the scan result in the selected --format to path.
+// report holds the --policy violations, if any, for formats that list them.
+func writeScanOutput(result *scanner.Result, absDir, path string, report *policy.Report) error {
 	switch flagFormat {
 	case "cyclonedx", "cdx":
-		if err := output.WriteCycloneDX(result, flagOutput, toolVersion); err != nil {
+		opts := output.CycloneDXOptions{
+			ToolVersion:           toolVersion,
+			SpecVersion:           flagSpecVersion,
+			IncludeDependencyTree: flagDepTree,
+			Reproducible:          flagReproducible,
+		}
+		if err := output.WriteCycloneDX(result, path, opts); err != nil {
 			return fmt.Errorf("failed to write CycloneDX output: %w", err)
 		}
+	case "cyclonedx-xml", "cdx-xml":
+		opts := output.CycloneDXOptions{
+			ToolVersion:  toolVersion,
+			SpecVersion:  flagSpecVersion,
+			Reproducible: flagReproducible,
+		}
+		if err := output.WriteCycloneDXXML(result, path, opts); err != nil {
+			return fmt.Errorf("failed to write CycloneDX XML output: %w", err)
+		}
+	case "spdx", "spdx-json":
+		opts := output.SPDXOptions{
+			ToolVersion:  toolVersion,
+			DocumentName: filepath.Base(absDir),
+			Reproducible: flagReproducible,
+		}
+		if err := output.WriteSPDX(result, path, opts); err != nil {
+			return fmt.Errorf("failed to write SPDX output: %w", err)
+		}
+	case "spdx3", "spdx3-jsonld":
+		opts := output.SPDX3Options{
+			ToolVersion:  toolVersion,
+			DocumentName: filepath.Base(absDir),
+			Reproducible: flagReproducible,
+		}
+		if err := output.WriteSPDX3(result, path, opts); err != nil {
+			return fmt.Errorf("failed to write SPDX 3.0 output: %w", err)
+		}
 	case "deptree", "tree":
-		if err := output.WriteDependencyTree(result, flagOutput); err != nil {
+		if err := output.WriteDependencyTree(result, path); err != nil {
 			return fmt.Errorf("failed to write dependency tree output: %w", err)
 		}
+	case "dot", "graphviz":
+		if err := output.WriteDOT(result, path); err != nil {
+			return fmt.Errorf("failed to write DOT output: %w", err)
+		}
+	case "mermaid", "mmd":
+		if err := output.WriteMermaid(result, path); err != nil {
+			return fmt.Errorf("failed to write Mermaid output: %w", err)
+		}
+	case "html":
+		opts := output.HTMLOptions{
+			ToolVersion:  toolVersion,
+			Title:        filepath.Base(absDir),
+			Reproducible: flagReproducible,
+		}
+		if err := output.WriteHTML(result, path, opts); err != nil {
+			return fmt.Errorf("failed to write HTML report: %w", err)
+		}
+	case "sarif":
+		opts := output.SARIFOptions{
+			ToolVersion: toolVersion,
+			Root:        absDir,
+			Policy:      report,
+		}
+		if err := output.WriteSARIF(result, path, opts); err != nil {
+			return fmt.Errorf("failed to write SARIF output: %w", err)
+		}
 	default:
-		return fmt.Errorf("unsupported format %q (supported: cyclonedx, deptree)", flagFormat)
+		return fmt.Errorf("unsupported format %q (supported: cyclonedx, cyclonedx-xml, spdx, spdx3, deptree, dot, mermaid, html, sarif)", flagFormat)
 	}
+	return nil
+}
 
-	if flagOutput != "-" {
-		fmt.Fprintf(os.Stderr, "SBOM written to: %s\n", flagOutput)
+// validatedFormats are the --format values whose output can be checked
+// against a schema with --validate.
+var validatedFormats = map[string]bool{
+	"cyclonedx": true, "cdx": true,
+	"spdx": true, "spdx-json": true,
+	"spdx3": true, "spdx3-jsonld": true,
+}
+
+// writeValidated renders the output to a temporary file, checks it against
+// the embedded schema and only writes it to --output when it is valid.
+func writeValidated(result *scanner.Result, absDir string, violations *policy.Report) error {
+	tmp, err := os.CreateTemp("", "cpp-sbom-builder-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
 	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
 
-	return nil
+	if err := writeScanOutput(result, absDir, tmp.Name(), violations); err != nil {
+		return err
+	}
+	data, err := os.ReadFile(tmp.Name())
+	if err != nil {
+		return err
+	}
+	report, err := validate.Validate(data)
+	if err != nil {
+		return fmt.Errorf("cannot validate output: %w", err)
+	}
+	if !report.Valid() {
+		var lines []string
+		for _, p := range report.Problems {
+			lines = append(lines, "  "+p.String())
+		}
+		return fmt.Errorf("output is not valid %s, refusing to write it:\n%s", report.Format, strings.Join(lines, "\n"))
+	}
+	fmt.Fprintf(os.Stderr, "Output is valid %s\n", report.Format)
+
+	if flagOutput == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(flagOutput, data, 0644)
 }
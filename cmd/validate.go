@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/StinkyLord/cpp-sbom-builder/internal/validate"
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate sbom.json [...]",
+	Short: "Check SBOMs against the CycloneDX and SPDX schemas",
+	Long: `Check JSON SBOMs against the CycloneDX (1.4, 1.5, 1.6) and SPDX (2.3, 3.0)
+schemas. The format and version are detected from each document, and the
+schemas are embedded in the binary, so no network access is needed.
+
+Every schema violation is listed with the JSON pointer of the offending
+value. Every file is checked, and the command exits with status 1 when any
+of them is invalid or cannot be read.
+
+Examples:
+  cpp-sbom-builder validate sbom.json
+  cpp-sbom-builder validate sbom.cdx.json sbom.spdx.json`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runValidate,
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+}
+
+func runValidate(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	invalid := 0
+	for _, path := range args {
+		r, err := validate.File(path)
+		if err != nil {
+			invalid++
+			fmt.Println(err)
+			continue
+		}
+		if r.Valid() {
+			fmt.Printf("%s: valid %s\n", path, r.Format)
+			continue
+		}
+		invalid++
+		fmt.Printf("%s: invalid %s (%d problem(s))\n", path, r.Format, len(r.Problems))
+		for _, p := range r.Problems {
+			fmt.Printf("  %s\n", p)
+		}
+	}
+
+	if invalid > 0 {
+		return &exitError{code: 1, msg: fmt.Sprintf("%d of %d file(s) failed validation", invalid, len(args))}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/StinkyLord/cpp-sbom-builder/internal/bom"
+	"github.com/StinkyLord/cpp-sbom-builder/internal/model"
+)
+
+var (
+	flagWhyDir            string
+	flagWhySBOM           string
+	flagWhyConanGraph     bool
+	flagWhyCMakeConfigure bool
+	flagWhyLdd            bool
+	flagWhyStrategies     strategyFlags
+	flagWhyMaxPaths       int
+)
+
+var whyCmd = &cobra.Command{
+	Use:   "why <name>",
+	Short: "Show every dependency path that pulls in a component",
+	Long: `List every path through the dependency graph from the project (or, when
+the project is unknown, from a direct dependency) to the named component,
+answering "who pulls in libcrypto?" for transitive libraries found through
+conan-graph, ldd or binary-edges.
+
+The graph comes from a fresh scan of --dir, or from an existing CycloneDX
+SBOM with --sbom. Each edge is annotated with the strategies that reported
+it when that is known.
+
+Examples:
+  cpp-sbom-builder why libcrypto --dir /path/to/project --ldd
+  cpp-sbom-builder why zlib --sbom sbom.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runWhy,
+}
+
+func init() {
+	whyCmd.Flags().StringVarP(&flagWhyDir, "dir", "d", ".", "Path to the C++ project root directory")
+	whyCmd.Flags().StringVar(&flagWhySBOM, "sbom", "", "Read the graph from this CycloneDX JSON SBOM instead of scanning")
+	whyCmd.Flags().BoolVar(&flagWhyConanGraph, "conan-graph", false, "Run 'conan graph info' as in scan")
+	whyCmd.Flags().BoolVar(&flagWhyCMakeConfigure, "cmake-configure", false, "Run a CMake configure as in scan")
+	whyCmd.Flags().BoolVar(&flagWhyLdd, "ldd", false, "Use ldd results as in scan")
+	whyCmd.Flags().IntVar(&flagWhyMaxPaths, "max-paths", 100, "List at most this many paths per component (0: no limit)")
+	flagWhyStrategies.register(whyCmd)
+
+	rootCmd.AddCommand(whyCmd)
+}
+
+func runWhy(cmd *cobra.Command, args []string) error {
+	if err := flagWhyStrategies.check(); err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+
+	var project *model.Component
+	var components []*model.Component
+	if flagWhySBOM != "" {
+		b, err := bom.ReadCycloneDX(flagWhySBOM)
+		if err != nil {
+			return err
+		}
+		project, components = b.Project, b.Components
+	} else {
+		absDir, err := filepath.Abs(flagWhyDir)
+		if err != nil {
+			return fmt.Errorf("cannot resolve directory %q: %w", flagWhyDir, err)
+		}
+		result, err := quietScan(absDir, flagWhyConanGraph, flagWhyCMakeConfigure, flagWhyLdd, &flagWhyStrategies)
+		if err != nil {
+			return err
+		}
+		project, components = result.Project, result.Components
+	}
+
+	targets := findComponents(components, args[0])
+	if len(targets) == 0 {
+		return fmt.Errorf("no component named %q in the dependency graph", args[0])
+	}
+
+	tree := model.IndexDependencies(components)
+	for i, target := range targets {
+		if i > 0 {
+			fmt.Println()
+		}
+		paths, truncated := tree.PathsTo(target, flagWhyMaxPaths)
+		printPaths(target, paths, truncated, project)
+	}
+	return nil
+}
+
+func printPaths(target *model.Component, paths [][]*model.Component, truncated bool, project *model.Component) {
+	fmt.Printf("%s %s (%s)\n", target.Name, target.Version, target.DependencyType())
+	if len(paths) == 0 {
+		fmt.Println("  not reachable from any direct dependency")
+		return
+	}
+	for i, path := range paths {
+		var steps []string
+		if project != nil {
+			steps = append(steps, project.Name)
+		}
+		for _, c := range path {
+			steps = append(steps, c.Name+" "+c.Version)
+		}
+		fmt.Printf("  %d. %s\n", i+1, strings.Join(steps, " → "))
+
+		for j := 1; j < len(path); j++ {
+			parent, child := path[j-1], path[j]
+			if sources := edgeSources(parent, child); len(sources) > 0 {
+				fmt.Printf("       %s → %s: %s\n", parent.Name, child.Name, strings.Join(sources, ", "))
+			}
+		}
+	}
+	if truncated {
+		fmt.Println("  … more paths not shown (see --max-paths)")
+	}
+}
+
+// edgeSources returns the strategies that reported parent depending on
+// child, matching the child by normalized name as the edge map does.
+func edgeSources(parent, child *model.Component) []string {
+	for name, sources := range parent.DependencySources {
+		if model.NormalizeName(name) == child.NameKey() {
+			return sources
+		}
+	}
+	return nil
+}
@@ -2,7 +2,11 @@ module github.com/StinkyLord/cpp-sbom-builder
 
 go 1.25.0
 
-require github.com/spf13/cobra v1.10.2
+require (
+	github.com/santhosh-tekuri/jsonschema/v6 v6.0.2
+	github.com/spf13/cobra v1.10.2
+	golang.org/x/text v0.14.0
+)
 
 require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
@@ -0,0 +1,171 @@
+package bom
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/StinkyLord/cpp-sbom-builder/internal/model"
+)
+
+const standardBOM = `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.5",
+  "metadata": {"component": {"bom-ref": "app@1.0", "type": "application", "name": "app", "version": "1.0"}},
+  "components": [
+    {"bom-ref": "openssl@3.1.4", "type": "library", "name": "openssl", "version": "3.1.4",
+     "properties": [{"name": "cpp-sbom-builder:dependencyType", "value": "direct"},
+                    {"name": "cpp-sbom-builder:detectionSource", "value": "conan"}]},
+    {"bom-ref": "zlib@1.2.13", "type": "library", "name": "zlib", "version": "1.2.13",
+     "licenses": [{"expression": "Zlib"}],
+     "properties": [{"name": "cpp-sbom-builder:dependencyType", "value": "transitive"}]}
+  ],
+  "dependencies": [
+    {"ref": "app@1.0", "dependsOn": ["openssl@3.1.4"]},
+    {"ref": "openssl@3.1.4", "dependsOn": ["zlib@1.2.13"]},
+    {"ref": "zlib@1.2.13"}
+  ]
+}`
+
+const legacyBOM = `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.4",
+  "components": [],
+  "dependencyTree": [
+    {"name": "openssl", "version": "3.1.2", "direct": true,
+     "children": [{"name": "zlib", "version": "1.2.13"}]},
+    {"name": "fmt", "version": "10.1.0", "direct": true,
+     "children": [{"name": "zlib", "version": "1.2.13"}]}
+  ]
+}`
+
+func TestParseCycloneDX_Standard(t *testing.T) {
+	b, err := ParseCycloneDX([]byte(standardBOM))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Project == nil || b.Project.Name != "app" || len(b.Project.Dependencies) != 1 {
+		t.Fatalf("project = %+v, want app depending on openssl", b.Project)
+	}
+	openssl := b.Lookup("openssl")
+	if openssl == nil || !openssl.IsDirect || openssl.DetectionSource != "conan" {
+		t.Fatalf("openssl = %+v, want direct from conan", openssl)
+	}
+	if len(openssl.Dependencies) != 1 || openssl.Dependencies[0] != "zlib" {
+		t.Errorf("openssl dependencies = %v, want [zlib]", openssl.Dependencies)
+	}
+	zlib := b.Lookup("zlib")
+	if zlib == nil || zlib.IsDirect || len(zlib.Licenses) != 1 || zlib.Licenses[0] != "Zlib" {
+		t.Errorf("zlib = %+v, want transitive with license Zlib", zlib)
+	}
+}
+
+func TestParseCycloneDX_LegacyTree(t *testing.T) {
+	b, err := ParseCycloneDX([]byte(legacyBOM))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b.Components) != 3 {
+		t.Fatalf("components = %d, want 3 (zlib shared)", len(b.Components))
+	}
+	if zlib := b.Lookup("zlib"); zlib == nil || zlib.IsDirect {
+		t.Errorf("zlib = %+v, want transitive", zlib)
+	}
+	if fmtLib := b.Lookup("fmt"); fmtLib == nil || !fmtLib.IsDirect {
+		t.Errorf("fmt = %+v, want direct", fmtLib)
+	}
+}
+
+func TestParseCycloneDX_RejectsOtherFormats(t *testing.T) {
+	if _, err := ParseCycloneDX([]byte(`{"bomFormat": "SPDX"}`)); err == nil {
+		t.Error("expected an error for a non-CycloneDX document")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	oldBOM, err := ParseCycloneDX([]byte(legacyBOM))
+	if err != nil {
+		t.Fatal(err)
+	}
+	newBOM, err := ParseCycloneDX([]byte(standardBOM))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := Diff(oldBOM, newBOM)
+	if len(r.Added) != 0 {
+		t.Errorf("added = %+v, want none", r.Added)
+	}
+	if len(r.Removed) != 1 || r.Removed[0].Name != "fmt" {
+		t.Errorf("removed = %+v, want fmt", r.Removed)
+	}
+	if len(r.VersionChanged) != 1 || r.VersionChanged[0] != (VersionChange{Name: "openssl", OldVersion: "3.1.2", NewVersion: "3.1.4"}) {
+		t.Errorf("version changes = %+v, want openssl 3.1.2 -> 3.1.4", r.VersionChanged)
+	}
+	if len(r.TypeChanged) != 0 {
+		t.Errorf("type changes = %+v, want none", r.TypeChanged)
+	}
+	if r.Count(ChangeRemoved) != 1 || r.Empty() {
+		t.Error("Count/Empty disagree with the report")
+	}
+
+	if !Diff(newBOM, newBOM).Empty() {
+		t.Error("an SBOM compared with itself should have no changes")
+	}
+}
+
+func TestDiff_TypeChange(t *testing.T) {
+	oldBOM, _ := ParseCycloneDX([]byte(standardBOM))
+	newBOM, _ := ParseCycloneDX([]byte(standardBOM))
+	newBOM.Lookup("zlib").IsDirect = true
+
+	r := Diff(oldBOM, newBOM)
+	if len(r.TypeChanged) != 1 || r.TypeChanged[0].OldType != "transitive" || r.TypeChanged[0].NewType != "direct" {
+		t.Errorf("type changes = %+v, want zlib transitive -> direct", r.TypeChanged)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	a, err := ParseCycloneDX([]byte(standardBOM))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ParseCycloneDX([]byte(legacyBOM))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	project := &model.Component{Name: "product", Version: "1.0"}
+	result, conflicts := Merge([]Input{{Source: "app.json", BOM: a}, {Source: "repos/tools.json", BOM: b}}, project)
+
+	// openssl is kept once per version, zlib and fmt once each.
+	if len(result.Components) != 4 {
+		t.Errorf("components = %d, want 4", len(result.Components))
+	}
+	if len(conflicts) != 1 || conflicts[0].Name != "openssl" || len(conflicts[0].Versions) != 2 {
+		t.Fatalf("conflicts = %+v, want openssl at two versions", conflicts)
+	}
+	if v := conflicts[0].Versions[1]; v.Version != "3.1.2" || len(v.Sources) != 1 || v.Sources[0] != "repos/tools.json" {
+		t.Errorf("second openssl version = %+v, want 3.1.2 from repos/tools.json", v)
+	}
+
+	if len(result.Assemblies) != 2 {
+		t.Fatalf("assemblies = %d, want one per input", len(result.Assemblies))
+	}
+	if result.Assemblies[0].Name != "app" {
+		t.Errorf("first assembly = %q, want the input's own project", result.Assemblies[0].Name)
+	}
+	tools := result.Assemblies[1]
+	// Edges to the conflicting library stay pinned to each input's version.
+	if tools.Name != "tools" || strings.Join(tools.Dependencies, ",") != "fmt,openssl@3.1.2" {
+		t.Errorf("second assembly = %s -> %v, want tools -> [fmt openssl@3.1.2]", tools.Name, tools.Dependencies)
+	}
+	if app := result.Assemblies[0]; strings.Join(app.Dependencies, ",") != "openssl@3.1.4" {
+		t.Errorf("first assembly dependencies = %v, want [openssl@3.1.4]", app.Dependencies)
+	}
+	if c := result.DependencyTree.Lookup("openssl@3.1.2"); c == nil || c.Version != "3.1.2" {
+		t.Errorf("pinned edge resolves to %+v, want openssl 3.1.2", c)
+	}
+	if result.Project != project || result.DependencyTree == nil {
+		t.Error("merged result should be rooted at the given project with a dependency tree")
+	}
+}
@@ -0,0 +1,320 @@
+// Package bom reads SBOMs produced by cpp-sbom-builder back into the
+// internal model, so existing documents can be compared and combined.
+package bom
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/StinkyLord/cpp-sbom-builder/internal/model"
+)
+
+// propertyPrefix namespaces the tool-specific CycloneDX properties, matching
+// the prefix the output package writes.
+const propertyPrefix = "cpp-sbom-builder:"
+
+// BOM is an SBOM read back from disk.
+type BOM struct {
+	// Project is metadata.component, when the document records one.
+	Project *model.Component
+
+	Components []*model.Component
+}
+
+// Lookup returns the component with the given name, matching on the
+// normalized form, or nil.
+func (b *BOM) Lookup(name string) *model.Component {
+	key := model.NormalizeName(name)
+	for _, c := range b.Components {
+		if c.NameKey() == key {
+			return c
+		}
+	}
+	return nil
+}
+
+// ---- CycloneDX JSON, as written by any supported spec version ----
+
+type cdxDocument struct {
+	BOMFormat string `json:"bomFormat"`
+	Metadata  struct {
+		Component *cdxComponent `json:"component"`
+	} `json:"metadata"`
+	Components   []cdxComponent  `json:"components"`
+	Dependencies []cdxDependency `json:"dependencies"`
+
+	// DependencyTree is the legacy nested layout. Older documents carry only
+	// this and no usable components/dependencies arrays.
+	DependencyTree []treeNode `json:"dependencyTree"`
+}
+
+type cdxComponent struct {
+	BOMRef      string `json:"bom-ref"`
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Description string `json:"description"`
+	PURL        string `json:"purl"`
+	Hashes      []struct {
+		Alg     string `json:"alg"`
+		Content string `json:"content"`
+	} `json:"hashes"`
+	Licenses []struct {
+		License *struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"license"`
+		Expression string `json:"expression"`
+	} `json:"licenses"`
+	ExternalReferences []struct {
+		Type    string `json:"type"`
+		URL     string `json:"url"`
+		Comment string `json:"comment"`
+	} `json:"externalReferences"`
+	Properties []struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	} `json:"properties"`
+	Components []cdxComponent `json:"components"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+// treeNode covers both nested tree shapes the tool has written: the
+// CycloneDX dependencyTree ("direct": true) and the deptree format
+// ("dependencyType": "direct").
+type treeNode struct {
+	Name            string     `json:"name"`
+	Version         string     `json:"version"`
+	PURL            string     `json:"purl"`
+	Direct          bool       `json:"direct"`
+	DependencyType  string     `json:"dependencyType"`
+	Description     string     `json:"description"`
+	DetectionSource string     `json:"detectionSource"`
+	IncludePaths    []string   `json:"includePaths"`
+	LinkLibraries   []string   `json:"linkLibraries"`
+	Children        []treeNode `json:"children"`
+}
+
+// ReadCycloneDX reads a CycloneDX JSON SBOM written by this tool. Documents
+// that only carry the legacy dependencyTree, and bare deptree output, are read
+// from the tree instead.
+func ReadCycloneDX(path string) (*BOM, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	b, err := ParseCycloneDX(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return b, nil
+}
+
+// ParseCycloneDX parses the JSON accepted by ReadCycloneDX.
+func ParseCycloneDX(data []byte) (*BOM, error) {
+	trimmed := strings.TrimSpace(string(data))
+	if strings.HasPrefix(trimmed, "[") {
+		var roots []treeNode
+		if err := json.Unmarshal(data, &roots); err != nil {
+			return nil, fmt.Errorf("invalid dependency tree: %w", err)
+		}
+		return fromTree(roots), nil
+	}
+
+	var doc cdxDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid CycloneDX JSON: %w", err)
+	}
+	if doc.BOMFormat != "" && doc.BOMFormat != "CycloneDX" {
+		return nil, fmt.Errorf("unsupported bomFormat %q", doc.BOMFormat)
+	}
+	if len(doc.Components) == 0 && len(doc.DependencyTree) > 0 {
+		return fromTree(doc.DependencyTree), nil
+	}
+	return fromComponents(&doc), nil
+}
+
+func fromComponents(doc *cdxDocument) *BOM {
+	b := &BOM{}
+	byRef := map[string]*model.Component{}
+	typed := map[*model.Component]bool{}
+	for i := range doc.Components {
+		c, hasType := componentFromCDX(&doc.Components[i])
+		b.Components = append(b.Components, c)
+		byRef[doc.Components[i].BOMRef] = c
+		typed[c] = hasType
+	}
+
+	var projectRef string
+	if p := doc.Metadata.Component; p != nil {
+		b.Project, _ = componentFromCDX(p)
+		projectRef = p.BOMRef
+	}
+
+	referenced := map[*model.Component]bool{}
+	for _, d := range doc.Dependencies {
+		var children []*model.Component
+		for _, ref := range d.DependsOn {
+			if child := byRef[ref]; child != nil {
+				children = append(children, child)
+			}
+		}
+		parent := byRef[d.Ref]
+		if d.Ref == projectRef && b.Project != nil {
+			parent = b.Project
+		}
+		if parent == nil {
+			continue
+		}
+		for _, child := range children {
+			parent.Dependencies = append(parent.Dependencies, child.Name)
+			if parent != b.Project {
+				referenced[child] = true
+			}
+		}
+	}
+
+	// Documents written before dependencyType was recorded: fall back to the
+	// graph shape, as the scanner does.
+	for _, c := range b.Components {
+		if !typed[c] {
+			c.IsDirect = !referenced[c]
+		}
+	}
+	return b
+}
+
+// componentFromCDX converts one CycloneDX component. It reports whether the
+// component recorded its dependency type.
+func componentFromCDX(in *cdxComponent) (*model.Component, bool) {
+	c := &model.Component{
+		Name:        in.Name,
+		Version:     in.Version,
+		Description: in.Description,
+		PURL:        in.PURL,
+	}
+	if c.Version == "" {
+		c.Version = "unknown"
+	}
+
+	hasType := false
+	for _, p := range in.Properties {
+		switch strings.TrimPrefix(p.Name, propertyPrefix) {
+		case "dependencyType":
+			c.IsDirect = p.Value == "direct"
+			hasType = true
+		case "detectionSource":
+			c.DetectionSource = p.Value
+		case "revision":
+			c.Revision = p.Value
+		case "channel":
+			c.Channel = p.Value
+		case "includePath":
+			c.IncludePaths = append(c.IncludePaths, p.Value)
+		case "linkLibrary":
+			c.LinkLibraries = append(c.LinkLibraries, p.Value)
+		}
+	}
+
+	for _, l := range in.Licenses {
+		switch {
+		case l.Expression != "":
+			c.Licenses = append(c.Licenses, l.Expression)
+		case l.License != nil && l.License.ID != "":
+			c.Licenses = append(c.Licenses, l.License.ID)
+		case l.License != nil && l.License.Name != "":
+			c.Licenses = append(c.Licenses, l.License.Name)
+		}
+	}
+	for _, ref := range in.ExternalReferences {
+		if ref.Type == "website" && c.Homepage == "" {
+			c.Homepage = ref.URL
+			continue
+		}
+		c.ExternalReferences = append(c.ExternalReferences, model.ExternalReference{
+			Type: ref.Type, URL: ref.URL, Comment: ref.Comment,
+		})
+	}
+
+	for _, f := range in.Components {
+		if f.Type != "file" {
+			continue
+		}
+		a := model.Artifact{Path: f.Name}
+		for _, h := range f.Hashes {
+			a.Hashes = append(a.Hashes, model.Hash{Algorithm: h.Alg, Value: h.Content})
+		}
+		for _, p := range f.Properties {
+			if p.Name == propertyPrefix+"size" {
+				a.Size, _ = strconv.ParseInt(p.Value, 10, 64)
+			}
+		}
+		c.AddArtifact(a)
+	}
+	return c, hasType
+}
+
+// fromTree flattens a nested tree. A library reached along several paths
+// appears once, with the union of the children seen on every path.
+func fromTree(roots []treeNode) *BOM {
+	b := &BOM{}
+	byKey := map[string]*model.Component{}
+
+	var visit func(n *treeNode) *model.Component
+	visit = func(n *treeNode) *model.Component {
+		version := n.Version
+		if version == "" {
+			version = "unknown"
+		}
+		key := (&model.Component{Name: n.Name, Version: version}).Key()
+		c, seen := byKey[key]
+		if !seen {
+			c = &model.Component{
+				Name:            n.Name,
+				Version:         version,
+				PURL:            n.PURL,
+				Description:     n.Description,
+				DetectionSource: n.DetectionSource,
+				IncludePaths:    n.IncludePaths,
+				LinkLibraries:   n.LinkLibraries,
+			}
+			byKey[key] = c
+			b.Components = append(b.Components, c)
+		}
+		if n.Direct || n.DependencyType == "direct" {
+			c.IsDirect = true
+		}
+		for i := range n.Children {
+			child := visit(&n.Children[i])
+			if !containsName(c.Dependencies, child.Name) {
+				c.Dependencies = append(c.Dependencies, child.Name)
+			}
+		}
+		return c
+	}
+
+	for i := range roots {
+		visit(&roots[i]).IsDirect = true
+	}
+	for _, c := range b.Components {
+		sort.Strings(c.Dependencies)
+	}
+	return b
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,212 @@
+package bom
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/StinkyLord/cpp-sbom-builder/internal/model"
+)
+
+// Change kinds reported by Diff, also accepted by the diff command's
+// --fail-on flag.
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeVersion = "version"
+	ChangeType    = "type"
+)
+
+// ChangeKinds lists every change kind, in report order.
+var ChangeKinds = []string{ChangeAdded, ChangeRemoved, ChangeVersion, ChangeType}
+
+// DiffReport lists how the components of one SBOM differ from another.
+// Components are matched by normalized name.
+type DiffReport struct {
+	Added          []ComponentRef  `json:"added"`
+	Removed        []ComponentRef  `json:"removed"`
+	VersionChanged []VersionChange `json:"versionChanged"`
+	TypeChanged    []TypeChange    `json:"dependencyTypeChanged"`
+}
+
+// ComponentRef identifies a component in a diff report.
+type ComponentRef struct {
+	Name           string `json:"name"`
+	Version        string `json:"version"`
+	PURL           string `json:"purl,omitempty"`
+	DependencyType string `json:"dependencyType"`
+}
+
+// VersionChange is a library present in both SBOMs at different versions.
+type VersionChange struct {
+	Name       string `json:"name"`
+	OldVersion string `json:"oldVersion"`
+	NewVersion string `json:"newVersion"`
+}
+
+// TypeChange is a library that moved between direct and transitive.
+type TypeChange struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	OldType string `json:"oldType"`
+	NewType string `json:"newType"`
+}
+
+// Diff compares two SBOMs. A library present once on each side is reported
+// as a version change when its version differs; when either side carries
+// several versions of it, each version only on one side is reported as added
+// or removed.
+func Diff(oldBOM, newBOM *BOM) *DiffReport {
+	oldByName := groupByName(oldBOM.Components)
+	newByName := groupByName(newBOM.Components)
+
+	r := &DiffReport{
+		Added:          []ComponentRef{},
+		Removed:        []ComponentRef{},
+		VersionChanged: []VersionChange{},
+		TypeChanged:    []TypeChange{},
+	}
+
+	for key, olds := range oldByName {
+		news, ok := newByName[key]
+		if !ok {
+			for _, c := range olds {
+				r.Removed = append(r.Removed, refOf(c))
+			}
+			continue
+		}
+
+		if len(olds) == 1 && len(news) == 1 {
+			o, n := olds[0], news[0]
+			if o.Version != n.Version {
+				r.VersionChanged = append(r.VersionChanged, VersionChange{Name: n.Name, OldVersion: o.Version, NewVersion: n.Version})
+			}
+			if o.IsDirect != n.IsDirect {
+				r.TypeChanged = append(r.TypeChanged, TypeChange{
+					Name: n.Name, Version: n.Version, OldType: o.DependencyType(), NewType: n.DependencyType(),
+				})
+			}
+			continue
+		}
+
+		oldVersions := map[string]*model.Component{}
+		for _, c := range olds {
+			oldVersions[c.Version] = c
+		}
+		newVersions := map[string]*model.Component{}
+		for _, c := range news {
+			newVersions[c.Version] = c
+			if o, ok := oldVersions[c.Version]; !ok {
+				r.Added = append(r.Added, refOf(c))
+			} else if o.IsDirect != c.IsDirect {
+				r.TypeChanged = append(r.TypeChanged, TypeChange{
+					Name: c.Name, Version: c.Version, OldType: o.DependencyType(), NewType: c.DependencyType(),
+				})
+			}
+		}
+		for _, c := range olds {
+			if _, ok := newVersions[c.Version]; !ok {
+				r.Removed = append(r.Removed, refOf(c))
+			}
+		}
+	}
+	for key, news := range newByName {
+		if _, ok := oldByName[key]; !ok {
+			for _, c := range news {
+				r.Added = append(r.Added, refOf(c))
+			}
+		}
+	}
+
+	sortRefs(r.Added)
+	sortRefs(r.Removed)
+	sort.Slice(r.VersionChanged, func(i, j int) bool { return r.VersionChanged[i].Name < r.VersionChanged[j].Name })
+	sort.Slice(r.TypeChanged, func(i, j int) bool { return r.TypeChanged[i].Name < r.TypeChanged[j].Name })
+	return r
+}
+
+// Count returns the number of changes of the given kind.
+func (r *DiffReport) Count(kind string) int {
+	switch kind {
+	case ChangeAdded:
+		return len(r.Added)
+	case ChangeRemoved:
+		return len(r.Removed)
+	case ChangeVersion:
+		return len(r.VersionChanged)
+	case ChangeType:
+		return len(r.TypeChanged)
+	}
+	return 0
+}
+
+// Empty reports whether the two SBOMs had no differences.
+func (r *DiffReport) Empty() bool {
+	for _, kind := range ChangeKinds {
+		if r.Count(kind) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// WriteText prints the report in a human-readable form.
+func (r *DiffReport) WriteText(w io.Writer) error {
+	if r.Empty() {
+		_, err := fmt.Fprintln(w, "No changes.")
+		return err
+	}
+
+	var err error
+	printf := func(format string, args ...any) {
+		if err == nil {
+			_, err = fmt.Fprintf(w, format, args...)
+		}
+	}
+	if len(r.Added) > 0 {
+		printf("Added (%d):\n", len(r.Added))
+		for _, c := range r.Added {
+			printf("  + %s %s (%s)\n", c.Name, c.Version, c.DependencyType)
+		}
+	}
+	if len(r.Removed) > 0 {
+		printf("Removed (%d):\n", len(r.Removed))
+		for _, c := range r.Removed {
+			printf("  - %s %s (%s)\n", c.Name, c.Version, c.DependencyType)
+		}
+	}
+	if len(r.VersionChanged) > 0 {
+		printf("Version changed (%d):\n", len(r.VersionChanged))
+		for _, c := range r.VersionChanged {
+			printf("  ~ %s %s -> %s\n", c.Name, c.OldVersion, c.NewVersion)
+		}
+	}
+	if len(r.TypeChanged) > 0 {
+		printf("Dependency type changed (%d):\n", len(r.TypeChanged))
+		for _, c := range r.TypeChanged {
+			printf("  ~ %s %s: %s -> %s\n", c.Name, c.Version, c.OldType, c.NewType)
+		}
+	}
+	return err
+}
+
+func groupByName(comps []*model.Component) map[string][]*model.Component {
+	out := map[string][]*model.Component{}
+	for _, c := range comps {
+		out[c.NameKey()] = append(out[c.NameKey()], c)
+	}
+	return out
+}
+
+func refOf(c *model.Component) ComponentRef {
+	return ComponentRef{Name: c.Name, Version: c.Version, PURL: c.PURL, DependencyType: c.DependencyType()}
+}
+
+func sortRefs(refs []ComponentRef) {
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].Name != refs[j].Name {
+			return refs[i].Name < refs[j].Name
+		}
+		return refs[i].Version < refs[j].Version
+	})
+}
@@ -0,0 +1,193 @@
+package bom
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/StinkyLord/cpp-sbom-builder/internal/model"
+	"github.com/StinkyLord/cpp-sbom-builder/internal/scanner"
+)
+
+// Input is one SBOM to merge, labelled with where it came from.
+type Input struct {
+	Source string
+	BOM    *BOM
+}
+
+// VersionConflict is a library that the merged inputs carry at more than one
+// known version.
+type VersionConflict struct {
+	Name     string
+	Versions []ConflictingVersion
+}
+
+// ConflictingVersion is one version of a conflicting library and the inputs
+// that carry it.
+type ConflictingVersion struct {
+	Version string
+	Sources []string
+}
+
+// Merge combines several SBOMs into one scan result rooted at project.
+//
+// Components are deduplicated by normalized name, as the scanner does, and
+// folded together with scanner.MergeInto. A component with an unknown version
+// merges into a known one; two different known versions are both kept and
+// reported as a VersionConflict. A component is direct if any input lists it
+// as direct.
+//
+// Every input becomes an assembly of the project: its own project component,
+// or one named after the file, depending on that input's direct dependencies.
+func Merge(inputs []Input, project *model.Component) (*scanner.Result, []VersionConflict) {
+	byName := map[string][]*model.Component{}
+	var order []string
+	sources := map[*model.Component][]string{}
+
+	conflicted := conflictingNames(inputs)
+
+	result := &scanner.Result{Project: project}
+	assemblies := map[string]*model.Component{}
+	for _, in := range inputs {
+		pin := pinVersions(in.BOM, conflicted)
+		for _, c := range in.BOM.Components {
+			clone := *c
+			clone.Dependencies, clone.DependencySources = pin(c.Dependencies, c.DependencySources)
+
+			key := c.NameKey()
+			if _, ok := byName[key]; !ok {
+				order = append(order, key)
+			}
+			target := mergeTarget(byName[key], &clone)
+			if target == nil {
+				target = &clone
+				byName[key] = append(byName[key], target)
+			} else {
+				scanner.MergeInto(target, &clone)
+			}
+			sources[target] = appendUnique(sources[target], in.Source)
+		}
+
+		// The same project scanned twice is one assembly.
+		a := assemblyFor(in)
+		a.Dependencies, _ = pin(a.Dependencies, nil)
+		if existing, ok := assemblies[a.Key()]; ok {
+			scanner.MergeInto(existing, a)
+			continue
+		}
+		assemblies[a.Key()] = a
+		result.Assemblies = append(result.Assemblies, a)
+	}
+
+	var conflicts []VersionConflict
+	for _, key := range order {
+		group := byName[key]
+		result.Components = append(result.Components, group...)
+		if len(group) < 2 {
+			continue
+		}
+		conflict := VersionConflict{Name: group[0].Name}
+		for _, c := range group {
+			conflict.Versions = append(conflict.Versions, ConflictingVersion{Version: c.Version, Sources: sources[c]})
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].Name < conflicts[j].Name })
+
+	result.DependencyTree = model.BuildDependencyTree(result.Components)
+	return result, conflicts
+}
+
+// conflictingNames returns the normalized names of libraries that the inputs
+// carry at more than one known version.
+func conflictingNames(inputs []Input) map[string]bool {
+	versions := map[string]map[string]bool{}
+	for _, in := range inputs {
+		for _, c := range in.BOM.Components {
+			if c.Version == "unknown" {
+				continue
+			}
+			if versions[c.NameKey()] == nil {
+				versions[c.NameKey()] = map[string]bool{}
+			}
+			versions[c.NameKey()][c.Version] = true
+		}
+	}
+	conflicted := map[string]bool{}
+	for key, vs := range versions {
+		if len(vs) > 1 {
+			conflicted[key] = true
+		}
+	}
+	return conflicted
+}
+
+// pinVersions returns a function rewriting an input's dependency names.
+// Edges to a conflicting library are pinned to the version this input
+// carries, by its name@version Key, so each input keeps pointing at its own
+// version once both are merged side by side.
+func pinVersions(b *BOM, conflicted map[string]bool) func([]string, map[string][]string) ([]string, map[string][]string) {
+	return func(deps []string, depSources map[string][]string) ([]string, map[string][]string) {
+		if len(conflicted) == 0 {
+			return deps, depSources
+		}
+		pinned := make([]string, 0, len(deps))
+		var pinnedSources map[string][]string
+		if depSources != nil {
+			pinnedSources = make(map[string][]string, len(depSources))
+		}
+		for _, d := range deps {
+			name := d
+			if child := b.Lookup(d); child != nil && conflicted[child.NameKey()] {
+				name = child.Key()
+			}
+			pinned = append(pinned, name)
+			if srcs, ok := depSources[d]; ok {
+				pinnedSources[name] = srcs
+			}
+		}
+		return pinned, pinnedSources
+	}
+}
+
+// mergeTarget returns the already-merged component c folds into: the one
+// with the same version, or any one when either version is unknown. It
+// returns nil when c is a new library or a conflicting version.
+func mergeTarget(group []*model.Component, c *model.Component) *model.Component {
+	for _, existing := range group {
+		if existing.Version == c.Version {
+			return existing
+		}
+	}
+	for _, existing := range group {
+		if existing.Version == "unknown" || c.Version == "unknown" {
+			return existing
+		}
+	}
+	return nil
+}
+
+// assemblyFor describes one merged input as a sub-assembly.
+func assemblyFor(in Input) *model.Component {
+	if p := in.BOM.Project; p != nil {
+		a := *p
+		return &a
+	}
+
+	name := strings.TrimSuffix(filepath.Base(in.Source), filepath.Ext(in.Source))
+	a := &model.Component{Name: name, Version: "unknown", PURL: "pkg:generic/" + strings.ToLower(name)}
+	for _, c := range in.BOM.Components {
+		if c.IsDirect {
+			a.Dependencies = appendUnique(a.Dependencies, c.Name)
+		}
+	}
+	sort.Strings(a.Dependencies)
+	return a
+}
+
+func appendUnique(list []string, s string) []string {
+	if containsName(list, s) {
+		return list
+	}
+	return append(list, s)
+}
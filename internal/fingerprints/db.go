@@ -5,7 +5,10 @@
 // This is used as a fallback when compiler artifacts are not available.
 package fingerprints
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+)
 
 // LibraryFingerprint describes how to recognise a known C++ library.
 type LibraryFingerprint struct {
@@ -436,17 +439,38 @@ func IsStdlibHeader(include string) bool {
 // headers match the given string (an include path or header name).
 // Returns nil if no match is found.
 func MatchLibrary(s string) *LibraryFingerprint {
+	if m := Explain(s); m != nil {
+		return m.Library
+	}
+	return nil
+}
+
+// Match records which entry of a fingerprint matched a string.
+type Match struct {
+	Library *LibraryFingerprint
+	Kind    string // "path segment" or "header"
+	Pattern string // The PathSegments or Headers entry that matched
+}
+
+// String describes the match, e.g. `openssl (path segment "ssl")`.
+func (m *Match) String() string {
+	return fmt.Sprintf("%s (%s %q)", m.Library.Name, m.Kind, m.Pattern)
+}
+
+// Explain is MatchLibrary, also reporting which path segment or header of
+// the fingerprint matched. Returns nil if no match is found.
+func Explain(s string) *Match {
 	lower := strings.ToLower(s)
 	for i := range KnownLibraries {
 		fp := &KnownLibraries[i]
 		for _, seg := range fp.PathSegments {
 			if strings.Contains(lower, strings.ToLower(seg)) {
-				return fp
+				return &Match{Library: fp, Kind: "path segment", Pattern: seg}
 			}
 		}
 		for _, hdr := range fp.Headers {
 			if strings.Contains(lower, strings.ToLower(hdr)) {
-				return fp
+				return &Match{Library: fp, Kind: "header", Pattern: hdr}
 			}
 		}
 	}
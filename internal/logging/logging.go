@@ -0,0 +1,50 @@
+// Package logging builds the leveled logger that the scanner and its
+// strategies report through. Log records go to stderr, so the SBOM can be
+// written to stdout.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Levels and Formats list the accepted --log-level and --log-format values.
+var (
+	Levels  = []string{"debug", "info", "warn", "error"}
+	Formats = []string{"text", "json"}
+)
+
+// New returns a logger writing records at level and above to w, as
+// logfmt-style text or as one JSON object per line.
+func New(w io.Writer, level, format string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if !isLevel(level) || lvl.UnmarshalText([]byte(level)) != nil {
+		return nil, fmt.Errorf("unsupported log level %q (supported: %s)", level, strings.Join(Levels, ", "))
+	}
+	opts := &slog.HandlerOptions{Level: lvl}
+	switch format {
+	case "text":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	}
+	return nil, fmt.Errorf("unsupported log format %q (supported: %s)", format, strings.Join(Formats, ", "))
+}
+
+// Discard returns a logger that drops every record.
+func Discard() *slog.Logger {
+	return slog.New(slog.DiscardHandler)
+}
+
+// isLevel reports whether level is one of Levels. slog.Level also accepts
+// offsets such as "info+2", which the flag does not.
+func isLevel(level string) bool {
+	for _, l := range Levels {
+		if strings.EqualFold(l, level) {
+			return true
+		}
+	}
+	return false
+}
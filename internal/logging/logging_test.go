@@ -0,0 +1,39 @@
+package logging
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	var buf strings.Builder
+	log, err := New(&buf, "info", "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	log.Debug("hidden")
+	log.With("strategy", "conan").Info("parsing", "file", "conan.lock")
+	var rec map[string]any
+	if err := json.Unmarshal([]byte(buf.String()), &rec); err != nil {
+		t.Fatalf("want one JSON record, got %q: %v", buf.String(), err)
+	}
+	if rec["level"] != "INFO" || rec["msg"] != "parsing" || rec["strategy"] != "conan" || rec["file"] != "conan.lock" {
+		t.Errorf("record = %v", rec)
+	}
+
+	buf.Reset()
+	if log, err = New(&buf, "DEBUG", "text"); err != nil {
+		t.Fatal(err)
+	}
+	log.Debug("parsing", "file", "vcpkg.json")
+	if !strings.Contains(buf.String(), `level=DEBUG msg=parsing file=vcpkg.json`) {
+		t.Errorf("text record = %q", buf.String())
+	}
+
+	for _, bad := range [][2]string{{"info+2", "text"}, {"verbose", "text"}, {"info", "xml"}} {
+		if _, err := New(&buf, bad[0], bad[1]); err == nil {
+			t.Errorf("New(%q, %q) accepted", bad[0], bad[1])
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package model
+
+// BuildInvocation is a single compiler or linker command line recovered from
+// build-system artifacts (compile_commands.json entries, CMake link.txt files).
+// It describes how the project was built rather than what it depends on.
+type BuildInvocation struct {
+	Kind       string   // "compile" or "link"
+	Arguments  []string // Full argv, including the compiler/linker as Arguments[0]
+	Directory  string   // Working directory the command runs in
+	Input      string   // Translation unit for compile invocations, if known
+	Output     string   // Value of -o / /Fo / /OUT:, if present
+	SourceFile string   // Artifact the invocation was read from
+}
+
+// Tool returns the compiler or linker executable of the invocation.
+func (b *BuildInvocation) Tool() string {
+	if len(b.Arguments) == 0 {
+		return ""
+	}
+	return b.Arguments[0]
+}
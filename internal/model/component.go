@@ -12,9 +12,114 @@ type Component struct {
 	LinkLibraries   []string // Linked library names (e.g., "boost_system", "ssl")
 	Description     string   // Optional description from manifest
 
+	// Package metadata from manifests that declare it (conan-graph, vcpkg
+	// port manifests, meson wraps)
+	Licenses           []string            // Declared licenses: SPDX expressions or free-text names
+	Homepage           string              // Project homepage URL
+	ExternalReferences []ExternalReference // Other URLs describing the component
+
+	// Artifacts are the concrete files (shared/static libraries) the component
+	// was detected through, with their digests
+	Artifacts []Artifact
+
+	// Evidence lists every observation, from every strategy, that led to the
+	// component being reported
+	Evidence []Evidence
+
+	// Sources records every strategy that reported the component, with the
+	// file and version it reported, where DetectionSource keeps only the
+	// most reliable one
+	Sources []Source
+
 	// Dependency hierarchy fields
 	IsDirect     bool     // true = directly used by the project; false = transitive
 	Dependencies []string // children
+
+	// DependencyTypeReason explains why IsDirect was set the way it was
+	// (e.g. "declared in a Conan manifest (conan)")
+	DependencyTypeReason string
+
+	// DependencySources maps each entry of Dependencies to the strategies
+	// that reported the edge (e.g. "conan-graph", "ldd")
+	DependencySources map[string][]string
+}
+
+// ExternalReference is a URL related to a component. Type uses the CycloneDX
+// external reference vocabulary ("vcs", "distribution", "build-meta", ...).
+type ExternalReference struct {
+	Type    string
+	URL     string
+	Comment string
+}
+
+// Artifact is a library file on disk that a component was detected through.
+type Artifact struct {
+	Path   string
+	Size   int64
+	Hashes []Hash
+}
+
+// Evidence is one observation that made a strategy report a component.
+type Evidence struct {
+	Strategy    string // Strategy that made the observation
+	File        string // File the observation was made in
+	Line        int    // 1-based line in File, or 0 when the file is not line-oriented
+	Text        string // What was matched: a manifest entry, include path, linker input...
+	Fingerprint string // Fingerprint entry that identified the library, if one was used
+}
+
+// Source is one strategy reporting a component.
+type Source struct {
+	Strategy string // Strategy that reported the component
+	File     string // File it was reported from, relative to the project root when inside it; empty if unknown
+	Version  string // Version the strategy reported, "unknown" when it found none
+}
+
+// String renders s as "conan: conan.lock, version 1.3.0".
+func (s Source) String() string {
+	out := s.Strategy
+	if s.File != "" {
+		out += ": " + s.File
+	}
+	return out + ", version " + s.Version
+}
+
+// Hash is a file digest. Algorithm uses the CycloneDX names ("SHA-256",
+// "SHA-512"); Value is lower-case hex.
+type Hash struct {
+	Algorithm string
+	Value     string
+}
+
+// AddArtifact records a, unless an artifact with the same path is already
+// present.
+func (c *Component) AddArtifact(a Artifact) {
+	for _, existing := range c.Artifacts {
+		if existing.Path == a.Path {
+			return
+		}
+	}
+	c.Artifacts = append(c.Artifacts, a)
+}
+
+// AddEvidence records e, unless identical evidence is already present.
+func (c *Component) AddEvidence(e Evidence) {
+	for _, existing := range c.Evidence {
+		if existing == e {
+			return
+		}
+	}
+	c.Evidence = append(c.Evidence, e)
+}
+
+// AddSource records s, unless an identical source is already present.
+func (c *Component) AddSource(s Source) {
+	for _, existing := range c.Sources {
+		if existing == s {
+			return
+		}
+	}
+	c.Sources = append(c.Sources, s)
 }
 
 // Key returns a normalized deduplication key for the component.
@@ -26,6 +131,20 @@ func (c *Component) Key() string {
 	return normalizeKey(c.Name) + "@" + c.Version
 }
 
+// NameKey returns the normalized name without the version, identifying the
+// same library across scans even when its version changes.
+func (c *Component) NameKey() string {
+	return normalizeKey(c.Name)
+}
+
+// BOMRef returns a stable identifier for the component, suitable for use as a
+// CycloneDX bom-ref or an SPDX element ID seed. It is derived from Key(), so
+// the same library at the same version always gets the same reference across
+// scans.
+func (c *Component) BOMRef() string {
+	return c.Key()
+}
+
 // normalizeKey returns a normalized map key for a name string:
 // lowercase, with underscores and dots replaced by hyphens.
 func normalizeKey(name string) string {
@@ -43,6 +162,12 @@ func normalizeKey(name string) string {
 	return string(result)
 }
 
+// NormalizeName returns name normalized the way NameKey normalizes a
+// component's, for matching a user-supplied name against components.
+func NormalizeName(name string) string {
+	return normalizeKey(name)
+}
+
 // DependencyType returns "direct" or "transitive" for use in SBOM output.
 func (c *Component) DependencyType() string {
 	if c.IsDirect {
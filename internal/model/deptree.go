@@ -38,7 +38,8 @@ type DependencyTree struct {
 	// All is the union of Direct and Transitive, deduplicated.
 	All []*Component
 
-	// ByName provides O(1) lookup of any component by its lowercase name.
+	// ByName provides O(1) lookup of any component by its lowercase name,
+	// or by its name@version Key when several versions share a name.
 	ByName map[string]*Component
 
 	// Roots is the recursive tree: only direct dependencies at the top level,
@@ -47,7 +48,19 @@ type DependencyTree struct {
 	Roots []*TreeNode
 }
 
+// BuildDependencyTree indexes components and builds the recursive tree of
+// Roots. The tree repeats a shared subtree under every parent, so it grows
+// with the number of paths through the graph.
 func BuildDependencyTree(components []*Component) *DependencyTree {
+	tree := IndexDependencies(components)
+	tree.Roots = tree.buildTree()
+	return tree
+}
+
+// IndexDependencies is BuildDependencyTree without Roots, for callers that
+// only look components up or walk paths with PathsTo, and so stay linear on
+// graphs of many shared dependencies.
+func IndexDependencies(components []*Component) *DependencyTree {
 	tree := &DependencyTree{
 		ByName: make(map[string]*Component, len(components)),
 	}
@@ -56,6 +69,7 @@ func BuildDependencyTree(components []*Component) *DependencyTree {
 		tree.All = append(tree.All, c)
 		tree.ByName[normalizeKey(c.Name)] = c
 		tree.ByName[c.Name] = c
+		tree.ByName[c.Key()] = c
 
 		if c.IsDirect {
 			tree.Direct = append(tree.Direct, c)
@@ -63,11 +77,20 @@ func BuildDependencyTree(components []*Component) *DependencyTree {
 			tree.Transitive = append(tree.Transitive, c)
 		}
 	}
-
-	tree.Roots = tree.buildTree()
 	return tree
 }
 
+// Lookup returns the component with the given name, matching on the
+// normalized form so "nlohmann_json" finds "nlohmann-json". A Key such as
+// "openssl@3.1.4" selects one version of a library. It returns nil if no such
+// component exists.
+func (t *DependencyTree) Lookup(name string) *Component {
+	if c, ok := t.ByName[name]; ok {
+		return c
+	}
+	return t.ByName[normalizeKey(name)]
+}
+
 // workItem holds a pending node to be expanded along with the set of ancestor
 // keys on the path from the root to this node (used for cycle detection).
 type workItem struct {
@@ -136,7 +159,7 @@ func (t *DependencyTree) buildTree() []*TreeNode {
 		sort.Strings(childNames)
 
 		for _, childName := range childNames {
-			childComp := t.ByName[normalizeKey(childName)]
+			childComp := t.Lookup(childName)
 			if childComp == nil {
 				// Referenced in an edge but not in the component list —
 				// emit a placeholder leaf node (no further expansion needed).
@@ -189,3 +212,119 @@ func (t *DependencyTree) buildTree() []*TreeNode {
 
 	return roots
 }
+
+// PathsTo returns the dependency paths that lead from a direct dependency to
+// target. Each path starts at a direct component and ends at target; a
+// direct target is its own one-element path. Paths never revisit a
+// component, so cycles in the graph do not repeat, and they stop at target
+// rather than passing through it. Paths are ordered by their component names.
+//
+// A graph of stacked diamonds has exponentially many paths, so at most limit
+// paths are returned, the first in that order; truncated reports whether
+// there were more. A limit of 0 or less means no limit.
+//
+// The walk only descends into components from which target can be reached,
+// found by a breadth-first search backwards from target. Like buildTree, it
+// uses an explicit stack rather than recursion.
+func (t *DependencyTree) PathsTo(target *Component, limit int) (paths [][]*Component, truncated bool) {
+	targetKey := target.Key()
+	reaches := t.reaching(target)
+
+	directs := make([]*Component, 0, len(t.Direct))
+	for _, c := range t.Direct {
+		if reaches[c.Key()] {
+			directs = append(directs, c)
+		}
+	}
+	sort.Slice(directs, func(i, j int) bool {
+		return directs[i].Name < directs[j].Name
+	})
+
+	// Pushed in reverse so the stack pops them in name order.
+	var stack [][]*Component
+	for i := len(directs) - 1; i >= 0; i-- {
+		stack = append(stack, []*Component{directs[i]})
+	}
+
+	for len(stack) > 0 {
+		path := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		last := path[len(path)-1]
+		if last.Key() == targetKey {
+			if limit > 0 && len(paths) == limit {
+				return paths, true
+			}
+			paths = append(paths, path)
+			continue
+		}
+
+		children := t.children(last)
+		for i := len(children) - 1; i >= 0; i-- {
+			child := children[i]
+			if !reaches[child.Key()] || onPath(path, child) {
+				continue
+			}
+			next := make([]*Component, len(path), len(path)+1)
+			copy(next, path)
+			stack = append(stack, append(next, child))
+		}
+	}
+
+	return paths, false
+}
+
+// children returns the components c depends on, sorted by name, each once
+// even when several of its dependency names resolve to it.
+func (t *DependencyTree) children(c *Component) []*Component {
+	names := make([]string, len(c.Dependencies))
+	copy(names, c.Dependencies)
+	sort.Strings(names)
+
+	var out []*Component
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		child := t.Lookup(name)
+		if child == nil || seen[child.Key()] {
+			continue
+		}
+		seen[child.Key()] = true
+		out = append(out, child)
+	}
+	return out
+}
+
+// reaching returns the keys of the components from which target can be
+// reached, target included, by a breadth-first search along reversed edges.
+func (t *DependencyTree) reaching(target *Component) map[string]bool {
+	parents := map[string][]*Component{}
+	for _, c := range t.All {
+		for _, child := range t.children(c) {
+			parents[child.Key()] = append(parents[child.Key()], c)
+		}
+	}
+
+	reaches := map[string]bool{target.Key(): true}
+	queue := []*Component{target}
+	for len(queue) > 0 {
+		c := queue[0]
+		queue = queue[1:]
+		for _, p := range parents[c.Key()] {
+			if !reaches[p.Key()] {
+				reaches[p.Key()] = true
+				queue = append(queue, p)
+			}
+		}
+	}
+	return reaches
+}
+
+// onPath reports whether c already appears in path.
+func onPath(path []*Component, c *Component) bool {
+	for _, p := range path {
+		if p.Key() == c.Key() {
+			return true
+		}
+	}
+	return false
+}
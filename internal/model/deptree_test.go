@@ -0,0 +1,105 @@
+package model
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// paths drops PathsTo's truncated result.
+func paths(p [][]*Component, _ bool) [][]*Component { return p }
+
+func TestPathsTo(t *testing.T) {
+	comp := func(name string, direct bool, deps ...string) *Component {
+		return &Component{Name: name, Version: "1", IsDirect: direct, Dependencies: deps}
+	}
+	// app → openssl → libcrypto, app → curl → openssl → libcrypto,
+	// app → curl → libcrypto, with a cycle libcrypto ↔ zlib.
+	openssl := comp("openssl", true, "libcrypto")
+	curl := comp("curl", true, "openssl", "libcrypto")
+	libcrypto := comp("libcrypto", false, "zlib")
+	zlib := comp("zlib", false, "libcrypto")
+	tree := BuildDependencyTree([]*Component{openssl, curl, libcrypto, zlib})
+
+	var got []string
+	for _, p := range paths(tree.PathsTo(libcrypto, 0)) {
+		var names []string
+		for _, c := range p {
+			names = append(names, c.Name)
+		}
+		got = append(got, strings.Join(names, " > "))
+	}
+	want := []string{
+		"curl > libcrypto",
+		"curl > openssl > libcrypto",
+		"openssl > libcrypto",
+	}
+	if strings.Join(got, "; ") != strings.Join(want, "; ") {
+		t.Errorf("PathsTo(libcrypto) = %q, want %q", got, want)
+	}
+
+	if p, _ := tree.PathsTo(openssl, 0); len(p) != 2 || len(p[1]) != 1 {
+		t.Errorf("PathsTo(openssl) = %v, want [curl openssl] and [openssl]", p)
+	}
+}
+
+func TestPathsTo_Diamonds(t *testing.T) {
+	// 30 stacked diamonds, d0 → {l0, r0} → d1 → … → d30 → target, have 2^30
+	// paths to target. Beside them hang a wide tree that cannot reach target
+	// and a cycle through the diamonds' bottom.
+	const n = 30
+	var components []*Component
+	comp := func(name string, direct bool, deps ...string) *Component {
+		c := &Component{Name: name, Version: "1", IsDirect: direct, Dependencies: deps}
+		components = append(components, c)
+		return c
+	}
+	name := func(prefix string, i int) string { return prefix + strconv.Itoa(i) }
+	comp("d0", true, "l0", "r0", "unrelated")
+	for i := 0; i < n; i++ {
+		comp(name("l", i), false, name("d", i+1))
+		comp(name("r", i), false, name("d", i+1), name("d", i+1))
+		if i > 0 {
+			comp(name("d", i), false, name("l", i), name("r", i))
+		}
+	}
+	comp(name("d", n), false, "target", "d0")
+	target := comp("target", false)
+	comp("unrelated", false, "u0", "u1", "u2")
+	for i := 0; i < 3; i++ {
+		comp(name("u", i), false, "u0", "u1", "u2")
+	}
+	tree := IndexDependencies(components)
+
+	got, truncated := tree.PathsTo(target, 10)
+	if len(got) != 10 || !truncated {
+		t.Fatalf("PathsTo(target, 10) = %d path(s), truncated %v; want 10, true", len(got), truncated)
+	}
+	// The first path takes every left branch, the second turns right last.
+	for i, want := range []string{"l29", "r29"} {
+		p := got[i]
+		if len(p) != 2*n+2 || p[len(p)-3].Name != want || p[len(p)-1] != target {
+			t.Errorf("path %d ends %v, want … %s d30 target", i, p[len(p)-3:], want)
+		}
+	}
+
+	// The duplicate r → d edges do not yield duplicate paths.
+	small := IndexDependencies(components[:9])
+	var names []string
+	for _, p := range paths(small.PathsTo(small.Lookup("d2"), 0)) {
+		var steps []string
+		for _, c := range p {
+			steps = append(steps, c.Name)
+		}
+		names = append(names, strings.Join(steps, " > "))
+	}
+	want := []string{
+		"d0 > l0 > d1 > l1 > d2",
+		"d0 > l0 > d1 > r1 > d2",
+		"d0 > r0 > d1 > l1 > d2",
+		"d0 > r0 > d1 > r1 > d2",
+	}
+	if strings.Join(names, "; ") != strings.Join(want, "; ") {
+		t.Errorf("PathsTo(d2) = %q, want %q", names, want)
+	}
+}
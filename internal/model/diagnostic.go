@@ -0,0 +1,47 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Diagnostic severities.
+const (
+	SeverityError   = "error"   // a file or tool the scan relies on could not be used
+	SeverityWarning = "warning" // input was only partly understood
+	SeverityInfo    = "info"    // worth knowing, nothing was lost
+)
+
+// Diagnostic is a problem met while scanning: a manifest that failed to
+// parse, a file that could not be read, a tool that failed or timed out. It
+// tells "no dependencies found" apart from "could not look".
+type Diagnostic struct {
+	Severity string // SeverityError, SeverityWarning or SeverityInfo
+	Strategy string // Strategy that met the problem, empty for the scanner itself
+	File     string // File concerned, if any
+	Line     int    // 1-based line in File, or 0 when unknown
+	Message  string
+}
+
+// Location returns "file:line", "file" or "" for d.
+func (d Diagnostic) Location() string {
+	switch {
+	case d.File == "":
+		return ""
+	case d.Line > 0:
+		return fmt.Sprintf("%s:%d", d.File, d.Line)
+	}
+	return d.File
+}
+
+// String renders d on one line: "error: conan: conan.lock:3: message".
+func (d Diagnostic) String() string {
+	parts := []string{d.Severity}
+	if d.Strategy != "" {
+		parts = append(parts, d.Strategy)
+	}
+	if loc := d.Location(); loc != "" {
+		parts = append(parts, loc)
+	}
+	return strings.Join(append(parts, d.Message), ": ")
+}
@@ -0,0 +1,100 @@
+package model
+
+import (
+	"strconv"
+	"strings"
+)
+
+// versionPart is one numeric or alphabetic run of a version string.
+type versionPart struct {
+	num   int
+	str   string
+	isNum bool
+	pre   bool // an alphabetic run after "-" or "~", i.e. a pre-release tag
+}
+
+// parseVersion splits a version into numeric and alphabetic runs, dropping
+// separators and a leading "v".
+func parseVersion(v string) []versionPart {
+	v = strings.TrimPrefix(strings.ToLower(v), "v")
+	var parts []versionPart
+	var sep byte
+	for i := 0; i < len(v); {
+		c := v[i]
+		switch {
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(v) && v[j] >= '0' && v[j] <= '9' {
+				j++
+			}
+			n, _ := strconv.Atoi(v[i:j])
+			parts = append(parts, versionPart{num: n, isNum: true})
+			i, sep = j, 0
+		case c >= 'a' && c <= 'z':
+			j := i
+			for j < len(v) && v[j] >= 'a' && v[j] <= 'z' {
+				j++
+			}
+			parts = append(parts, versionPart{str: v[i:j], pre: sep == '-' || sep == '~'})
+			i, sep = j, 0
+		default:
+			sep = c
+			i++
+		}
+	}
+	return parts
+}
+
+// CompareVersions orders dotted versions the way C/C++ libraries number
+// their releases: numeric runs compare as numbers, so 1.10 > 1.9; a letter
+// suffix follows the release, so OpenSSL's 1.1.1k > 1.1.1; and a pre-release
+// tag after "-" precedes it, so 2.0.0-rc1 < 2.0.0. A leading "v" is ignored.
+// It returns -1, 0 or +1.
+func CompareVersions(a, b string) int {
+	pa, pb := parseVersion(a), parseVersion(b)
+	for i := 0; i < len(pa) && i < len(pb); i++ {
+		x, y := pa[i], pb[i]
+		switch {
+		case x.isNum && y.isNum:
+			if x.num != y.num {
+				if x.num < y.num {
+					return -1
+				}
+				return 1
+			}
+		case !x.isNum && !y.isNum:
+			if x.pre != y.pre {
+				if x.pre {
+					return -1
+				}
+				return 1
+			}
+			if c := strings.Compare(x.str, y.str); c != 0 {
+				return c
+			}
+		case x.isNum:
+			if y.pre {
+				return 1
+			}
+			return -1
+		default:
+			if x.pre {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case len(pa) > len(pb):
+		if pa[len(pb)].pre {
+			return -1
+		}
+		return 1
+	case len(pa) < len(pb):
+		if pb[len(pa)].pre {
+			return 1
+		}
+		return -1
+	}
+	return 0
+}
@@ -0,0 +1,29 @@
+package model
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.13", "1.2.13", 0},
+		{"v1.2.13", "1.2.13", 0},
+		{"1.10.0", "1.9.0", 1},
+		{"3.0.8", "3.0.10", -1},
+		{"1.1.1k", "1.1.1", 1},
+		{"1.1.1k", "1.1.1t", -1},
+		{"2.0.0-rc1", "2.0.0", -1},
+		{"2.0.0-rc1", "2.0.0-rc2", -1},
+		{"1.84.0", "1.84", 1},
+		{"0", "0.0.1", -1},
+	}
+	for _, c := range cases {
+		if got := CompareVersions(c.a, c.b); got != c.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", c.a, c.b, got, c.want)
+		}
+		if got := CompareVersions(c.b, c.a); got != -c.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", c.b, c.a, got, -c.want)
+		}
+	}
+}
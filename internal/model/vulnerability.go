@@ -0,0 +1,34 @@
+package model
+
+// Vulnerability is a published security advisory that affects one or more
+// components of a scan.
+type Vulnerability struct {
+	ID         string   // Advisory identifier, e.g. "CVE-2023-0286" or "GHSA-xxxx-xxxx-xxxx"
+	Aliases    []string // Other identifiers of the same advisory
+	Source     string   // Database the advisory was read from, e.g. "OSV"
+	Summary    string
+	Details    string
+	Ratings    []Rating
+	References []string // Advisory, fix and report URLs
+	Published  string   // RFC 3339 timestamps, as published by the database
+	Modified   string
+
+	// Affects lists the components of the scan the advisory applies to.
+	Affects []AffectedComponent
+}
+
+// Rating is one severity assessment of a vulnerability.
+type Rating struct {
+	Method   string // CycloneDX method: "CVSSv2", "CVSSv3", "CVSSv31", "CVSSv4" or "other"
+	Vector   string // Scoring vector, e.g. "CVSS:3.1/AV:N/AC:L/..."
+	Severity string // "critical", "high", "medium", "low" or "" when only a vector is given
+}
+
+// AffectedComponent is a component matched by a vulnerability, with the
+// versions that fix it when the advisory names them.
+type AffectedComponent struct {
+	BOMRef  string
+	Name    string
+	Version string
+	Fixed   []string
+}
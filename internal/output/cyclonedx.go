@@ -4,22 +4,171 @@ package output
 import (
 	"encoding/json"
 	"fmt"
-	"os"
-	"time"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/StinkyLord/cpp-sbom-builder/internal/model"
 	"github.com/StinkyLord/cpp-sbom-builder/internal/scanner"
 )
 
-// ---- CycloneDX 1.4 JSON schema types ----
+// propertyPrefix namespaces the tool-specific CycloneDX properties we attach
+// to components (detection source, include paths, link libraries, ...).
+const propertyPrefix = "cpp-sbom-builder:"
 
-type cdxBOM struct {
-	BOMFormat      string         `json:"bomFormat"`
-	SpecVersion    string         `json:"specVersion"`
-	Version        int            `json:"version"`
-	SerialNumber   string         `json:"serialNumber"`
-	Metadata       cdxMetadata    `json:"metadata"`
-	DependencyTree []*cdxTreeNode `json:"dependencyTree,omitempty"`
+// DefaultSpecVersion is the CycloneDX version written when none is requested.
+const DefaultSpecVersion = "1.4"
+
+// ---- Version-neutral BOM model ----
+//
+// buildCycloneDX converts a scanner.Result into a cdxDoc exactly once. The
+// per-version serializers in cyclonedx_spec.go then project that document onto
+// the wire types of the selected spec version, dropping anything the version
+// does not allow.
+
+type cdxDoc struct {
+	SerialNumber string
+	Timestamp    string
+	Tool         cdxTool
+
+	// Lifecycle is the CycloneDX 1.5+ lifecycle phase the SBOM describes:
+	// "build" when compiled artifacts were inspected, "pre-build" otherwise.
+	Lifecycle string
+
+	// Project is the scanned project itself, emitted as metadata.component.
+	Project *cdxComponent
+
+	Components   []cdxComponentData
+	Dependencies []cdxDependency
+
+	// Strategies lists the detection strategies that produced results. They
+	// become the tasks of the scan workflow in CycloneDX 1.5+ formulation.
+	Strategies []string
+
+	// Vulnerabilities are the known advisories affecting the components.
+	Vulnerabilities []cdxVulnerability
+
+	// Properties describe the scan itself (its diagnostics) and are
+	// emitted as metadata.properties.
+	Properties []cdxProperty
+
+	// DependencyTree is the legacy npm-style nested tree. It is not part of
+	// the CycloneDX specification and is only emitted on request.
+	DependencyTree []*cdxTreeNode
+}
+
+// cdxComponentData is a component together with the version-gated data that
+// only some spec versions can carry.
+type cdxComponentData struct {
+	cdxComponent
+	Evidence cdxEvidenceData
+}
+
+// cdxEvidenceData describes how a component was identified. It is rendered as
+// CycloneDX 1.5+ evidence.identity / evidence.occurrences.
+type cdxEvidenceData struct {
+	// Methods holds one identity method per strategy report.
+	Methods     []cdxMethod
+	Occurrences []string
+}
+
+// ---- Wire types shared by every spec version ----
+
+type cdxComponent struct {
+	BOMRef             string                 `json:"bom-ref"`
+	Type               string                 `json:"type"`
+	Name               string                 `json:"name"`
+	Version            string                 `json:"version,omitempty"`
+	Description        string                 `json:"description,omitempty"`
+	Hashes             []cdxHash              `json:"hashes,omitempty"`
+	Licenses           []cdxLicenseChoice     `json:"licenses,omitempty"`
+	PURL               string                 `json:"purl,omitempty"`
+	ExternalReferences []cdxExternalReference `json:"externalReferences,omitempty"`
+	Properties         []cdxProperty          `json:"properties,omitempty"`
+
+	// Components holds the library files the component was detected
+	// through, as CycloneDX "file" sub-components.
+	Components []cdxComponent `json:"components,omitempty"`
+}
+
+type cdxHash struct {
+	Alg     string `json:"alg" xml:"alg,attr"`
+	Content string `json:"content" xml:",chardata"`
+}
+
+// cdxLicenseChoice is either a single license or an SPDX expression. The
+// schema allows one expression or any number of licenses, never both.
+type cdxLicenseChoice struct {
+	License    *cdxLicense `json:"license,omitempty"`
+	Expression string      `json:"expression,omitempty"`
+}
+
+type cdxLicense struct {
+	ID   string `json:"id,omitempty" xml:"id,omitempty"`
+	Name string `json:"name,omitempty" xml:"name,omitempty"`
+}
+
+type cdxExternalReference struct {
+	Type    string `json:"type"`
+	URL     string `json:"url"`
+	Comment string `json:"comment,omitempty"`
+}
+
+type cdxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+type cdxVulnerability struct {
+	ID             string         `json:"id"`
+	Source         *cdxVulnSource `json:"source,omitempty"`
+	References     []cdxVulnRef   `json:"references,omitempty"`
+	Ratings        []cdxRating    `json:"ratings,omitempty"`
+	Description    string         `json:"description,omitempty"`
+	Detail         string         `json:"detail,omitempty"`
+	Recommendation string         `json:"recommendation,omitempty"`
+	Advisories     []cdxAdvisory  `json:"advisories,omitempty"`
+	Published      string         `json:"published,omitempty"`
+	Updated        string         `json:"updated,omitempty"`
+	Affects        []cdxAffect    `json:"affects"`
+}
+
+type cdxVulnSource struct {
+	Name string `json:"name,omitempty" xml:"name,omitempty"`
+	URL  string `json:"url,omitempty" xml:"url,omitempty"`
+}
+
+// cdxVulnRef names the same vulnerability in another database.
+type cdxVulnRef struct {
+	ID     string        `json:"id" xml:"id"`
+	Source cdxVulnSource `json:"source" xml:"source"`
+}
+
+type cdxRating struct {
+	Source   *cdxVulnSource `json:"source,omitempty" xml:"source,omitempty"`
+	Severity string         `json:"severity,omitempty" xml:"severity,omitempty"`
+	Method   string         `json:"method,omitempty" xml:"method,omitempty"`
+	Vector   string         `json:"vector,omitempty" xml:"vector,omitempty"`
+}
+
+type cdxAdvisory struct {
+	URL string `json:"url" xml:"url"`
+}
+
+type cdxAffect struct {
+	Ref      string            `json:"ref"`
+	Versions []cdxAffectedVers `json:"versions,omitempty"`
+}
+
+type cdxAffectedVers struct {
+	Version string `json:"version" xml:"version"`
+	Status  string `json:"status" xml:"status"`
 }
 
 type cdxTreeNode struct {
@@ -30,67 +179,459 @@ type cdxTreeNode struct {
 	Children []*cdxTreeNode `json:"children,omitempty"`
 }
 
-type cdxMetadata struct {
-	Timestamp string    `json:"timestamp"`
-	Tools     []cdxTool `json:"tools"`
-}
-
 type cdxTool struct {
 	Vendor  string `json:"vendor"`
 	Name    string `json:"name"`
 	Version string `json:"version"`
 }
 
-// WriteCycloneDX serialises the scan result as a CycloneDX 1.4 JSON SBOM and
-// writes it to the given output path. If outputPath is "-", it writes to stdout.
-func WriteCycloneDX(result *scanner.Result, outputPath string, toolVersion string) error {
-	bom := buildCycloneDX(result, toolVersion)
+// CycloneDXOptions controls how WriteCycloneDX renders a scan result.
+type CycloneDXOptions struct {
+	// ToolVersion is recorded in metadata.tools.
+	ToolVersion string
 
-	data, err := json.MarshalIndent(bom, "", "  ")
+	// SpecVersion selects the CycloneDX specification version ("1.4", "1.5"
+	// or "1.6"). Empty means DefaultSpecVersion.
+	SpecVersion string
+
+	// IncludeDependencyTree additionally emits the legacy nested
+	// "dependencyTree" field for consumers that still rely on it.
+	IncludeDependencyTree bool
+
+	// Reproducible derives the serial number from the document content
+	// (a v5 UUID) instead of generating a random one, and timestamps the
+	// document with SOURCE_DATE_EPOCH or the Unix epoch.
+	Reproducible bool
+}
+
+// WriteCycloneDX serialises the scan result as a CycloneDX JSON SBOM of the
+// requested spec version and writes it to the given output path. If
+// outputPath is "-", it writes to stdout.
+func WriteCycloneDX(result *scanner.Result, outputPath string, opts CycloneDXOptions) error {
+	ser, err := cdxSerializerFor(opts.SpecVersion)
 	if err != nil {
-		return fmt.Errorf("failed to marshal CycloneDX JSON: %w", err)
+		return err
 	}
 
-	if outputPath == "-" {
-		_, err = os.Stdout.Write(data)
-		if err == nil {
-			_, err = os.Stdout.WriteString("\n")
-		}
+	doc, err := buildCycloneDX(result, opts)
+	if err != nil {
 		return err
 	}
+	bom := ser.serialize(doc)
+
+	data, err := json.MarshalIndent(bom, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal CycloneDX JSON: %w", err)
+	}
 
-	return os.WriteFile(outputPath, append(data, '\n'), 0644)
+	return writeOutput(outputPath, data)
 }
 
-func buildCycloneDX(result *scanner.Result, toolVersion string) cdxBOM {
+// buildCycloneDX is the single conversion path from a scan result to a BOM.
+// Every spec version and encoding is rendered from its output.
+func buildCycloneDX(result *scanner.Result, opts CycloneDXOptions) (*cdxDoc, error) {
+	components, dependencies := buildCDXComponents(result)
+
+	// The project is the root of the dependency graph: its entry comes first
+	// and depends on every direct dependency. Merged SBOMs nest each input
+	// project under it as an assembly, which in turn depends on its own
+	// direct dependencies.
+	var project *cdxComponent
+	if result.Project != nil {
+		tree := result.DependencyTree
+		if tree == nil {
+			tree = model.BuildDependencyTree(result.Components)
+		}
+
+		p := projectToCDX(result.Project)
+		root := cdxDependency{Ref: p.BOMRef}
+		var assemblyDeps []cdxDependency
+		for _, a := range result.Assemblies {
+			sub := projectToCDX(a)
+			sub.BOMRef = assemblyRef(a)
+			p.Components = append(p.Components, sub)
+			root.DependsOn = append(root.DependsOn, sub.BOMRef)
+			assemblyDeps = append(assemblyDeps, cdxDependency{
+				Ref:       sub.BOMRef,
+				DependsOn: dependsOnRefs(a.Dependencies, tree),
+			})
+		}
+		root.DependsOn = append(root.DependsOn, dependsOnRefs(result.Project.Dependencies, tree)...)
+		project = &p
+		dependencies = append(append([]cdxDependency{root}, assemblyDeps...), dependencies...)
+	}
+
 	// Build the dependencyTree: npm-style tree.
 	// Only direct dependencies appear at the root; each carries its full subtree.
 	var depTree []*cdxTreeNode
-	if result.DependencyTree != nil && len(result.DependencyTree.Roots) > 0 {
+	if opts.IncludeDependencyTree && result.DependencyTree != nil {
 		for _, root := range result.DependencyTree.Roots {
 			depTree = append(depTree, modelNodeToCDX(root))
 		}
 	}
 
-	return cdxBOM{
-		BOMFormat:    "CycloneDX",
-		SpecVersion:  "1.4",
-		Version:      1,
-		SerialNumber: generateURN(),
-		Metadata: cdxMetadata{
-			Timestamp: time.Now().UTC().Format(time.RFC3339),
-			Tools: []cdxTool{
-				{
-					Vendor:  "StinkyLord",
-					Name:    "cpp-sbom-builder",
-					Version: toolVersion,
-				},
-			},
+	strategiesUsed := make([]string, len(result.StrategiesUsed))
+	copy(strategiesUsed, result.StrategiesUsed)
+	sort.Strings(strategiesUsed)
+
+	doc := &cdxDoc{
+		Tool: cdxTool{
+			Vendor:  "StinkyLord",
+			Name:    "cpp-sbom-builder",
+			Version: opts.ToolVersion,
+		},
+		Lifecycle:       lifecyclePhase(strategiesUsed),
+		Project:         project,
+		Components:      components,
+		Dependencies:    dependencies,
+		Strategies:      strategiesUsed,
+		Vulnerabilities: vulnerabilitiesToCDX(result.Vulnerabilities),
+		Properties:      diagnosticProperties(result.Diagnostics),
+		DependencyTree:  depTree,
+	}
+
+	// The content-derived serial is computed before the serial and timestamp
+	// are filled in, so it only changes when the BOM content does.
+	serial := newRandomUUID()
+	if opts.Reproducible {
+		var err error
+		if serial, err = contentUUID(doc); err != nil {
+			return nil, err
+		}
+	}
+	timestamp, err := creationTime(opts.Reproducible)
+	if err != nil {
+		return nil, err
+	}
+	doc.SerialNumber = "urn:uuid:" + serial
+	doc.Timestamp = timestamp
+
+	return doc, nil
+}
+
+// buildCDXComponents converts every merged component into a CycloneDX
+// component and every graph edge into an entry of the dependencies array.
+// Both slices are sorted by bom-ref so the output is stable.
+//
+// Edges whose child is not a known component are dropped: CycloneDX requires
+// every dependsOn entry to reference a bom-ref present in the document.
+func buildCDXComponents(result *scanner.Result) ([]cdxComponentData, []cdxDependency) {
+	comps := make([]*model.Component, len(result.Components))
+	copy(comps, result.Components)
+	sort.Slice(comps, func(i, j int) bool {
+		return comps[i].BOMRef() < comps[j].BOMRef()
+	})
+
+	tree := result.DependencyTree
+	if tree == nil {
+		tree = model.BuildDependencyTree(comps)
+	}
+
+	components := make([]cdxComponentData, 0, len(comps))
+	dependencies := make([]cdxDependency, 0, len(comps))
+	for _, c := range comps {
+		components = append(components, cdxComponentData{
+			cdxComponent: componentToCDX(c),
+			Evidence:     componentEvidence(c),
+		})
+
+		dep := cdxDependency{Ref: c.BOMRef()}
+		seen := map[string]bool{}
+		for _, childName := range c.Dependencies {
+			child := tree.Lookup(childName)
+			if child == nil || child == c {
+				continue
+			}
+			ref := child.BOMRef()
+			if !seen[ref] {
+				seen[ref] = true
+				dep.DependsOn = append(dep.DependsOn, ref)
+			}
+		}
+		sort.Strings(dep.DependsOn)
+		dependencies = append(dependencies, dep)
+	}
+
+	return components, dependencies
+}
+
+// vulnerabilitiesToCDX maps matched advisories to CycloneDX vulnerabilities.
+// Each affects entry references the bom-ref of a component in the document.
+func vulnerabilitiesToCDX(vulns []model.Vulnerability) []cdxVulnerability {
+	var out []cdxVulnerability
+	for _, v := range vulns {
+		source := vulnSource(v.Source, v.ID)
+		cv := cdxVulnerability{
+			ID:          v.ID,
+			Source:      &source,
+			Description: v.Summary,
+			Detail:      v.Details,
+			Published:   v.Published,
+			Updated:     v.Modified,
+		}
+		for _, alias := range v.Aliases {
+			cv.References = append(cv.References, cdxVulnRef{ID: alias, Source: vulnSource(v.Source, alias)})
+		}
+		for _, r := range v.Ratings {
+			cv.Ratings = append(cv.Ratings, cdxRating{
+				Source:   &cdxVulnSource{Name: source.Name},
+				Severity: r.Severity,
+				Method:   r.Method,
+				Vector:   r.Vector,
+			})
+		}
+		for _, url := range v.References {
+			cv.Advisories = append(cv.Advisories, cdxAdvisory{URL: url})
+		}
+		var upgrades []string
+		for _, a := range v.Affects {
+			cv.Affects = append(cv.Affects, cdxAffect{
+				Ref:      a.BOMRef,
+				Versions: []cdxAffectedVers{{Version: a.Version, Status: "affected"}},
+			})
+			if len(a.Fixed) > 0 {
+				upgrades = append(upgrades, fmt.Sprintf("upgrade %s to %s or later", a.Name, a.Fixed[0]))
+			}
+		}
+		if len(upgrades) > 0 {
+			rec := strings.Join(upgrades, "; ")
+			cv.Recommendation = strings.ToUpper(rec[:1]) + rec[1:]
+		}
+		out = append(out, cv)
+	}
+	return out
+}
+
+// vulnSource names the database an advisory ID comes from, linking to its
+// OSV page, which resolves CVE and GHSA aliases as well as OSV IDs.
+func vulnSource(database, id string) cdxVulnSource {
+	if database == "" {
+		database = "OSV"
+	}
+	return cdxVulnSource{Name: database, URL: "https://osv.dev/vulnerability/" + id}
+}
+
+// projectToCDX maps the scanned project to the CycloneDX application
+// component recorded in metadata.component.
+func projectToCDX(p *model.Component) cdxComponent {
+	out := cdxComponent{
+		BOMRef:      p.BOMRef(),
+		Type:        "application",
+		Name:        p.Name,
+		Description: p.Description,
+		PURL:        p.PURL,
+		Licenses:    cdxLicenses(p.Licenses),
+	}
+	if p.Version != "" && p.Version != "unknown" {
+		out.Version = p.Version
+	}
+	if p.Homepage != "" {
+		out.ExternalReferences = []cdxExternalReference{{Type: "website", URL: p.Homepage}}
+	}
+	if p.DetectionSource != "" {
+		out.Properties = []cdxProperty{{Name: propertyPrefix + "detectionSource", Value: p.DetectionSource}}
+	}
+	return out
+}
+
+// dependsOnRefs resolves dependency names to the bom-refs of known
+// components, deduplicated and sorted.
+func dependsOnRefs(names []string, tree *model.DependencyTree) []string {
+	var refs []string
+	seen := map[string]bool{}
+	for _, name := range names {
+		child := tree.Lookup(name)
+		if child == nil || seen[child.BOMRef()] {
+			continue
+		}
+		seen[child.BOMRef()] = true
+		refs = append(refs, child.BOMRef())
+	}
+	sort.Strings(refs)
+	return refs
+}
+
+// assemblyRef is the bom-ref of a merged input project. It is namespaced so
+// it cannot collide with a library of the same name and version, which is
+// common when one input repository consumes another.
+func assemblyRef(a *model.Component) string {
+	return "assembly:" + a.BOMRef()
+}
+
+// componentToCDX maps a model.Component to a CycloneDX library component.
+// Fields CycloneDX has no slot for are preserved as namespaced properties.
+func componentToCDX(c *model.Component) cdxComponent {
+	out := cdxComponent{
+		BOMRef:      c.BOMRef(),
+		Type:        "library",
+		Name:        c.Name,
+		Description: c.Description,
+		PURL:        c.PURL,
+	}
+	if c.Version != "" && c.Version != "unknown" {
+		out.Version = c.Version
+	}
+	out.Licenses = cdxLicenses(c.Licenses)
+	if c.Homepage != "" {
+		out.ExternalReferences = append(out.ExternalReferences, cdxExternalReference{Type: "website", URL: c.Homepage})
+	}
+	for _, ref := range c.ExternalReferences {
+		out.ExternalReferences = append(out.ExternalReferences, cdxExternalReference(ref))
+	}
+
+	addProp := func(name, value string) {
+		if value != "" {
+			out.Properties = append(out.Properties, cdxProperty{Name: propertyPrefix + name, Value: value})
+		}
+	}
+	addProp("dependencyType", c.DependencyType())
+	addProp("detectionSource", c.DetectionSource)
+	addProp("revision", c.Revision)
+	addProp("channel", c.Channel)
+	for _, p := range c.IncludePaths {
+		addProp("includePath", p)
+	}
+	for _, l := range c.LinkLibraries {
+		addProp("linkLibrary", l)
+	}
+
+	// A single library file identifies the component exactly, so its digests
+	// are the component's. With several files each digest only applies to
+	// its own file sub-component.
+	if len(c.Artifacts) == 1 {
+		out.Hashes = cdxHashes(c.Artifacts[0].Hashes)
+	}
+	for _, a := range c.Artifacts {
+		out.Components = append(out.Components, artifactToCDX(c, a))
+	}
+
+	return out
+}
+
+// artifactToCDX maps a library file to a CycloneDX file component nested
+// under the component it belongs to.
+func artifactToCDX(c *model.Component, a model.Artifact) cdxComponent {
+	path := filepath.ToSlash(a.Path)
+	return cdxComponent{
+		BOMRef: c.BOMRef() + "#" + path,
+		Type:   "file",
+		Name:   path,
+		Hashes: cdxHashes(a.Hashes),
+		Properties: []cdxProperty{
+			{Name: propertyPrefix + "size", Value: strconv.FormatInt(a.Size, 10)},
 		},
-		DependencyTree: depTree,
 	}
 }
 
+func cdxHashes(hashes []model.Hash) []cdxHash {
+	out := make([]cdxHash, 0, len(hashes))
+	for _, h := range hashes {
+		out = append(out, cdxHash{Alg: h.Algorithm, Content: h.Value})
+	}
+	return out
+}
+
+// cdxLicenses renders declared licenses as a single SPDX expression when
+// every one of them is valid SPDX. Otherwise each becomes its own license
+// entry: by ID when it is a known SPDX ID, by name when it is free text.
+func cdxLicenses(licenses []string) []cdxLicenseChoice {
+	if len(licenses) == 0 {
+		return nil
+	}
+	if expr, ok := combinedLicenseExpression(licenses); ok {
+		return []cdxLicenseChoice{{Expression: expr}}
+	}
+	out := make([]cdxLicenseChoice, 0, len(licenses))
+	for _, l := range licenses {
+		if id, ok := spdxLicenseIDs[strings.ToLower(strings.TrimSpace(l))]; ok {
+			out = append(out, cdxLicenseChoice{License: &cdxLicense{ID: id}})
+		} else {
+			out = append(out, cdxLicenseChoice{License: &cdxLicense{Name: l}})
+		}
+	}
+	return out
+}
+
+// componentEvidence describes how the component was identified: one method
+// per strategy that reported it, and the files they reported it from
+// followed by its include paths. Components without sources (read back from
+// an SBOM) fall back to the strategy that won the merge.
+func componentEvidence(c *model.Component) cdxEvidenceData {
+	var ev cdxEvidenceData
+	for _, src := range c.Sources {
+		technique, confidence := identityTechnique(src.Strategy)
+		ev.Methods = append(ev.Methods, cdxMethod{Technique: technique, Confidence: confidence, Value: src.String()})
+		if src.File != "" {
+			ev.Occurrences = appendUnique(ev.Occurrences, src.File)
+		}
+	}
+	if len(ev.Methods) == 0 {
+		technique, confidence := identityTechnique(c.DetectionSource)
+		ev.Methods = []cdxMethod{{Technique: technique, Confidence: confidence, Value: c.DetectionSource}}
+	}
+	for _, p := range c.IncludePaths {
+		ev.Occurrences = appendUnique(ev.Occurrences, p)
+	}
+	return ev
+}
+
+// appendUnique appends s to list unless it is already present.
+func appendUnique(list []string, s string) []string {
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+// diagnosticProperties records each scan diagnostic as a
+// "cpp-sbom-builder:diagnostic" property, so an SBOM without components can
+// be told apart from one whose manifests failed to parse.
+func diagnosticProperties(diags []model.Diagnostic) []cdxProperty {
+	var props []cdxProperty
+	for _, d := range diags {
+		props = append(props, cdxProperty{Name: propertyPrefix + "diagnostic", Value: d.String()})
+	}
+	return props
+}
+
+// identityTechnique maps a detection strategy to a CycloneDX 1.5+ identity
+// technique and a confidence score between 0 and 1.
+func identityTechnique(source string) (string, float64) {
+	switch source {
+	case "conan-graph":
+		return "manifest-analysis", 1.0
+	case "conan", "vcpkg":
+		return "manifest-analysis", 0.9
+	case "cmake", "meson":
+		return "manifest-analysis", 0.7
+	case "compile_commands.json", "build-logs", "cmake-configure":
+		return "filename", 0.6
+	case "linker-map":
+		return "filename", 0.7
+	case "binary-edges", "ldd":
+		return "binary-analysis", 0.6
+	case "header-scan":
+		return "source-code-analysis", 0.3
+	default:
+		return "other", 0.1
+	}
+}
+
+// lifecyclePhase reports "build" when any strategy inspected compiled
+// artifacts (map files, shared libraries, ldd output) and "pre-build" when
+// the SBOM was inferred from manifests and sources alone.
+func lifecyclePhase(strategiesUsed []string) string {
+	for _, s := range strategiesUsed {
+		switch s {
+		case "linker-map", "binary-edges", "ldd":
+			return "build"
+		}
+	}
+	return "pre-build"
+}
+
 // modelNodeToCDX converts a model.TreeNode to a cdxTreeNode iteratively.
 func modelNodeToCDX(root *model.TreeNode) *cdxTreeNode {
 	type workItem struct {
@@ -124,16 +665,3 @@ func modelNodeToCDX(root *model.TreeNode) *cdxTreeNode {
 
 	return rootDst
 }
-
-// generateURN produces a simple URN:UUID using the current time.
-// For production use, replace with a proper UUID library.
-func generateURN() string {
-	now := time.Now().UnixNano()
-	return fmt.Sprintf("urn:uuid:%08x-%04x-%04x-%04x-%012x",
-		now&0xFFFFFFFF,
-		(now>>32)&0xFFFF,
-		0x4000|((now>>48)&0x0FFF),
-		0x8000|(now&0x3FFF),
-		now&0xFFFFFFFFFFFF,
-	)
-}
@@ -0,0 +1,390 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SupportedSpecVersions lists the CycloneDX versions WriteCycloneDX can emit.
+var SupportedSpecVersions = []string{"1.4", "1.5", "1.6"}
+
+// cdxSerializer projects the version-neutral cdxDoc onto the wire types of
+// one CycloneDX spec version. Each implementation only emits the fields its
+// version's schema allows.
+type cdxSerializer interface {
+	specVersion() string
+	serialize(doc *cdxDoc) any
+	serializeXML(doc *cdxDoc) *xmlBOM
+}
+
+var cdxSerializers = map[string]cdxSerializer{
+	"1.4": cdx14Serializer{},
+	"1.5": cdx15Serializer{},
+	"1.6": cdx16Serializer{},
+}
+
+// cdxSerializerFor returns the serializer for the given spec version.
+// An empty version selects DefaultSpecVersion.
+func cdxSerializerFor(version string) (cdxSerializer, error) {
+	if version == "" {
+		version = DefaultSpecVersion
+	}
+	ser, ok := cdxSerializers[version]
+	if !ok {
+		return nil, fmt.Errorf("unsupported CycloneDX spec version %q (supported: %s)",
+			version, strings.Join(SupportedSpecVersions, ", "))
+	}
+	return ser, nil
+}
+
+func schemaURL(specVersion string) string {
+	return "http://cyclonedx.org/schema/bom-" + specVersion + ".schema.json"
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// CycloneDX 1.4
+// ─────────────────────────────────────────────────────────────────────────────
+
+type cdx14BOM struct {
+	Schema       string          `json:"$schema,omitempty"`
+	BOMFormat    string          `json:"bomFormat"`
+	SpecVersion  string          `json:"specVersion"`
+	Version      int             `json:"version"`
+	SerialNumber string          `json:"serialNumber"`
+	Metadata     cdx14Metadata   `json:"metadata"`
+	Components   []cdxComponent  `json:"components"`
+	Dependencies []cdxDependency `json:"dependencies"`
+
+	Vulnerabilities []cdxVulnerability `json:"vulnerabilities,omitempty"`
+
+	DependencyTree []*cdxTreeNode `json:"dependencyTree,omitempty"`
+}
+
+// cdx14Metadata uses the legacy tools array, which 1.5 deprecated.
+type cdx14Metadata struct {
+	Timestamp  string        `json:"timestamp"`
+	Tools      []cdxTool     `json:"tools"`
+	Component  *cdxComponent `json:"component,omitempty"`
+	Properties []cdxProperty `json:"properties,omitempty"`
+}
+
+type cdx14Serializer struct{}
+
+func (cdx14Serializer) specVersion() string { return "1.4" }
+
+func (s cdx14Serializer) serialize(doc *cdxDoc) any {
+	components := make([]cdxComponent, 0, len(doc.Components))
+	for _, c := range doc.Components {
+		components = append(components, c.cdxComponent)
+	}
+	return &cdx14BOM{
+		Schema:       schemaURL(s.specVersion()),
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  s.specVersion(),
+		Version:      1,
+		SerialNumber: doc.SerialNumber,
+		Metadata: cdx14Metadata{
+			Timestamp:  doc.Timestamp,
+			Tools:      []cdxTool{doc.Tool},
+			Component:  doc.Project,
+			Properties: doc.Properties,
+		},
+		Components:      components,
+		Dependencies:    doc.Dependencies,
+		Vulnerabilities: vulnerabilities14(doc.Vulnerabilities),
+		DependencyTree:  doc.DependencyTree,
+	}
+}
+
+func (s cdx14Serializer) serializeXML(doc *cdxDoc) *xmlBOM {
+	d := *doc
+	d.Vulnerabilities = vulnerabilities14(doc.Vulnerabilities)
+	return buildXMLBOM(&d, s.specVersion(), false)
+}
+
+// vulnerabilities14 returns vulns with the rating methods 1.4 does not know,
+// such as CVSSv4, which 1.5 added, written as "other".
+func vulnerabilities14(vulns []cdxVulnerability) []cdxVulnerability {
+	out := make([]cdxVulnerability, len(vulns))
+	for i, v := range vulns {
+		ratings := make([]cdxRating, len(v.Ratings))
+		for j, r := range v.Ratings {
+			switch r.Method {
+			case "", "CVSSv2", "CVSSv3", "CVSSv31", "OWASP", "other":
+			default:
+				r.Method = "other"
+			}
+			ratings[j] = r
+		}
+		v.Ratings = ratings
+		out[i] = v
+	}
+	return out
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// CycloneDX 1.5 — adds lifecycles, tools-as-components, evidence identity and
+// occurrences, and formulation.
+// ─────────────────────────────────────────────────────────────────────────────
+
+type cdx15BOM struct {
+	Schema       string           `json:"$schema,omitempty"`
+	BOMFormat    string           `json:"bomFormat"`
+	SpecVersion  string           `json:"specVersion"`
+	Version      int              `json:"version"`
+	SerialNumber string           `json:"serialNumber"`
+	Metadata     cdx15Metadata    `json:"metadata"`
+	Components   []cdx15Component `json:"components"`
+	Dependencies []cdxDependency  `json:"dependencies"`
+	Formulation  []cdxFormula     `json:"formulation,omitempty"`
+
+	Vulnerabilities []cdxVulnerability `json:"vulnerabilities,omitempty"`
+
+	DependencyTree []*cdxTreeNode `json:"dependencyTree,omitempty"`
+}
+
+// cdx15Metadata is shared by 1.5 and 1.6, whose metadata shapes are identical
+// for the fields we emit.
+type cdx15Metadata struct {
+	Timestamp  string         `json:"timestamp"`
+	Lifecycles []cdxLifecycle `json:"lifecycles,omitempty"`
+	Tools      cdx15Tools     `json:"tools"`
+	Component  *cdxComponent  `json:"component,omitempty"`
+	Properties []cdxProperty  `json:"properties,omitempty"`
+}
+
+type cdxLifecycle struct {
+	Phase string `json:"phase" xml:"phase"`
+}
+
+type cdx15Tools struct {
+	Components []cdxToolComponent `json:"components"`
+}
+
+type cdxToolComponent struct {
+	Type     string           `json:"type"`
+	Supplier *cdxOrganization `json:"supplier,omitempty"`
+	Name     string           `json:"name"`
+	Version  string           `json:"version,omitempty"`
+}
+
+type cdxOrganization struct {
+	Name string `json:"name" xml:"name"`
+}
+
+type cdx15Component struct {
+	cdxComponent
+	Evidence *cdx15Evidence `json:"evidence,omitempty"`
+}
+
+type cdx15Evidence struct {
+	Identity    *cdxIdentity    `json:"identity,omitempty"`
+	Occurrences []cdxOccurrence `json:"occurrences,omitempty"`
+}
+
+type cdxIdentity struct {
+	Field      string      `json:"field"`
+	Confidence float64     `json:"confidence"`
+	Methods    []cdxMethod `json:"methods,omitempty"`
+}
+
+type cdxMethod struct {
+	Technique  string  `json:"technique"`
+	Confidence float64 `json:"confidence"`
+	Value      string  `json:"value,omitempty"`
+}
+
+type cdxOccurrence struct {
+	Location string `json:"location" xml:"location"`
+}
+
+type cdxFormula struct {
+	BOMRef    string        `json:"bom-ref"`
+	Workflows []cdxWorkflow `json:"workflows,omitempty"`
+}
+
+type cdxWorkflow struct {
+	BOMRef    string    `json:"bom-ref"`
+	UID       string    `json:"uid"`
+	Name      string    `json:"name,omitempty"`
+	TaskTypes []string  `json:"taskTypes"`
+	Tasks     []cdxTask `json:"tasks,omitempty"`
+}
+
+type cdxTask struct {
+	BOMRef    string   `json:"bom-ref"`
+	UID       string   `json:"uid"`
+	Name      string   `json:"name,omitempty"`
+	TaskTypes []string `json:"taskTypes"`
+}
+
+type cdx15Serializer struct{}
+
+func (cdx15Serializer) specVersion() string { return "1.5" }
+
+func (s cdx15Serializer) serialize(doc *cdxDoc) any {
+	components := make([]cdx15Component, 0, len(doc.Components))
+	for _, c := range doc.Components {
+		out := cdx15Component{cdxComponent: c.cdxComponent}
+		if ident := identityFor(c); ident != nil || len(c.Evidence.Occurrences) > 0 {
+			out.Evidence = &cdx15Evidence{
+				Identity:    ident,
+				Occurrences: occurrencesFor(c),
+			}
+		}
+		components = append(components, out)
+	}
+	return &cdx15BOM{
+		Schema:          schemaURL(s.specVersion()),
+		BOMFormat:       "CycloneDX",
+		SpecVersion:     s.specVersion(),
+		Version:         1,
+		SerialNumber:    doc.SerialNumber,
+		Metadata:        metadata15(doc),
+		Components:      components,
+		Dependencies:    doc.Dependencies,
+		Formulation:     formulationFor(doc),
+		Vulnerabilities: doc.Vulnerabilities,
+		DependencyTree:  doc.DependencyTree,
+	}
+}
+
+func (s cdx15Serializer) serializeXML(doc *cdxDoc) *xmlBOM {
+	return buildXMLBOM(doc, s.specVersion(), true)
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// CycloneDX 1.6 — evidence.identity becomes an array.
+// ─────────────────────────────────────────────────────────────────────────────
+
+type cdx16BOM struct {
+	Schema       string           `json:"$schema,omitempty"`
+	BOMFormat    string           `json:"bomFormat"`
+	SpecVersion  string           `json:"specVersion"`
+	Version      int              `json:"version"`
+	SerialNumber string           `json:"serialNumber"`
+	Metadata     cdx15Metadata    `json:"metadata"`
+	Components   []cdx16Component `json:"components"`
+	Dependencies []cdxDependency  `json:"dependencies"`
+	Formulation  []cdxFormula     `json:"formulation,omitempty"`
+
+	Vulnerabilities []cdxVulnerability `json:"vulnerabilities,omitempty"`
+
+	DependencyTree []*cdxTreeNode `json:"dependencyTree,omitempty"`
+}
+
+type cdx16Component struct {
+	cdxComponent
+	Evidence *cdx16Evidence `json:"evidence,omitempty"`
+}
+
+type cdx16Evidence struct {
+	Identity    []cdxIdentity   `json:"identity,omitempty"`
+	Occurrences []cdxOccurrence `json:"occurrences,omitempty"`
+}
+
+type cdx16Serializer struct{}
+
+func (cdx16Serializer) specVersion() string { return "1.6" }
+
+func (s cdx16Serializer) serialize(doc *cdxDoc) any {
+	components := make([]cdx16Component, 0, len(doc.Components))
+	for _, c := range doc.Components {
+		out := cdx16Component{cdxComponent: c.cdxComponent}
+		ident := identityFor(c)
+		if ident != nil || len(c.Evidence.Occurrences) > 0 {
+			out.Evidence = &cdx16Evidence{Occurrences: occurrencesFor(c)}
+			if ident != nil {
+				out.Evidence.Identity = []cdxIdentity{*ident}
+			}
+		}
+		components = append(components, out)
+	}
+	return &cdx16BOM{
+		Schema:          schemaURL(s.specVersion()),
+		BOMFormat:       "CycloneDX",
+		SpecVersion:     s.specVersion(),
+		Version:         1,
+		SerialNumber:    doc.SerialNumber,
+		Metadata:        metadata15(doc),
+		Components:      components,
+		Dependencies:    doc.Dependencies,
+		Formulation:     formulationFor(doc),
+		Vulnerabilities: doc.Vulnerabilities,
+		DependencyTree:  doc.DependencyTree,
+	}
+}
+
+// serializeXML is identical to 1.5: the XML schema already allowed repeated
+// <identity> elements, so only the namespace changes.
+func (s cdx16Serializer) serializeXML(doc *cdxDoc) *xmlBOM {
+	return buildXMLBOM(doc, s.specVersion(), true)
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Helpers shared by the 1.5+ serializers
+// ─────────────────────────────────────────────────────────────────────────────
+
+func metadata15(doc *cdxDoc) cdx15Metadata {
+	md := cdx15Metadata{
+		Timestamp: doc.Timestamp,
+		Tools: cdx15Tools{
+			Components: []cdxToolComponent{{
+				Type:     "application",
+				Supplier: &cdxOrganization{Name: doc.Tool.Vendor},
+				Name:     doc.Tool.Name,
+				Version:  doc.Tool.Version,
+			}},
+		},
+		Component:  doc.Project,
+		Properties: doc.Properties,
+	}
+	if doc.Lifecycle != "" {
+		md.Lifecycles = []cdxLifecycle{{Phase: doc.Lifecycle}}
+	}
+	return md
+}
+
+// identityFor returns the name identity of c, as confident as its most
+// confident method.
+func identityFor(c cdxComponentData) *cdxIdentity {
+	if len(c.Evidence.Methods) == 0 {
+		return nil
+	}
+	ident := &cdxIdentity{Field: "name", Methods: c.Evidence.Methods}
+	for _, m := range c.Evidence.Methods {
+		ident.Confidence = max(ident.Confidence, m.Confidence)
+	}
+	return ident
+}
+
+func occurrencesFor(c cdxComponentData) []cdxOccurrence {
+	var out []cdxOccurrence
+	for _, loc := range c.Evidence.Occurrences {
+		out = append(out, cdxOccurrence{Location: loc})
+	}
+	return out
+}
+
+// formulationFor describes how the SBOM itself was produced: one scan
+// workflow whose tasks are the detection strategies that produced results.
+func formulationFor(doc *cdxDoc) []cdxFormula {
+	if len(doc.Strategies) == 0 {
+		return nil
+	}
+	wf := cdxWorkflow{
+		BOMRef:    "workflow-scan",
+		UID:       "cpp-sbom-builder-scan",
+		Name:      doc.Tool.Name + " scan",
+		TaskTypes: []string{"scan"},
+	}
+	for _, name := range doc.Strategies {
+		wf.Tasks = append(wf.Tasks, cdxTask{
+			BOMRef:    "task-" + name,
+			UID:       "strategy-" + name,
+			Name:      name,
+			TaskTypes: []string{"scan"},
+		})
+	}
+	return []cdxFormula{{BOMRef: "formula-scan", Workflows: []cdxWorkflow{wf}}}
+}
@@ -73,7 +73,7 @@ func TestCycloneDXSchema(t *testing.T) {
 	result := makeTestResult()
 
 	tmp := filepath.Join(t.TempDir(), "sbom.json")
-	if err := WriteCycloneDX(result, tmp, "1.0.0-test"); err != nil {
+	if err := WriteCycloneDX(result, tmp, CycloneDXOptions{ToolVersion: "1.0.0-test"}); err != nil {
 		t.Fatalf("WriteCycloneDX failed: %v", err)
 	}
 
@@ -221,12 +221,13 @@ func TestRecursiveTree(t *testing.T) {
 }
 
 // TestDependencyTreeInOutput verifies that dependencyTree appears in the JSON output
-// and has the correct recursive structure.
+// when requested and has the correct recursive structure.
 func TestDependencyTreeInOutput(t *testing.T) {
 	result := makeTestResult()
 
 	tmp := filepath.Join(t.TempDir(), "sbom.json")
-	if err := WriteCycloneDX(result, tmp, "1.0.0-test"); err != nil {
+	opts := CycloneDXOptions{ToolVersion: "1.0.0-test", IncludeDependencyTree: true}
+	if err := WriteCycloneDX(result, tmp, opts); err != nil {
 		t.Fatalf("WriteCycloneDX failed: %v", err)
 	}
 
@@ -235,7 +236,7 @@ func TestDependencyTreeInOutput(t *testing.T) {
 		t.Fatalf("cannot read output file: %v", err)
 	}
 
-	var bom cdxBOM
+	var bom cdx14BOM
 	if err := json.Unmarshal(data, &bom); err != nil {
 		t.Fatalf("cannot unmarshal CycloneDX BOM: %v", err)
 	}
@@ -281,7 +282,7 @@ func TestCycloneDXStdout(t *testing.T) {
 	r, w, _ := os.Pipe()
 	os.Stdout = w
 
-	err := WriteCycloneDX(result, "-", "1.0.0-test")
+	err := WriteCycloneDX(result, "-", CycloneDXOptions{ToolVersion: "1.0.0-test"})
 
 	w.Close()
 	os.Stdout = old
@@ -308,7 +309,7 @@ func TestCycloneDXMetadata(t *testing.T) {
 	result := makeTestResult()
 
 	tmp := filepath.Join(t.TempDir(), "sbom.json")
-	if err := WriteCycloneDX(result, tmp, "test-version"); err != nil {
+	if err := WriteCycloneDX(result, tmp, CycloneDXOptions{ToolVersion: "test-version"}); err != nil {
 		t.Fatalf("WriteCycloneDX failed: %v", err)
 	}
 
@@ -317,7 +318,7 @@ func TestCycloneDXMetadata(t *testing.T) {
 		t.Fatalf("cannot read output file: %v", err)
 	}
 
-	var bom cdxBOM
+	var bom cdx14BOM
 	if err := json.Unmarshal(data, &bom); err != nil {
 		t.Fatalf("cannot unmarshal CycloneDX BOM: %v", err)
 	}
@@ -336,3 +337,627 @@ func TestCycloneDXMetadata(t *testing.T) {
 		t.Errorf("tool version = %q, want %q", tool.Version, "test-version")
 	}
 }
+
+// TestCycloneDXComponents verifies that every merged component is emitted as a
+// spec-compliant CycloneDX component with a stable bom-ref.
+func TestCycloneDXComponents(t *testing.T) {
+	bom, err := buildCycloneDX(makeTestResult(), CycloneDXOptions{ToolVersion: "test"})
+	if err != nil {
+		t.Fatalf("buildCycloneDX failed: %v", err)
+	}
+
+	if len(bom.Components) != 4 {
+		t.Fatalf("components count = %d, want 4", len(bom.Components))
+	}
+
+	byName := map[string]cdxComponentData{}
+	for _, c := range bom.Components {
+		if c.Type != "library" {
+			t.Errorf("component %q type = %q, want library", c.Name, c.Type)
+		}
+		if c.BOMRef == "" {
+			t.Errorf("component %q has empty bom-ref", c.Name)
+		}
+		byName[c.Name] = c
+	}
+
+	boost := byName["boost"]
+	if boost.BOMRef != "boost@1.82.0" {
+		t.Errorf("boost bom-ref = %q, want boost@1.82.0", boost.BOMRef)
+	}
+	if boost.PURL != "pkg:conan/boost@1.82.0" {
+		t.Errorf("boost purl = %q, want pkg:conan/boost@1.82.0", boost.PURL)
+	}
+	if boost.Description != "Boost C++ Libraries" {
+		t.Errorf("boost description = %q", boost.Description)
+	}
+
+	// "unknown" is not a version — it must be omitted rather than emitted.
+	if v := byName["nlohmann-json"].Version; v != "" {
+		t.Errorf("nlohmann-json version = %q, want empty", v)
+	}
+
+	// The legacy tree is opt-in.
+	if bom.DependencyTree != nil {
+		t.Error("dependencyTree emitted without IncludeDependencyTree")
+	}
+}
+
+// TestCycloneDXDependencies verifies the standard dependencies graph.
+func TestCycloneDXDependencies(t *testing.T) {
+	bom, err := buildCycloneDX(makeTestResult(), CycloneDXOptions{ToolVersion: "test"})
+	if err != nil {
+		t.Fatalf("buildCycloneDX failed: %v", err)
+	}
+
+	refs := map[string]bool{}
+	for _, c := range bom.Components {
+		refs[c.BOMRef] = true
+	}
+
+	byRef := map[string]cdxDependency{}
+	for _, d := range bom.Dependencies {
+		if !refs[d.Ref] {
+			t.Errorf("dependency ref %q does not match any component", d.Ref)
+		}
+		for _, child := range d.DependsOn {
+			if !refs[child] {
+				t.Errorf("dependsOn %q (from %q) does not match any component", child, d.Ref)
+			}
+		}
+		byRef[d.Ref] = d
+	}
+
+	if len(byRef) != len(bom.Components) {
+		t.Errorf("dependencies count = %d, want one per component (%d)", len(byRef), len(bom.Components))
+	}
+
+	openssl := byRef["openssl@3.1.4"]
+	if len(openssl.DependsOn) != 1 || openssl.DependsOn[0] != "zlib@1.2.13" {
+		t.Errorf("openssl dependsOn = %v, want [zlib@1.2.13]", openssl.DependsOn)
+	}
+	if len(byRef["boost@1.82.0"].DependsOn) != 0 {
+		t.Errorf("boost dependsOn = %v, want none", byRef["boost@1.82.0"].DependsOn)
+	}
+}
+
+// TestCycloneDXProject verifies that the scanned project is written as
+// metadata.component and is the root of the dependency graph.
+func TestCycloneDXProject(t *testing.T) {
+	result := makeTestResult()
+	result.Project = &model.Component{
+		Name:            "MyApp",
+		Version:         "2.0.0",
+		PURL:            "pkg:generic/myapp@2.0.0",
+		DetectionSource: "cmake",
+		Dependencies:    []string{"boost", "openssl", "nlohmann-json"},
+	}
+
+	for _, ver := range SupportedSpecVersions {
+		t.Run(ver, func(t *testing.T) {
+			tmp := filepath.Join(t.TempDir(), "sbom.json")
+			if err := WriteCycloneDX(result, tmp, CycloneDXOptions{ToolVersion: "test", SpecVersion: ver}); err != nil {
+				t.Fatalf("WriteCycloneDX failed: %v", err)
+			}
+			data, err := os.ReadFile(tmp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var bom struct {
+				Metadata struct {
+					Component *cdxComponent `json:"component"`
+				} `json:"metadata"`
+				Components   []cdxComponent  `json:"components"`
+				Dependencies []cdxDependency `json:"dependencies"`
+			}
+			if err := json.Unmarshal(data, &bom); err != nil {
+				t.Fatalf("invalid JSON: %v", err)
+			}
+
+			p := bom.Metadata.Component
+			if p == nil || p.Type != "application" || p.Name != "MyApp" || p.Version != "2.0.0" {
+				t.Fatalf("metadata.component = %+v, want application MyApp 2.0.0", p)
+			}
+			for _, c := range bom.Components {
+				if c.BOMRef == p.BOMRef {
+					t.Error("project must not also be listed in components")
+				}
+			}
+
+			root := bom.Dependencies[0]
+			want := []string{"boost@1.82.0", "nlohmann-json@unknown", "openssl@3.1.4"}
+			if root.Ref != p.BOMRef || strings.Join(root.DependsOn, ",") != strings.Join(want, ",") {
+				t.Errorf("root dependency = %+v, want %s -> %v", root, p.BOMRef, want)
+			}
+		})
+	}
+}
+
+// TestCycloneDXSpecVersions verifies that each supported spec version is
+// written with its own specVersion and only the fields that version allows.
+func TestCycloneDXSpecVersions(t *testing.T) {
+	for _, version := range SupportedSpecVersions {
+		t.Run(version, func(t *testing.T) {
+			tmp := filepath.Join(t.TempDir(), "sbom.json")
+			opts := CycloneDXOptions{ToolVersion: "test", SpecVersion: version}
+			if err := WriteCycloneDX(makeTestResult(), tmp, opts); err != nil {
+				t.Fatalf("WriteCycloneDX failed: %v", err)
+			}
+			data, err := os.ReadFile(tmp)
+			if err != nil {
+				t.Fatalf("cannot read output file: %v", err)
+			}
+
+			var raw struct {
+				SpecVersion string `json:"specVersion"`
+				Metadata    struct {
+					Lifecycles json.RawMessage `json:"lifecycles"`
+					Tools      json.RawMessage `json:"tools"`
+				} `json:"metadata"`
+				Components []struct {
+					Name     string `json:"name"`
+					Evidence *struct {
+						Identity json.RawMessage `json:"identity"`
+					} `json:"evidence"`
+				} `json:"components"`
+				Formulation json.RawMessage `json:"formulation"`
+			}
+			if err := json.Unmarshal(data, &raw); err != nil {
+				t.Fatalf("output is not valid JSON: %v", err)
+			}
+
+			if raw.SpecVersion != version {
+				t.Errorf("specVersion = %q, want %q", raw.SpecVersion, version)
+			}
+
+			legacy := version == "1.4"
+			if legacy {
+				if raw.Metadata.Lifecycles != nil || raw.Formulation != nil {
+					t.Error("1.4 output must not contain lifecycles or formulation")
+				}
+				if !strings.HasPrefix(string(raw.Metadata.Tools), "[") {
+					t.Errorf("1.4 metadata.tools must be an array, got %s", raw.Metadata.Tools)
+				}
+			} else {
+				if raw.Metadata.Lifecycles == nil {
+					t.Errorf("%s output is missing metadata.lifecycles", version)
+				}
+				if raw.Formulation == nil {
+					t.Errorf("%s output is missing formulation", version)
+				}
+				if !strings.HasPrefix(string(raw.Metadata.Tools), "{") {
+					t.Errorf("%s metadata.tools must be an object, got %s", version, raw.Metadata.Tools)
+				}
+			}
+
+			for _, c := range raw.Components {
+				if legacy {
+					if c.Evidence != nil {
+						t.Errorf("1.4 component %q must not carry identity evidence", c.Name)
+					}
+					continue
+				}
+				if c.Evidence == nil || c.Evidence.Identity == nil {
+					t.Errorf("%s component %q is missing evidence.identity", version, c.Name)
+					continue
+				}
+				isArray := strings.HasPrefix(string(c.Evidence.Identity), "[")
+				if want := version == "1.6"; isArray != want {
+					t.Errorf("%s evidence.identity array = %v, want %v", version, isArray, want)
+				}
+			}
+		})
+	}
+}
+
+// TestCycloneDXUnsupportedSpecVersion verifies that unknown versions are rejected.
+func TestCycloneDXUnsupportedSpecVersion(t *testing.T) {
+	tmp := filepath.Join(t.TempDir(), "sbom.json")
+	err := WriteCycloneDX(makeTestResult(), tmp, CycloneDXOptions{SpecVersion: "1.3"})
+	if err == nil {
+		t.Fatal("expected an error for spec version 1.3")
+	}
+	if _, statErr := os.Stat(tmp); statErr == nil {
+		t.Error("output file written despite unsupported spec version")
+	}
+}
+
+// TestCycloneDXSerialNumber verifies that serials are RFC 4122 v4 UUIDs by
+// default and content-derived v5 UUIDs in reproducible mode.
+func TestCycloneDXSerialNumber(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "")
+
+	random1, err := buildCycloneDX(makeTestResult(), CycloneDXOptions{ToolVersion: "test"})
+	if err != nil {
+		t.Fatalf("buildCycloneDX failed: %v", err)
+	}
+	random2, err := buildCycloneDX(makeTestResult(), CycloneDXOptions{ToolVersion: "test"})
+	if err != nil {
+		t.Fatalf("buildCycloneDX failed: %v", err)
+	}
+	if random1.SerialNumber == random2.SerialNumber {
+		t.Errorf("random serials collide: %s", random1.SerialNumber)
+	}
+	if v := uuidVersion(t, random1.SerialNumber); v != '4' {
+		t.Errorf("default serial version = %c, want 4", v)
+	}
+
+	opts := CycloneDXOptions{ToolVersion: "test", Reproducible: true}
+	repro1, err := buildCycloneDX(makeTestResult(), opts)
+	if err != nil {
+		t.Fatalf("buildCycloneDX failed: %v", err)
+	}
+	repro2, err := buildCycloneDX(makeTestResult(), opts)
+	if err != nil {
+		t.Fatalf("buildCycloneDX failed: %v", err)
+	}
+	if repro1.SerialNumber != repro2.SerialNumber {
+		t.Errorf("reproducible serials differ: %s vs %s", repro1.SerialNumber, repro2.SerialNumber)
+	}
+	if v := uuidVersion(t, repro1.SerialNumber); v != '5' {
+		t.Errorf("reproducible serial version = %c, want 5", v)
+	}
+	if repro1.Timestamp != "1970-01-01T00:00:00Z" {
+		t.Errorf("reproducible timestamp = %q, want the Unix epoch", repro1.Timestamp)
+	}
+
+	changed := makeTestResult()
+	changed.Components[0].Version = "1.83.0"
+	repro3, err := buildCycloneDX(changed, opts)
+	if err != nil {
+		t.Fatalf("buildCycloneDX failed: %v", err)
+	}
+	if repro3.SerialNumber == repro1.SerialNumber {
+		t.Error("reproducible serial did not change with the content")
+	}
+}
+
+// TestSourceDateEpoch verifies that SOURCE_DATE_EPOCH sets the timestamp and
+// that a malformed value is reported.
+func TestSourceDateEpoch(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+	doc, err := buildCycloneDX(makeTestResult(), CycloneDXOptions{ToolVersion: "test", Reproducible: true})
+	if err != nil {
+		t.Fatalf("buildCycloneDX failed: %v", err)
+	}
+	if doc.Timestamp != "2023-11-14T22:13:20Z" {
+		t.Errorf("timestamp = %q, want 2023-11-14T22:13:20Z", doc.Timestamp)
+	}
+
+	t.Setenv("SOURCE_DATE_EPOCH", "yesterday")
+	if _, err := buildCycloneDX(makeTestResult(), CycloneDXOptions{ToolVersion: "test"}); err == nil {
+		t.Error("expected an error for a malformed SOURCE_DATE_EPOCH")
+	}
+}
+
+// uuidVersion returns the version nibble of a urn:uuid serial number.
+func uuidVersion(t *testing.T, serial string) byte {
+	t.Helper()
+	uuid := strings.TrimPrefix(serial, "urn:uuid:")
+	if len(uuid) != 36 || uuid[8] != '-' || uuid[13] != '-' || uuid[18] != '-' || uuid[23] != '-' {
+		t.Fatalf("serial %q is not a urn:uuid", serial)
+	}
+	if v := uuid[19]; v != '8' && v != '9' && v != 'a' && v != 'b' {
+		t.Errorf("serial %q does not use the RFC 4122 variant", serial)
+	}
+	return uuid[14]
+}
+
+// TestCycloneDXLicenses verifies that declared licenses become a single SPDX
+// expression when valid and named licenses otherwise, and that homepages and
+// external references are emitted.
+func TestCycloneDXLicenses(t *testing.T) {
+	result := makeTestResult()
+	boost, openssl, zlib, nlohmann := result.Components[0], result.Components[1], result.Components[2], result.Components[3]
+	boost.Licenses = []string{"bsl-1.0"}
+	boost.Homepage = "https://www.boost.org"
+	boost.ExternalReferences = []model.ExternalReference{{Type: "build-meta", URL: "https://github.com/conan-io/conan-center-index", Comment: "Conan recipe repository"}}
+	openssl.Licenses = []string{"Apache-2.0", "MIT OR BSD-3-Clause"}
+	zlib.Licenses = []string{"Zlib", "Custom zlib-style license"}
+	nlohmann.Licenses = []string{"MIT WITH LLVM-exception"}
+
+	bom, err := buildCycloneDX(result, CycloneDXOptions{ToolVersion: "test"})
+	if err != nil {
+		t.Fatalf("buildCycloneDX failed: %v", err)
+	}
+	byName := map[string]cdxComponentData{}
+	for _, c := range bom.Components {
+		byName[c.Name] = c
+	}
+
+	expression := func(name string) string {
+		lic := byName[name].Licenses
+		if len(lic) != 1 || lic[0].License != nil {
+			t.Errorf("%s licenses = %+v, want a single expression", name, lic)
+			return ""
+		}
+		return lic[0].Expression
+	}
+	if got := expression("boost"); got != "BSL-1.0" {
+		t.Errorf("boost expression = %q, want BSL-1.0", got)
+	}
+	if got := expression("openssl"); got != "Apache-2.0 AND (MIT OR BSD-3-Clause)" {
+		t.Errorf("openssl expression = %q", got)
+	}
+	if got := expression("nlohmann-json"); got != "MIT WITH LLVM-exception" {
+		t.Errorf("nlohmann-json expression = %q", got)
+	}
+
+	zlibLic := byName["zlib"].Licenses
+	if len(zlibLic) != 2 || zlibLic[0].License == nil || zlibLic[0].License.ID != "Zlib" ||
+		zlibLic[1].License == nil || zlibLic[1].License.Name != "Custom zlib-style license" {
+		t.Errorf("zlib licenses = %+v, want [{id: Zlib} {name: Custom zlib-style license}]", zlibLic)
+	}
+
+	refs := byName["boost"].ExternalReferences
+	if len(refs) != 2 || refs[0].Type != "website" || refs[0].URL != "https://www.boost.org" || refs[1].Type != "build-meta" {
+		t.Errorf("boost externalReferences = %+v", refs)
+	}
+
+	doc, err := buildSPDX(result, SPDXOptions{ToolVersion: "test"})
+	if err != nil {
+		t.Fatalf("buildSPDX failed: %v", err)
+	}
+	declared := map[string]string{}
+	for _, p := range doc.Packages {
+		declared[p.Name] = p.LicenseDeclared
+	}
+	if declared["boost"] != "BSL-1.0" || declared["zlib"] != spdxNoAssertion {
+		t.Errorf("SPDX licenseDeclared = %v", declared)
+	}
+}
+
+// TestCycloneDXHashes verifies that a component backed by a single library
+// file carries that file's digests, and that every file is emitted as a
+// nested file component.
+func TestCycloneDXHashes(t *testing.T) {
+	result := makeTestResult()
+	boost, openssl := result.Components[0], result.Components[1]
+	sha256 := model.Hash{Algorithm: "SHA-256", Value: "aa"}
+	sha512 := model.Hash{Algorithm: "SHA-512", Value: "bb"}
+	openssl.Artifacts = []model.Artifact{{Path: "/usr/lib/libssl.so.3", Size: 3, Hashes: []model.Hash{sha256, sha512}}}
+	boost.Artifacts = []model.Artifact{
+		{Path: "/opt/boost/lib/libboost_system.so", Size: 1, Hashes: []model.Hash{sha256}},
+		{Path: "/opt/boost/lib/libboost_filesystem.so", Size: 2, Hashes: []model.Hash{sha512}},
+	}
+
+	bom, err := buildCycloneDX(result, CycloneDXOptions{ToolVersion: "test"})
+	if err != nil {
+		t.Fatalf("buildCycloneDX failed: %v", err)
+	}
+	byName := map[string]cdxComponentData{}
+	for _, c := range bom.Components {
+		byName[c.Name] = c
+	}
+
+	ssl := byName["openssl"]
+	if len(ssl.Hashes) != 2 || ssl.Hashes[0] != (cdxHash{Alg: "SHA-256", Content: "aa"}) || ssl.Hashes[1] != (cdxHash{Alg: "SHA-512", Content: "bb"}) {
+		t.Errorf("openssl hashes = %+v", ssl.Hashes)
+	}
+	if len(ssl.Components) != 1 || ssl.Components[0].Type != "file" || ssl.Components[0].Name != "/usr/lib/libssl.so.3" {
+		t.Errorf("openssl file components = %+v", ssl.Components)
+	}
+
+	b := byName["boost"]
+	if len(b.Hashes) != 0 {
+		t.Errorf("boost has %d files, component-level hashes would be ambiguous: %+v", len(b.Components), b.Hashes)
+	}
+	if len(b.Components) != 2 || len(b.Components[0].Hashes) != 1 || len(b.Components[1].Hashes) != 1 {
+		t.Errorf("boost file components = %+v", b.Components)
+	}
+	if b.Components[0].BOMRef == b.Components[1].BOMRef {
+		t.Error("file components must have distinct bom-refs")
+	}
+}
+
+// testVulnerability is an advisory affecting openssl in makeTestResult.
+func testVulnerability() model.Vulnerability {
+	return model.Vulnerability{
+		ID:        "CVE-2023-0286",
+		Aliases:   []string{"GHSA-x4qr-2fvf-3mr5"},
+		Source:    "OSV",
+		Summary:   "X.400 address type confusion",
+		Ratings:   []model.Rating{{Method: "CVSSv31", Vector: "CVSS:3.1/AV:N"}, {Severity: "high"}},
+		Published: "2023-02-08T20:15:00Z",
+		Affects: []model.AffectedComponent{
+			{BOMRef: "openssl@3.1.4", Name: "openssl", Version: "3.1.4", Fixed: []string{"3.1.5"}},
+		},
+	}
+}
+
+func TestCycloneDXVulnerabilities(t *testing.T) {
+	result := makeTestResult()
+	result.Vulnerabilities = []model.Vulnerability{testVulnerability()}
+
+	// 1.4 carries vulnerabilities too, with the CVSSv4 method it lacks
+	// written as "other".
+	v4 := testVulnerability()
+	v4.Ratings = []model.Rating{{Method: "CVSSv4", Vector: "CVSS:4.0/AV:N"}}
+	legacyResult := makeTestResult()
+	legacyResult.Vulnerabilities = []model.Vulnerability{v4}
+	legacy := filepath.Join(t.TempDir(), "sbom-1.4.json")
+	if err := WriteCycloneDX(legacyResult, legacy, CycloneDXOptions{ToolVersion: "test", SpecVersion: "1.4"}); err != nil {
+		t.Fatalf("WriteCycloneDX failed: %v", err)
+	}
+	legacyData, _ := os.ReadFile(legacy)
+	if !strings.Contains(string(legacyData), `"vulnerabilities"`) || !strings.Contains(string(legacyData), `"method": "other"`) {
+		t.Errorf("1.4 output lacks the vulnerability or its CVSSv4 rating as other:\n%s", legacyData)
+	}
+
+	tmp := filepath.Join(t.TempDir(), "sbom.json")
+	if err := WriteCycloneDX(result, tmp, CycloneDXOptions{ToolVersion: "test", SpecVersion: "1.5"}); err != nil {
+		t.Fatalf("WriteCycloneDX failed: %v", err)
+	}
+	data, _ := os.ReadFile(tmp)
+	var bom struct {
+		Components []struct {
+			BOMRef string `json:"bom-ref"`
+		} `json:"components"`
+		Vulnerabilities []struct {
+			ID         string `json:"id"`
+			References []struct {
+				ID string `json:"id"`
+			} `json:"references"`
+			Ratings []struct {
+				Severity string `json:"severity"`
+				Method   string `json:"method"`
+			} `json:"ratings"`
+			Recommendation string `json:"recommendation"`
+			Affects        []struct {
+				Ref      string `json:"ref"`
+				Versions []struct {
+					Version string `json:"version"`
+					Status  string `json:"status"`
+				} `json:"versions"`
+			} `json:"affects"`
+		} `json:"vulnerabilities"`
+	}
+	if err := json.Unmarshal(data, &bom); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(bom.Vulnerabilities) != 1 {
+		t.Fatalf("vulnerabilities = %d, want 1", len(bom.Vulnerabilities))
+	}
+	v := bom.Vulnerabilities[0]
+	if v.ID != "CVE-2023-0286" || len(v.References) != 1 || v.References[0].ID != "GHSA-x4qr-2fvf-3mr5" {
+		t.Errorf("id/references = %s %+v", v.ID, v.References)
+	}
+	if len(v.Ratings) != 2 || v.Ratings[0].Method != "CVSSv31" || v.Ratings[1].Severity != "high" {
+		t.Errorf("ratings = %+v", v.Ratings)
+	}
+	if v.Recommendation != "Upgrade openssl to 3.1.5 or later" {
+		t.Errorf("recommendation = %q", v.Recommendation)
+	}
+
+	refs := map[string]bool{}
+	for _, c := range bom.Components {
+		refs[c.BOMRef] = true
+	}
+	if len(v.Affects) != 1 || !refs[v.Affects[0].Ref] {
+		t.Fatalf("affects = %+v, must reference a component bom-ref", v.Affects)
+	}
+	if vs := v.Affects[0].Versions; len(vs) != 1 || vs[0].Version != "3.1.4" || vs[0].Status != "affected" {
+		t.Errorf("affected versions = %+v", vs)
+	}
+}
+
+func TestCycloneDXDiagnostics(t *testing.T) {
+	result := makeTestResult()
+	result.Diagnostics = []model.Diagnostic{{
+		Severity: model.SeverityError, Strategy: "conan", File: "conan.lock", Line: 3, Message: "invalid JSON: unexpected end of JSON input",
+	}}
+
+	for _, spec := range []string{"1.4", "1.5"} {
+		tmp := filepath.Join(t.TempDir(), "sbom.json")
+		if err := WriteCycloneDX(result, tmp, CycloneDXOptions{ToolVersion: "test", SpecVersion: spec}); err != nil {
+			t.Fatalf("WriteCycloneDX failed: %v", err)
+		}
+		data, _ := os.ReadFile(tmp)
+		var bom struct {
+			Metadata struct {
+				Properties []struct {
+					Name  string `json:"name"`
+					Value string `json:"value"`
+				} `json:"properties"`
+			} `json:"metadata"`
+		}
+		if err := json.Unmarshal(data, &bom); err != nil {
+			t.Fatal(err)
+		}
+		props := bom.Metadata.Properties
+		if len(props) != 1 || props[0].Name != "cpp-sbom-builder:diagnostic" ||
+			props[0].Value != "error: conan: conan.lock:3: invalid JSON: unexpected end of JSON input" {
+			t.Errorf("%s: metadata.properties = %+v", spec, props)
+		}
+	}
+
+	var text strings.Builder
+	if err := WriteDiagnostics(&text, result.Diagnostics, "text"); err != nil {
+		t.Fatal(err)
+	}
+	want := "error   conan: conan.lock:3: invalid JSON: unexpected end of JSON input\nDiagnostics: 1 error(s), 0 warning(s)\n"
+	if text.String() != want {
+		t.Errorf("text report = %q, want %q", text.String(), want)
+	}
+
+	var js strings.Builder
+	if err := WriteDiagnostics(&js, result.Diagnostics, "json"); err != nil {
+		t.Fatal(err)
+	}
+	var report struct {
+		Diagnostics []struct {
+			File string `json:"file"`
+			Line int    `json:"line"`
+		} `json:"diagnostics"`
+		Errors int `json:"errors"`
+	}
+	if err := json.Unmarshal([]byte(js.String()), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Errors != 1 || len(report.Diagnostics) != 1 || report.Diagnostics[0].Line != 3 {
+		t.Errorf("json report = %s", js.String())
+	}
+}
+
+// TestCycloneDXEvidenceSources verifies that every strategy that reported a
+// component becomes an identity method, and its files occurrences.
+func TestCycloneDXEvidenceSources(t *testing.T) {
+	result := makeTestResult()
+	openssl := result.Components[1]
+	openssl.Sources = []model.Source{
+		{Strategy: "conan", File: "conan.lock", Version: "3.1.4"},
+		{Strategy: "compile_commands.json", File: "build/compile_commands.json", Version: "3.1.4"},
+		{Strategy: "linker-map", File: "build/app.map", Version: "unknown"},
+	}
+
+	tmp := filepath.Join(t.TempDir(), "sbom.json")
+	if err := WriteCycloneDX(result, tmp, CycloneDXOptions{ToolVersion: "test", SpecVersion: "1.5"}); err != nil {
+		t.Fatalf("WriteCycloneDX failed: %v", err)
+	}
+	data, _ := os.ReadFile(tmp)
+	var bom struct {
+		Components []struct {
+			Name     string `json:"name"`
+			Evidence struct {
+				Identity struct {
+					Confidence float64 `json:"confidence"`
+					Methods    []struct {
+						Technique string `json:"technique"`
+						Value     string `json:"value"`
+					} `json:"methods"`
+				} `json:"identity"`
+				Occurrences []struct {
+					Location string `json:"location"`
+				} `json:"occurrences"`
+			} `json:"evidence"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(data, &bom); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range bom.Components {
+		switch c.Name {
+		case "openssl":
+			ident := c.Evidence.Identity
+			if len(ident.Methods) != 3 || ident.Confidence != 0.9 {
+				t.Fatalf("openssl identity = %+v", ident)
+			}
+			if m := ident.Methods[2]; m.Technique != "filename" || m.Value != "linker-map: build/app.map, version unknown" {
+				t.Errorf("linker-map method = %+v", m)
+			}
+			var locations []string
+			for _, o := range c.Evidence.Occurrences {
+				locations = append(locations, o.Location)
+			}
+			if got := strings.Join(locations, ","); got != "conan.lock,build/compile_commands.json,build/app.map,/usr/include/openssl" {
+				t.Errorf("openssl occurrences = %s", got)
+			}
+		case "boost":
+			// No sources: the detection source stands in.
+			if m := c.Evidence.Identity.Methods; len(m) != 1 || m[0].Value != "conan" {
+				t.Errorf("boost methods = %+v", m)
+			}
+		}
+	}
+}
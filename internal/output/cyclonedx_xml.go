@@ -0,0 +1,467 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+
+	"github.com/StinkyLord/cpp-sbom-builder/internal/scanner"
+)
+
+// ---- CycloneDX XML types ----
+//
+// The XML encoding carries the same BOM content as the JSON one. Element order
+// follows the xs:sequence declarations of the CycloneDX XSDs, which is why the
+// struct fields are not grouped the way the JSON types are. One set of types
+// covers every supported version; the per-version serializers decide which
+// optional elements to populate.
+
+type xmlBOM struct {
+	XMLName      xml.Name         `xml:"bom"`
+	Xmlns        string           `xml:"xmlns,attr"`
+	SerialNumber string           `xml:"serialNumber,attr"`
+	Version      int              `xml:"version,attr"`
+	Metadata     xmlMetadata      `xml:"metadata"`
+	Components   xmlComponents    `xml:"components"`
+	Dependencies *xmlDependencies `xml:"dependencies,omitempty"`
+
+	Vulnerabilities *xmlVulnerabilities `xml:"vulnerabilities,omitempty"`
+
+	Formulation *xmlFormulation `xml:"formulation,omitempty"`
+}
+
+type xmlMetadata struct {
+	Timestamp  string         `xml:"timestamp"`
+	Lifecycles *xmlLifecycles `xml:"lifecycles,omitempty"`
+	Tools      xmlTools       `xml:"tools"`
+	Component  *xmlComponent  `xml:"component,omitempty"`
+	Properties *xmlProperties `xml:"properties,omitempty"`
+}
+
+type xmlLifecycles struct {
+	Lifecycle []cdxLifecycle `xml:"lifecycle"`
+}
+
+// xmlTools holds either the legacy <tool> list (1.4) or <components> (1.5+).
+type xmlTools struct {
+	Tool       []xmlLegacyTool    `xml:"tool,omitempty"`
+	Components *xmlToolComponents `xml:"components,omitempty"`
+}
+
+type xmlLegacyTool struct {
+	Vendor  string `xml:"vendor"`
+	Name    string `xml:"name"`
+	Version string `xml:"version"`
+}
+
+type xmlToolComponents struct {
+	Component []xmlToolComponent `xml:"component"`
+}
+
+type xmlToolComponent struct {
+	Type     string           `xml:"type,attr"`
+	Supplier *cdxOrganization `xml:"supplier,omitempty"`
+	Name     string           `xml:"name"`
+	Version  string           `xml:"version,omitempty"`
+}
+
+type xmlComponents struct {
+	Component []xmlComponent `xml:"component"`
+}
+
+type xmlComponent struct {
+	Type         string                 `xml:"type,attr"`
+	BOMRef       string                 `xml:"bom-ref,attr"`
+	Name         string                 `xml:"name"`
+	Version      string                 `xml:"version,omitempty"`
+	Description  string                 `xml:"description,omitempty"`
+	Hashes       *xmlHashes             `xml:"hashes,omitempty"`
+	Licenses     *xmlLicenses           `xml:"licenses,omitempty"`
+	PURL         string                 `xml:"purl,omitempty"`
+	ExternalRefs *xmlExternalReferences `xml:"externalReferences,omitempty"`
+	Properties   *xmlProperties         `xml:"properties,omitempty"`
+	Components   *xmlComponents         `xml:"components,omitempty"`
+	Evidence     *xmlEvidence           `xml:"evidence,omitempty"`
+}
+
+type xmlHashes struct {
+	Hash []cdxHash `xml:"hash"`
+}
+
+type xmlLicenses struct {
+	License    []cdxLicense `xml:"license"`
+	Expression string       `xml:"expression,omitempty"`
+}
+
+type xmlExternalReferences struct {
+	Reference []xmlExternalReference `xml:"reference"`
+}
+
+type xmlExternalReference struct {
+	Type    string `xml:"type,attr"`
+	URL     string `xml:"url"`
+	Comment string `xml:"comment,omitempty"`
+}
+
+type xmlProperties struct {
+	Property []xmlProperty `xml:"property"`
+}
+
+type xmlProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:",chardata"`
+}
+
+type xmlEvidence struct {
+	Identity    *xmlIdentity    `xml:"identity,omitempty"`
+	Occurrences *xmlOccurrences `xml:"occurrences,omitempty"`
+}
+
+type xmlIdentity struct {
+	Field      string      `xml:"field"`
+	Confidence string      `xml:"confidence"`
+	Methods    *xmlMethods `xml:"methods,omitempty"`
+}
+
+type xmlMethods struct {
+	Method []xmlMethod `xml:"method"`
+}
+
+type xmlMethod struct {
+	Technique  string `xml:"technique"`
+	Confidence string `xml:"confidence"`
+	Value      string `xml:"value,omitempty"`
+}
+
+type xmlOccurrences struct {
+	Occurrence []cdxOccurrence `xml:"occurrence"`
+}
+
+type xmlVulnerabilities struct {
+	Vulnerability []xmlVulnerability `xml:"vulnerability"`
+}
+
+type xmlVulnerability struct {
+	BOMRef         string         `xml:"bom-ref,attr,omitempty"`
+	ID             string         `xml:"id"`
+	Source         *cdxVulnSource `xml:"source,omitempty"`
+	References     *xmlVulnRefs   `xml:"references,omitempty"`
+	Ratings        *xmlRatings    `xml:"ratings,omitempty"`
+	Description    string         `xml:"description,omitempty"`
+	Detail         string         `xml:"detail,omitempty"`
+	Recommendation string         `xml:"recommendation,omitempty"`
+	Advisories     *xmlAdvisories `xml:"advisories,omitempty"`
+	Published      string         `xml:"published,omitempty"`
+	Updated        string         `xml:"updated,omitempty"`
+	Affects        *xmlAffects    `xml:"affects,omitempty"`
+}
+
+type xmlVulnRefs struct {
+	Reference []cdxVulnRef `xml:"reference"`
+}
+
+type xmlRatings struct {
+	Rating []cdxRating `xml:"rating"`
+}
+
+type xmlAdvisories struct {
+	Advisory []cdxAdvisory `xml:"advisory"`
+}
+
+type xmlAffects struct {
+	Target []xmlAffect `xml:"target"`
+}
+
+type xmlAffect struct {
+	Ref      string               `xml:"ref"`
+	Versions *xmlAffectedVersions `xml:"versions,omitempty"`
+}
+
+type xmlAffectedVersions struct {
+	Version []cdxAffectedVers `xml:"version"`
+}
+
+type xmlDependencies struct {
+	Dependency []xmlDependency `xml:"dependency"`
+}
+
+type xmlDependency struct {
+	Ref        string          `xml:"ref,attr"`
+	Dependency []xmlDependency `xml:"dependency,omitempty"`
+}
+
+type xmlFormulation struct {
+	Formula []xmlFormula `xml:"formula"`
+}
+
+type xmlFormula struct {
+	BOMRef    string       `xml:"bom-ref,attr"`
+	Workflows xmlWorkflows `xml:"workflows"`
+}
+
+type xmlWorkflows struct {
+	Workflow []xmlWorkflow `xml:"workflow"`
+}
+
+type xmlWorkflow struct {
+	BOMRef    string       `xml:"bom-ref,attr"`
+	UID       string       `xml:"uid"`
+	Name      string       `xml:"name,omitempty"`
+	Tasks     *xmlTasks    `xml:"tasks,omitempty"`
+	TaskTypes xmlTaskTypes `xml:"taskTypes"`
+}
+
+type xmlTasks struct {
+	Task []xmlTask `xml:"task"`
+}
+
+type xmlTask struct {
+	BOMRef    string       `xml:"bom-ref,attr"`
+	UID       string       `xml:"uid"`
+	Name      string       `xml:"name,omitempty"`
+	TaskTypes xmlTaskTypes `xml:"taskTypes"`
+}
+
+type xmlTaskTypes struct {
+	TaskType []string `xml:"taskType"`
+}
+
+// WriteCycloneDXXML serialises the scan result as a CycloneDX XML SBOM of the
+// requested spec version and writes it to the given output path. If
+// outputPath is "-", it writes to stdout.
+func WriteCycloneDXXML(result *scanner.Result, outputPath string, opts CycloneDXOptions) error {
+	ser, err := cdxSerializerFor(opts.SpecVersion)
+	if err != nil {
+		return err
+	}
+
+	doc, err := buildCycloneDX(result, opts)
+	if err != nil {
+		return err
+	}
+	bom := ser.serializeXML(doc)
+
+	data, err := xml.MarshalIndent(bom, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal CycloneDX XML: %w", err)
+	}
+
+	return writeOutput(outputPath, append([]byte(xml.Header), data...))
+}
+
+// xmlNamespace returns the CycloneDX XML namespace for a spec version.
+func xmlNamespace(specVersion string) string {
+	return "http://cyclonedx.org/schema/bom/" + specVersion
+}
+
+// buildXMLBOM renders doc as XML. extended enables the elements introduced in
+// CycloneDX 1.5 (lifecycles, tools as components, identity evidence,
+// formulation); 1.4 documents are written without them.
+func buildXMLBOM(doc *cdxDoc, specVersion string, extended bool) *xmlBOM {
+	bom := &xmlBOM{
+		Xmlns:        xmlNamespace(specVersion),
+		SerialNumber: doc.SerialNumber,
+		Version:      1,
+		Metadata:     xmlMetadata{Timestamp: doc.Timestamp},
+	}
+
+	if extended {
+		if doc.Lifecycle != "" {
+			bom.Metadata.Lifecycles = &xmlLifecycles{Lifecycle: []cdxLifecycle{{Phase: doc.Lifecycle}}}
+		}
+		bom.Metadata.Tools.Components = &xmlToolComponents{Component: []xmlToolComponent{{
+			Type:     "application",
+			Supplier: &cdxOrganization{Name: doc.Tool.Vendor},
+			Name:     doc.Tool.Name,
+			Version:  doc.Tool.Version,
+		}}}
+	} else {
+		bom.Metadata.Tools.Tool = []xmlLegacyTool{{
+			Vendor:  doc.Tool.Vendor,
+			Name:    doc.Tool.Name,
+			Version: doc.Tool.Version,
+		}}
+	}
+
+	if doc.Project != nil {
+		project := xmlComponentFor(*doc.Project)
+		bom.Metadata.Component = &project
+	}
+	if len(doc.Properties) > 0 {
+		bom.Metadata.Properties = &xmlProperties{}
+		for _, p := range doc.Properties {
+			bom.Metadata.Properties.Property = append(bom.Metadata.Properties.Property, xmlProperty(p))
+		}
+	}
+
+	for _, c := range doc.Components {
+		xc := xmlComponentFor(c.cdxComponent)
+		if extended {
+			xc.Evidence = xmlEvidenceFor(c)
+		}
+		bom.Components.Component = append(bom.Components.Component, xc)
+	}
+
+	if len(doc.Dependencies) > 0 {
+		bom.Dependencies = &xmlDependencies{}
+		for _, d := range doc.Dependencies {
+			xd := xmlDependency{Ref: d.Ref}
+			for _, child := range d.DependsOn {
+				xd.Dependency = append(xd.Dependency, xmlDependency{Ref: child})
+			}
+			bom.Dependencies.Dependency = append(bom.Dependencies.Dependency, xd)
+		}
+	}
+
+	if len(doc.Vulnerabilities) > 0 {
+		bom.Vulnerabilities = &xmlVulnerabilities{}
+		for _, v := range doc.Vulnerabilities {
+			bom.Vulnerabilities.Vulnerability = append(bom.Vulnerabilities.Vulnerability, xmlVulnerabilityFor(v))
+		}
+	}
+
+	if extended {
+		for _, f := range formulationFor(doc) {
+			if bom.Formulation == nil {
+				bom.Formulation = &xmlFormulation{}
+			}
+			xf := xmlFormula{BOMRef: f.BOMRef}
+			for _, wf := range f.Workflows {
+				xw := xmlWorkflow{
+					BOMRef:    wf.BOMRef,
+					UID:       wf.UID,
+					Name:      wf.Name,
+					TaskTypes: xmlTaskTypes{TaskType: wf.TaskTypes},
+				}
+				if len(wf.Tasks) > 0 {
+					xw.Tasks = &xmlTasks{}
+					for _, t := range wf.Tasks {
+						xw.Tasks.Task = append(xw.Tasks.Task, xmlTask{
+							BOMRef:    t.BOMRef,
+							UID:       t.UID,
+							Name:      t.Name,
+							TaskTypes: xmlTaskTypes{TaskType: t.TaskTypes},
+						})
+					}
+				}
+				xf.Workflows.Workflow = append(xf.Workflows.Workflow, xw)
+			}
+			bom.Formulation.Formula = append(bom.Formulation.Formula, xf)
+		}
+	}
+
+	return bom
+}
+
+// xmlComponentFor converts a component and its nested file components.
+func xmlComponentFor(c cdxComponent) xmlComponent {
+	xc := xmlComponent{
+		Type:        c.Type,
+		BOMRef:      c.BOMRef,
+		Name:        c.Name,
+		Version:     c.Version,
+		Description: c.Description,
+		PURL:        c.PURL,
+	}
+	if len(c.Hashes) > 0 {
+		xc.Hashes = &xmlHashes{Hash: c.Hashes}
+	}
+	if len(c.Licenses) > 0 {
+		xc.Licenses = &xmlLicenses{}
+		for _, l := range c.Licenses {
+			if l.Expression != "" {
+				xc.Licenses.Expression = l.Expression
+			} else if l.License != nil {
+				xc.Licenses.License = append(xc.Licenses.License, *l.License)
+			}
+		}
+	}
+	if len(c.ExternalReferences) > 0 {
+		xc.ExternalRefs = &xmlExternalReferences{}
+		for _, ref := range c.ExternalReferences {
+			xc.ExternalRefs.Reference = append(xc.ExternalRefs.Reference, xmlExternalReference(ref))
+		}
+	}
+	if len(c.Properties) > 0 {
+		xc.Properties = &xmlProperties{}
+		for _, p := range c.Properties {
+			xc.Properties.Property = append(xc.Properties.Property, xmlProperty(p))
+		}
+	}
+	if len(c.Components) > 0 {
+		xc.Components = &xmlComponents{}
+		for _, sub := range c.Components {
+			xc.Components.Component = append(xc.Components.Component, xmlComponentFor(sub))
+		}
+	}
+	return xc
+}
+
+// xmlVulnerabilityFor wraps the lists of v in the container elements the XSD
+// declares; the shared wire types already follow its element order.
+func xmlVulnerabilityFor(v cdxVulnerability) xmlVulnerability {
+	xv := xmlVulnerability{
+		ID:             v.ID,
+		Source:         v.Source,
+		Description:    v.Description,
+		Detail:         v.Detail,
+		Recommendation: v.Recommendation,
+		Published:      v.Published,
+		Updated:        v.Updated,
+	}
+	if len(v.References) > 0 {
+		xv.References = &xmlVulnRefs{Reference: v.References}
+	}
+	if len(v.Ratings) > 0 {
+		xv.Ratings = &xmlRatings{Rating: v.Ratings}
+	}
+	if len(v.Advisories) > 0 {
+		xv.Advisories = &xmlAdvisories{Advisory: v.Advisories}
+	}
+	if len(v.Affects) > 0 {
+		xv.Affects = &xmlAffects{}
+		for _, a := range v.Affects {
+			xa := xmlAffect{Ref: a.Ref}
+			if len(a.Versions) > 0 {
+				xa.Versions = &xmlAffectedVersions{Version: a.Versions}
+			}
+			xv.Affects.Target = append(xv.Affects.Target, xa)
+		}
+	}
+	return xv
+}
+
+func xmlEvidenceFor(c cdxComponentData) *xmlEvidence {
+	ident := identityFor(c)
+	occurrences := occurrencesFor(c)
+	if ident == nil && len(occurrences) == 0 {
+		return nil
+	}
+
+	ev := &xmlEvidence{}
+	if ident != nil {
+		xi := &xmlIdentity{
+			Field:      ident.Field,
+			Confidence: formatConfidence(ident.Confidence),
+		}
+		if len(ident.Methods) > 0 {
+			xi.Methods = &xmlMethods{}
+			for _, m := range ident.Methods {
+				xi.Methods.Method = append(xi.Methods.Method, xmlMethod{
+					Technique:  m.Technique,
+					Confidence: formatConfidence(m.Confidence),
+					Value:      m.Value,
+				})
+			}
+		}
+		ev.Identity = xi
+	}
+	if len(occurrences) > 0 {
+		ev.Occurrences = &xmlOccurrences{Occurrence: occurrences}
+	}
+	return ev
+}
+
+func formatConfidence(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,141 @@
+package output
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/StinkyLord/cpp-sbom-builder/internal/model"
+)
+
+func TestCycloneDXXML(t *testing.T) {
+	for _, version := range SupportedSpecVersions {
+		t.Run(version, func(t *testing.T) {
+			tmp := filepath.Join(t.TempDir(), "sbom.xml")
+			opts := CycloneDXOptions{ToolVersion: "test", SpecVersion: version}
+			if err := WriteCycloneDXXML(makeTestResult(), tmp, opts); err != nil {
+				t.Fatalf("WriteCycloneDXXML failed: %v", err)
+			}
+			data, err := os.ReadFile(tmp)
+			if err != nil {
+				t.Fatalf("cannot read output file: %v", err)
+			}
+
+			var bom struct {
+				XMLName    xml.Name
+				Serial     string    `xml:"serialNumber,attr"`
+				Lifecycles *struct{} `xml:"metadata>lifecycles"`
+				Components []struct {
+					BOMRef   string    `xml:"bom-ref,attr"`
+					Name     string    `xml:"name"`
+					Evidence *struct{} `xml:"evidence"`
+				} `xml:"components>component"`
+				Dependencies []struct {
+					Ref       string `xml:"ref,attr"`
+					DependsOn []struct {
+						Ref string `xml:"ref,attr"`
+					} `xml:"dependency"`
+				} `xml:"dependencies>dependency"`
+				Formulation *struct{} `xml:"formulation"`
+			}
+			if err := xml.Unmarshal(data, &bom); err != nil {
+				t.Fatalf("output is not valid XML: %v", err)
+			}
+
+			if want := "http://cyclonedx.org/schema/bom/" + version; bom.XMLName.Space != want {
+				t.Errorf("namespace = %q, want %q", bom.XMLName.Space, want)
+			}
+			if bom.XMLName.Local != "bom" {
+				t.Errorf("root element = %q, want bom", bom.XMLName.Local)
+			}
+			if bom.Serial == "" {
+				t.Error("serialNumber attribute is missing")
+			}
+			if len(bom.Components) != 4 {
+				t.Errorf("expected 4 components, got %d", len(bom.Components))
+			}
+			if len(bom.Dependencies) != 4 {
+				t.Errorf("expected 4 dependency entries, got %d", len(bom.Dependencies))
+			}
+
+			edges := map[string][]string{}
+			for _, d := range bom.Dependencies {
+				for _, child := range d.DependsOn {
+					edges[d.Ref] = append(edges[d.Ref], child.Ref)
+				}
+			}
+			if got := edges["openssl@3.1.4"]; len(got) != 1 || got[0] != "zlib@1.2.13" {
+				t.Errorf("openssl dependsOn = %v, want [zlib@1.2.13]", got)
+			}
+
+			extended := version != "1.4"
+			if (bom.Lifecycles != nil) != extended {
+				t.Errorf("lifecycles present = %v, want %v", bom.Lifecycles != nil, extended)
+			}
+			if (bom.Formulation != nil) != extended {
+				t.Errorf("formulation present = %v, want %v", bom.Formulation != nil, extended)
+			}
+			for _, c := range bom.Components {
+				if (c.Evidence != nil) != extended {
+					t.Errorf("component %q evidence present = %v, want %v", c.Name, c.Evidence != nil, extended)
+				}
+			}
+		})
+	}
+}
+
+func TestCycloneDXXMLVulnerabilities(t *testing.T) {
+	result := makeTestResult()
+	result.Vulnerabilities = []model.Vulnerability{testVulnerability()}
+
+	for _, version := range SupportedSpecVersions {
+		tmp := filepath.Join(t.TempDir(), "sbom.xml")
+		if err := WriteCycloneDXXML(result, tmp, CycloneDXOptions{ToolVersion: "test", SpecVersion: version}); err != nil {
+			t.Fatalf("WriteCycloneDXXML failed: %v", err)
+		}
+		data, _ := os.ReadFile(tmp)
+		var bom struct {
+			Vulnerabilities []struct {
+				ID         string `xml:"id"`
+				SourceName string `xml:"source>name"`
+				References []struct {
+					ID string `xml:"id"`
+				} `xml:"references>reference"`
+				Ratings []struct {
+					Severity string `xml:"severity"`
+					Method   string `xml:"method"`
+				} `xml:"ratings>rating"`
+				Recommendation string `xml:"recommendation"`
+				Targets        []struct {
+					Ref      string `xml:"ref"`
+					Versions []struct {
+						Version string `xml:"version"`
+						Status  string `xml:"status"`
+					} `xml:"versions>version"`
+				} `xml:"affects>target"`
+			} `xml:"vulnerabilities>vulnerability"`
+		}
+		if err := xml.Unmarshal(data, &bom); err != nil {
+			t.Fatalf("%s: output is not valid XML: %v", version, err)
+		}
+
+		if len(bom.Vulnerabilities) != 1 {
+			t.Fatalf("%s: vulnerabilities = %d, want 1", version, len(bom.Vulnerabilities))
+		}
+		v := bom.Vulnerabilities[0]
+		if v.ID != "CVE-2023-0286" || v.SourceName != "OSV" || len(v.References) != 1 || v.References[0].ID != "GHSA-x4qr-2fvf-3mr5" {
+			t.Errorf("%s: id/source/references = %+v", version, v)
+		}
+		if len(v.Ratings) != 2 || v.Ratings[0].Method != "CVSSv31" || v.Ratings[1].Severity != "high" {
+			t.Errorf("%s: ratings = %+v", version, v.Ratings)
+		}
+		if v.Recommendation != "Upgrade openssl to 3.1.5 or later" {
+			t.Errorf("%s: recommendation = %q", version, v.Recommendation)
+		}
+		if len(v.Targets) != 1 || v.Targets[0].Ref != "openssl@3.1.4" ||
+			len(v.Targets[0].Versions) != 1 || v.Targets[0].Versions[0].Status != "affected" {
+			t.Errorf("%s: affects = %+v", version, v.Targets)
+		}
+	}
+}
@@ -22,11 +22,16 @@ func WriteDependencyTree(result *scanner.Result, outputPath string) error {
 func writeJSON(outputPath string, v any) error {
 	data, err := json.MarshalIndent(v, "", "  ")
 	if err != nil {
-		return fmt.Errorf("failed to marshal dependency tree JSON: %w", err)
+		return fmt.Errorf("failed to marshal JSON: %w", err)
 	}
 
+	return writeOutput(outputPath, data)
+}
+
+// writeOutput writes data followed by a newline to outputPath (or stdout if "-").
+func writeOutput(outputPath string, data []byte) error {
 	if outputPath == "-" {
-		_, err = os.Stdout.Write(data)
+		_, err := os.Stdout.Write(data)
 		if err == nil {
 			_, err = os.Stdout.WriteString("\n")
 		}
@@ -0,0 +1,80 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/StinkyLord/cpp-sbom-builder/internal/model"
+)
+
+// DiagnosticsFormats lists the formats WriteDiagnostics supports.
+var DiagnosticsFormats = []string{"text", "json"}
+
+// WriteDiagnostics writes the diagnostics of a scan as a report in the given
+// format (see DiagnosticsFormats).
+func WriteDiagnostics(w io.Writer, diags []model.Diagnostic, format string) error {
+	switch format {
+	case "text":
+		return writeDiagnosticsText(w, diags)
+	case "json":
+		return writeDiagnosticsJSON(w, diags)
+	}
+	return fmt.Errorf("unsupported diagnostics format %q", format)
+}
+
+func writeDiagnosticsText(w io.Writer, diags []model.Diagnostic) error {
+	for _, d := range diags {
+		where := d.Strategy
+		if where == "" {
+			where = "scan"
+		}
+		if loc := d.Location(); loc != "" {
+			where += ": " + loc
+		}
+		fmt.Fprintf(w, "%-7s %s: %s\n", d.Severity, where, d.Message)
+	}
+	errors, warnings := CountDiagnostics(diags)
+	_, err := fmt.Fprintf(w, "Diagnostics: %d error(s), %d warning(s)\n", errors, warnings)
+	return err
+}
+
+type jsonDiagnostic struct {
+	Severity string `json:"severity"`
+	Strategy string `json:"strategy,omitempty"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Message  string `json:"message"`
+}
+
+func writeDiagnosticsJSON(w io.Writer, diags []model.Diagnostic) error {
+	out := struct {
+		Diagnostics []jsonDiagnostic `json:"diagnostics"`
+		Errors      int              `json:"errors"`
+		Warnings    int              `json:"warnings"`
+	}{Diagnostics: []jsonDiagnostic{}}
+	for _, d := range diags {
+		out.Diagnostics = append(out.Diagnostics, jsonDiagnostic(d))
+	}
+	out.Errors, out.Warnings = CountDiagnostics(diags)
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal diagnostics: %w", err)
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// CountDiagnostics returns the number of error and warning diagnostics.
+func CountDiagnostics(diags []model.Diagnostic) (errors, warnings int) {
+	for _, d := range diags {
+		switch d.Severity {
+		case model.SeverityError:
+			errors++
+		case model.SeverityWarning:
+			warnings++
+		}
+	}
+	return errors, warnings
+}
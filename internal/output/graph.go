@@ -0,0 +1,202 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/StinkyLord/cpp-sbom-builder/internal/model"
+	"github.com/StinkyLord/cpp-sbom-builder/internal/scanner"
+)
+
+// ---- Dependency graph diagrams (Graphviz DOT and Mermaid) ----
+//
+// Unlike DependencyTree.Roots, which expands a shared dependency once per path
+// that reaches it, the diagrams draw every component exactly once: a library
+// pulled in by two parents is one node with two incoming edges.
+
+type graphNode struct {
+	ID      string
+	Name    string
+	Version string
+
+	// Kind is "project", "direct", "transitive" or "missing" (referenced by
+	// an edge but not detected as a component).
+	Kind string
+}
+
+type graphEdge struct {
+	From, To string
+
+	// Sources are the strategies that reported the edge.
+	Sources []string
+}
+
+type depGraph struct {
+	Nodes []graphNode
+	Edges []graphEdge
+}
+
+// buildGraph flattens the scan result into nodes and edges. Nodes are sorted
+// by bom-ref, with the project first, and edges by source then target node,
+// so the diagrams are stable across runs.
+func buildGraph(result *scanner.Result) *depGraph {
+	comps := make([]*model.Component, len(result.Components))
+	copy(comps, result.Components)
+	sort.Slice(comps, func(i, j int) bool {
+		return comps[i].BOMRef() < comps[j].BOMRef()
+	})
+
+	tree := result.DependencyTree
+	if tree == nil {
+		tree = model.BuildDependencyTree(comps)
+	}
+
+	g := &depGraph{}
+	ids := map[string]string{}
+	addNode := func(key, name, version, kind string) string {
+		if id, ok := ids[key]; ok {
+			return id
+		}
+		id := fmt.Sprintf("n%d", len(g.Nodes))
+		ids[key] = id
+		g.Nodes = append(g.Nodes, graphNode{ID: id, Name: name, Version: version, Kind: kind})
+		return id
+	}
+
+	var projectID string
+	if p := result.Project; p != nil {
+		projectID = addNode("project:"+p.BOMRef(), p.Name, p.Version, "project")
+	}
+	for _, c := range comps {
+		addNode(c.BOMRef(), c.Name, c.Version, c.DependencyType())
+	}
+
+	if projectID != "" {
+		for _, name := range result.Project.Dependencies {
+			if child := tree.Lookup(name); child != nil {
+				g.Edges = append(g.Edges, graphEdge{From: projectID, To: ids[child.BOMRef()]})
+			}
+		}
+	}
+	for _, c := range comps {
+		from := ids[c.BOMRef()]
+		seen := map[string]bool{}
+		for _, childName := range c.Dependencies {
+			var to string
+			if child := tree.Lookup(childName); child == nil {
+				to = addNode("missing:"+childName, childName, "unknown", "missing")
+			} else if child != c {
+				to = ids[child.BOMRef()]
+			}
+			if to == "" || seen[to] {
+				continue
+			}
+			seen[to] = true
+			g.Edges = append(g.Edges, graphEdge{From: from, To: to, Sources: c.DependencySources[childName]})
+		}
+	}
+
+	order := make(map[string]int, len(g.Nodes))
+	for i, n := range g.Nodes {
+		order[n.ID] = i
+	}
+	sort.SliceStable(g.Edges, func(i, j int) bool {
+		if g.Edges[i].From != g.Edges[j].From {
+			return order[g.Edges[i].From] < order[g.Edges[j].From]
+		}
+		return order[g.Edges[i].To] < order[g.Edges[j].To]
+	})
+	return g
+}
+
+// label is the text shown in a node: the name, and the version when known.
+func (n graphNode) label(sep string) string {
+	if n.Version == "" || n.Version == "unknown" {
+		return n.Name
+	}
+	return n.Name + sep + n.Version
+}
+
+// WriteDOT renders the dependency graph in Graphviz DOT format. Direct
+// dependencies are filled boxes with a bold border, transitive ones plain
+// boxes, and edges are labelled with the strategies that reported them. If
+// outputPath is "-", it writes to stdout.
+func WriteDOT(result *scanner.Result, outputPath string) error {
+	return writeOutput(outputPath, []byte(renderDOT(buildGraph(result))))
+}
+
+func renderDOT(g *depGraph) string {
+	var b strings.Builder
+	b.WriteString("digraph dependencies {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, style=\"rounded\", fontname=\"Helvetica\"];\n")
+	b.WriteString("  edge [fontname=\"Helvetica\", fontsize=10];\n")
+
+	for _, n := range g.Nodes {
+		var attrs string
+		switch n.Kind {
+		case "project":
+			attrs = `shape=box3d, style="filled", fillcolor="#d3f9d8", penwidth=2`
+		case "direct":
+			attrs = `style="rounded,filled", fillcolor="#d0ebff", penwidth=2`
+		case "missing":
+			attrs = `style="rounded,dashed", fontcolor="#868e96"`
+		default:
+			attrs = `color="#868e96"`
+		}
+		fmt.Fprintf(&b, "  %s [label=%s, %s];\n", n.ID, dotQuote(n.label("\n")), attrs)
+	}
+
+	for _, e := range g.Edges {
+		if len(e.Sources) > 0 {
+			fmt.Fprintf(&b, "  %s -> %s [label=%s];\n", e.From, e.To, dotQuote(strings.Join(e.Sources, ", ")))
+		} else {
+			fmt.Fprintf(&b, "  %s -> %s;\n", e.From, e.To)
+		}
+	}
+
+	b.WriteString("}")
+	return b.String()
+}
+
+// dotQuote returns s as a DOT double-quoted string.
+func dotQuote(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+	return `"` + s + `"`
+}
+
+// WriteMermaid renders the dependency graph as a Mermaid flowchart, ready to
+// paste into a ```mermaid block. Node classes distinguish the project, direct
+// and transitive dependencies, and edges are labelled with the strategies
+// that reported them. If outputPath is "-", it writes to stdout.
+func WriteMermaid(result *scanner.Result, outputPath string) error {
+	return writeOutput(outputPath, []byte(renderMermaid(buildGraph(result))))
+}
+
+func renderMermaid(g *depGraph) string {
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	b.WriteString("  classDef project fill:#d3f9d8,stroke:#2b8a3e,stroke-width:2px\n")
+	b.WriteString("  classDef direct fill:#d0ebff,stroke:#1864ab,stroke-width:2px\n")
+	b.WriteString("  classDef transitive fill:#ffffff,stroke:#868e96\n")
+	b.WriteString("  classDef missing fill:#ffffff,stroke:#868e96,stroke-dasharray:4 4,color:#868e96\n")
+
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&b, "  %s[%s]:::%s\n", n.ID, mermaidQuote(n.label(" ")), n.Kind)
+	}
+	for _, e := range g.Edges {
+		if len(e.Sources) > 0 {
+			fmt.Fprintf(&b, "  %s -->|%s| %s\n", e.From, mermaidQuote(strings.Join(e.Sources, ", ")), e.To)
+		} else {
+			fmt.Fprintf(&b, "  %s --> %s\n", e.From, e.To)
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// mermaidQuote returns s as a Mermaid quoted label. Quotes inside the label
+// are written as the #quot; entity, the only escape Mermaid understands.
+func mermaidQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
@@ -0,0 +1,107 @@
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/StinkyLord/cpp-sbom-builder/internal/model"
+	"github.com/StinkyLord/cpp-sbom-builder/internal/scanner"
+)
+
+// makeDiamondResult builds app-facing libraries a and b that both depend on
+// c: the diamond buildTree expands into two copies of c.
+func makeDiamondResult() *scanner.Result {
+	a := &model.Component{
+		Name: "a", Version: "1.0", IsDirect: true,
+		Dependencies:      []string{"c"},
+		DependencySources: map[string][]string{"c": {"conan-graph"}},
+	}
+	b := &model.Component{
+		Name: "b", Version: "2.0", IsDirect: true,
+		Dependencies:      []string{"c", "ghost"},
+		DependencySources: map[string][]string{"c": {"conan-graph", "ldd"}, "ghost": {"ldd"}},
+	}
+	c := &model.Component{Name: "c", Version: "3.0"}
+
+	components := []*model.Component{a, b, c}
+	return &scanner.Result{
+		Project: &model.Component{
+			Name: "app", Version: "0.1", Dependencies: []string{"a", "b"},
+		},
+		Components:     components,
+		DependencyTree: model.BuildDependencyTree(components),
+	}
+}
+
+// TestBuildGraph_SharedDiamond verifies that a dependency reached through two
+// parents is a single node with two incoming edges.
+func TestBuildGraph_SharedDiamond(t *testing.T) {
+	g := buildGraph(makeDiamondResult())
+
+	kinds := map[string]string{}
+	idByName := map[string]string{}
+	for _, n := range g.Nodes {
+		if _, dup := idByName[n.Name]; dup {
+			t.Errorf("node %q appears more than once", n.Name)
+		}
+		idByName[n.Name] = n.ID
+		kinds[n.Name] = n.Kind
+	}
+	want := map[string]string{"app": "project", "a": "direct", "b": "direct", "c": "transitive", "ghost": "missing"}
+	for name, kind := range want {
+		if kinds[name] != kind {
+			t.Errorf("node %q kind = %q, want %q", name, kinds[name], kind)
+		}
+	}
+
+	into := map[string][]graphEdge{}
+	for _, e := range g.Edges {
+		into[e.To] = append(into[e.To], e)
+	}
+	if n := len(into[idByName["c"]]); n != 2 {
+		t.Fatalf("edges into c = %d, want 2", n)
+	}
+	for _, e := range into[idByName["c"]] {
+		if e.From == idByName["b"] && strings.Join(e.Sources, ",") != "conan-graph,ldd" {
+			t.Errorf("b -> c sources = %v, want [conan-graph ldd]", e.Sources)
+		}
+	}
+	if n := len(into[idByName["a"]]); n != 1 || into[idByName["a"]][0].From != idByName["app"] {
+		t.Errorf("a should hang off the project, got %+v", into[idByName["a"]])
+	}
+}
+
+func TestRenderDOT(t *testing.T) {
+	out := renderDOT(buildGraph(makeDiamondResult()))
+
+	for _, want := range []string{
+		"digraph dependencies {",
+		`[label="c\n3.0", color="#868e96"]`,
+		`[label="conan-graph, ldd"]`,
+		"shape=box3d",
+		"style=\"rounded,dashed\"",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("DOT output missing %q:\n%s", want, out)
+		}
+	}
+	if n := strings.Count(out, `label="c\n3.0"`); n != 1 {
+		t.Errorf("c declared %d times, want 1", n)
+	}
+}
+
+func TestRenderMermaid(t *testing.T) {
+	out := renderMermaid(buildGraph(makeDiamondResult()))
+
+	if !strings.HasPrefix(out, "flowchart LR\n") {
+		t.Errorf("mermaid output should start with flowchart LR:\n%s", out)
+	}
+	for _, want := range []string{`["a 1.0"]:::direct`, `["c 3.0"]:::transitive`, `-->|"conan-graph, ldd"|`, `["app 0.1"]:::project`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("mermaid output missing %q:\n%s", want, out)
+		}
+	}
+	if got := mermaidQuote(`say "hi"`); got != `"say #quot;hi#quot;"` {
+		t.Errorf("mermaidQuote = %s", got)
+	}
+}
@@ -0,0 +1,164 @@
+package output
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+
+	"github.com/StinkyLord/cpp-sbom-builder/internal/model"
+	"github.com/StinkyLord/cpp-sbom-builder/internal/scanner"
+)
+
+// reportTemplate is the HTML report. All CSS and JavaScript is inline so the
+// file can be opened offline and attached to reviews as-is.
+//
+//go:embed report.html.tmpl
+var reportTemplate string
+
+// HTMLOptions controls how WriteHTML renders a scan result.
+type HTMLOptions struct {
+	// ToolVersion is shown in the report footer.
+	ToolVersion string
+
+	// Title heads the report, typically the scanned project directory.
+	Title string
+
+	// Reproducible timestamps the report with SOURCE_DATE_EPOCH or the Unix
+	// epoch instead of the current time.
+	Reproducible bool
+}
+
+type htmlReport struct {
+	Title       string
+	Generated   string
+	ToolVersion string
+
+	Project    *htmlComponent
+	Components []htmlComponent
+	Direct     int
+	Transitive int
+
+	Tree              []*model.TreeNode
+	StrategiesUsed    []string
+	StrategiesSkipped []string
+}
+
+type htmlComponent struct {
+	ID              string
+	Name            string
+	Version         string
+	PURL            string
+	DependencyType  string
+	DetectionSource string
+	Description     string
+	Licenses        string
+	IncludePaths    []string
+	LinkLibraries   []string
+	Dependencies    []string
+}
+
+// htmlPanel is the data of one component's detail panel. The strategy lists
+// are scan-wide; the panel highlights the one that detected the component.
+type htmlPanel struct {
+	Component htmlComponent
+	Used      []string
+	Skipped   []string
+}
+
+// WriteHTML renders the scan result as a single self-contained HTML report: a
+// sortable component table, a collapsible dependency tree and a detail panel
+// per component. If outputPath is "-", it writes to stdout.
+func WriteHTML(result *scanner.Result, outputPath string, opts HTMLOptions) error {
+	report, err := buildHTMLReport(result, opts)
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := template.New("report").Funcs(template.FuncMap{
+		"nodeID": func(n *model.TreeNode) string {
+			return htmlComponentID(&model.Component{Name: n.Name, Version: n.Version})
+		},
+		"panelData": func(c htmlComponent, used, skipped []string) htmlPanel {
+			return htmlPanel{Component: c, Used: used, Skipped: skipped}
+		},
+	}).Parse(reportTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse HTML template: %w", err)
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, report); err != nil {
+		return fmt.Errorf("failed to render HTML report: %w", err)
+	}
+	return writeOutput(outputPath, []byte(b.String()))
+}
+
+func buildHTMLReport(result *scanner.Result, opts HTMLOptions) (*htmlReport, error) {
+	generated, err := creationTime(opts.Reproducible)
+	if err != nil {
+		return nil, err
+	}
+
+	comps := make([]*model.Component, len(result.Components))
+	copy(comps, result.Components)
+	sort.Slice(comps, func(i, j int) bool {
+		return comps[i].BOMRef() < comps[j].BOMRef()
+	})
+
+	report := &htmlReport{
+		Title:             opts.Title,
+		Generated:         generated,
+		ToolVersion:       opts.ToolVersion,
+		StrategiesUsed:    result.StrategiesUsed,
+		StrategiesSkipped: result.StrategiesSkipped,
+	}
+	if report.Title == "" {
+		report.Title = "cpp-sbom-builder scan"
+	}
+	if result.Project != nil {
+		p := componentToHTML(result.Project)
+		p.DependencyType = "project"
+		report.Project = &p
+	}
+	for _, c := range comps {
+		report.Components = append(report.Components, componentToHTML(c))
+		if c.IsDirect {
+			report.Direct++
+		} else {
+			report.Transitive++
+		}
+	}
+	if result.DependencyTree != nil {
+		report.Tree = result.DependencyTree.Roots
+	} else {
+		report.Tree = model.BuildDependencyTree(comps).Roots
+	}
+	return report, nil
+}
+
+func componentToHTML(c *model.Component) htmlComponent {
+	deps := make([]string, len(c.Dependencies))
+	copy(deps, c.Dependencies)
+	sort.Strings(deps)
+	return htmlComponent{
+		ID:              htmlComponentID(c),
+		Name:            c.Name,
+		Version:         c.Version,
+		PURL:            c.PURL,
+		DependencyType:  c.DependencyType(),
+		DetectionSource: c.DetectionSource,
+		Description:     c.Description,
+		Licenses:        strings.Join(c.Licenses, ", "),
+		IncludePaths:    c.IncludePaths,
+		LinkLibraries:   c.LinkLibraries,
+		Dependencies:    deps,
+	}
+}
+
+// htmlComponentID is the element ID of a component's detail panel, shared by
+// table rows and tree nodes so both can open it.
+func htmlComponentID(c *model.Component) string {
+	return "c-" + spdxSanitize(c.BOMRef())
+}
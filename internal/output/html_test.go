@@ -0,0 +1,55 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHTMLReport(t *testing.T) {
+	result := makeTestResult()
+	result.Components[0].Description = `<script>alert("x")</script>`
+
+	tmp := filepath.Join(t.TempDir(), "report.html")
+	if err := WriteHTML(result, tmp, HTMLOptions{ToolVersion: "test", Title: "sample"}); err != nil {
+		t.Fatalf("WriteHTML failed: %v", err)
+	}
+	data, err := os.ReadFile(tmp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(data)
+
+	for _, forbidden := range []string{"<script src", "<link ", "@import", `<script>alert`} {
+		if strings.Contains(out, forbidden) {
+			t.Errorf("report must be self-contained and escaped, found %q", forbidden)
+		}
+	}
+
+	for _, c := range result.Components {
+		id := htmlComponentID(c)
+		if !strings.Contains(out, `<tr data-panel="`+id+`">`) {
+			t.Errorf("missing table row for %s", c.Name)
+		}
+		if !strings.Contains(out, `<section class="panel" id="`+id+`" hidden>`) {
+			t.Errorf("missing detail panel for %s", c.Name)
+		}
+	}
+
+	// zlib is reachable under openssl in the collapsible tree.
+	if !strings.Contains(out, `<details open><summary><a href="#" data-panel="c-openssl-3.1.4"`) {
+		t.Error("openssl should be a collapsible tree node")
+	}
+	if !strings.Contains(out, `data-panel="c-zlib-1.2.13" class="transitive"`) {
+		t.Error("zlib should appear as a transitive tree node")
+	}
+
+	// Each panel lists the strategies that fired and those that were skipped.
+	if !strings.Contains(out, `<li class="strategy-source">conan (detected this component)</li>`) {
+		t.Error("panel should highlight the detecting strategy")
+	}
+	if !strings.Contains(out, `<li class="strategy-skipped" title="no results">vcpkg</li>`) {
+		t.Error("panel should list skipped strategies")
+	}
+}
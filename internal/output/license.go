@@ -0,0 +1,179 @@
+package output
+
+import (
+	"strings"
+)
+
+// spdxLicenseIDs is the subset of the SPDX license list that C/C++ packages
+// commonly declare, keyed by lower-case ID. Declared licenses outside this set
+// are emitted as free-text names rather than guessed at.
+var spdxLicenseIDs = canonicalIDs(
+	"0BSD", "AFL-3.0", "AGPL-3.0", "AGPL-3.0-only", "AGPL-3.0-or-later",
+	"Apache-1.1", "Apache-2.0", "APSL-2.0", "Artistic-2.0", "Beerware",
+	"BSD-1-Clause", "BSD-2-Clause", "BSD-2-Clause-Patent", "BSD-3-Clause",
+	"BSD-3-Clause-Clear", "BSD-4-Clause", "BSD-Source-Code", "BSL-1.0",
+	"bzip2-1.0.6", "CC-BY-3.0", "CC-BY-4.0", "CC-BY-SA-4.0", "CC0-1.0",
+	"CDDL-1.0", "CDDL-1.1", "CECILL-2.1", "curl", "ECL-2.0", "EPL-1.0",
+	"EPL-2.0", "EUPL-1.1", "EUPL-1.2", "FSFAP", "FTL", "GFDL-1.3",
+	"GPL-1.0-or-later", "GPL-2.0", "GPL-2.0+", "GPL-2.0-only",
+	"GPL-2.0-or-later", "GPL-3.0", "GPL-3.0+", "GPL-3.0-only",
+	"GPL-3.0-or-later", "HPND", "ICU", "IJG", "ISC", "JasPer-2.0", "JSON",
+	"LGPL-2.0", "LGPL-2.0-only", "LGPL-2.0-or-later", "LGPL-2.1", "LGPL-2.1+",
+	"LGPL-2.1-only", "LGPL-2.1-or-later", "LGPL-3.0", "LGPL-3.0+",
+	"LGPL-3.0-only", "LGPL-3.0-or-later", "libpng-2.0", "Libpng", "libtiff",
+	"LPL-1.02", "MIT", "MIT-0", "MIT-CMU", "MPL-1.1", "MPL-2.0",
+	"MPL-2.0-no-copyleft-exception", "MS-PL", "MS-RL", "NCSA", "OFL-1.1",
+	"OpenSSL", "PHP-3.01", "PostgreSQL", "PSF-2.0", "Python-2.0", "Qhull",
+	"Ruby", "SGI-B-2.0", "Sleepycat", "SMLNJ", "Unicode-3.0", "Unicode-DFS-2016",
+	"Unlicense", "UPL-1.0", "Vim", "W3C", "WTFPL", "X11", "XFree86-1.1",
+	"Zlib", "zlib-acknowledgement", "ZPL-2.1",
+)
+
+// spdxExceptionIDs lists the SPDX license exceptions accepted after WITH.
+var spdxExceptionIDs = canonicalIDs(
+	"Autoconf-exception-3.0", "Bison-exception-2.2", "Bootloader-exception",
+	"Classpath-exception-2.0", "GCC-exception-2.0", "GCC-exception-3.1",
+	"LLVM-exception", "OCaml-LGPL-linking-exception", "openvpn-openssl-exception",
+	"Qt-GPL-exception-1.0", "Qt-LGPL-exception-1.1", "Swift-exception",
+	"WxWindows-exception-3.1",
+)
+
+func canonicalIDs(ids ...string) map[string]string {
+	m := make(map[string]string, len(ids))
+	for _, id := range ids {
+		m[strings.ToLower(id)] = id
+	}
+	return m
+}
+
+// spdxExpression validates s as an SPDX license expression and returns it
+// with every license and exception ID in its canonical case. It reports false
+// for free-text license names and for IDs it does not know.
+func spdxExpression(s string) (string, bool) {
+	p := &spdxParser{tokens: spdxTokens(s)}
+	expr, ok := p.parseOr()
+	if !ok || p.pos != len(p.tokens) {
+		return "", false
+	}
+	return expr, true
+}
+
+// combinedLicenseExpression joins several declared licenses into one SPDX
+// expression. Packages that list more than one license are covered by all of
+// them, so the parts are joined with AND. It reports false if any part is not
+// a valid expression.
+func combinedLicenseExpression(licenses []string) (string, bool) {
+	var parts []string
+	for _, l := range licenses {
+		expr, ok := spdxExpression(l)
+		if !ok {
+			return "", false
+		}
+		if len(licenses) > 1 && strings.Contains(expr, " ") {
+			expr = "(" + expr + ")"
+		}
+		parts = append(parts, expr)
+	}
+	if len(parts) == 0 {
+		return "", false
+	}
+	return strings.Join(parts, " AND "), true
+}
+
+func spdxTokens(s string) []string {
+	s = strings.NewReplacer("(", " ( ", ")", " ) ").Replace(s)
+	return strings.Fields(s)
+}
+
+type spdxParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *spdxParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *spdxParser) parseOr() (string, bool) {
+	left, ok := p.parseAnd()
+	if !ok {
+		return "", false
+	}
+	for p.peek() == "OR" {
+		p.pos++
+		right, ok := p.parseAnd()
+		if !ok {
+			return "", false
+		}
+		left += " OR " + right
+	}
+	return left, true
+}
+
+func (p *spdxParser) parseAnd() (string, bool) {
+	left, ok := p.parseTerm()
+	if !ok {
+		return "", false
+	}
+	for p.peek() == "AND" {
+		p.pos++
+		right, ok := p.parseTerm()
+		if !ok {
+			return "", false
+		}
+		left += " AND " + right
+	}
+	return left, true
+}
+
+func (p *spdxParser) parseTerm() (string, bool) {
+	tok := p.peek()
+	if tok == "" {
+		return "", false
+	}
+	p.pos++
+
+	if tok == "(" {
+		inner, ok := p.parseOr()
+		if !ok || p.peek() != ")" {
+			return "", false
+		}
+		p.pos++
+		return "(" + inner + ")", true
+	}
+
+	id, ok := lookupLicenseID(tok)
+	if !ok {
+		return "", false
+	}
+	if p.peek() == "WITH" {
+		p.pos++
+		exc, ok := spdxExceptionIDs[strings.ToLower(p.peek())]
+		if !ok {
+			return "", false
+		}
+		p.pos++
+		id += " WITH " + exc
+	}
+	return id, true
+}
+
+// lookupLicenseID resolves a license ID, a trailing "+" (or-later) form, or a
+// LicenseRef-/DocumentRef- user-defined reference.
+func lookupLicenseID(tok string) (string, bool) {
+	if strings.HasPrefix(tok, "LicenseRef-") || strings.HasPrefix(tok, "DocumentRef-") {
+		return tok, len(tok) > len("LicenseRef-")
+	}
+	if id, ok := spdxLicenseIDs[strings.ToLower(tok)]; ok {
+		return id, true
+	}
+	if base, found := strings.CutSuffix(tok, "+"); found {
+		if id, ok := spdxLicenseIDs[strings.ToLower(base)]; ok {
+			return id + "+", true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,160 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/StinkyLord/cpp-sbom-builder/internal/model"
+	"github.com/StinkyLord/cpp-sbom-builder/internal/policy"
+	"github.com/StinkyLord/cpp-sbom-builder/internal/sarif"
+	"github.com/StinkyLord/cpp-sbom-builder/internal/scanner"
+)
+
+// ---- SARIF findings ----
+//
+// The SARIF output is not an SBOM but a list of findings for code-scanning
+// dashboards, each placed at the line that caused it so it shows up inline
+// in pull requests.
+
+// SARIFOptions configures the SARIF findings log.
+type SARIFOptions struct {
+	ToolVersion string
+
+	// Root is the scanned project directory. Findings in files under it are
+	// located relative to it.
+	Root string
+
+	// Policy, when set, adds its violations (license violations among them)
+	// to the detection findings.
+	Policy *policy.Report
+}
+
+// Detection finding rules.
+var (
+	ruleUndeclaredInclude = sarif.Rule{
+		ID:   "detection/undeclared-include",
+		Name: "UndeclaredThirdPartyInclude",
+		ShortDescription: &sarif.Message{
+			Text: "Third-party header included but the library is not declared in any manifest or build file",
+		},
+		DefaultConfiguration: &sarif.Configuration{Level: sarif.LevelWarning},
+	}
+	ruleUnknownVersion = sarif.Rule{
+		ID:   "detection/unknown-version",
+		Name: "UnknownVersion",
+		ShortDescription: &sarif.Message{
+			Text: "Dependency declared without a version that could be determined",
+		},
+		DefaultConfiguration: &sarif.Configuration{Level: sarif.LevelWarning},
+	}
+)
+
+// WriteSARIF writes the detection findings of the scan, and the policy
+// violations in opts.Policy, as a SARIF 2.1.0 log. If outputPath is "-", it
+// writes to stdout.
+//
+// Two kinds of findings come from the scan itself:
+//   - detection/undeclared-include, at every #include of a library that only
+//     the header scan found;
+//   - detection/unknown-version, at the line that declared a library (a
+//     find_package call, a conanfile.txt requires entry...) whose version is
+//     unknown. Undeclared includes are not reported again here.
+func WriteSARIF(result *scanner.Result, outputPath string, opts SARIFOptions) error {
+	log := sarif.New("cpp-sbom-builder", opts.ToolVersion, "https://github.com/StinkyLord/cpp-sbom-builder", opts.Root)
+	log.AddRule(ruleUndeclaredInclude)
+	log.AddRule(ruleUnknownVersion)
+
+	comps := make([]*model.Component, len(result.Components))
+	copy(comps, result.Components)
+	sort.Slice(comps, func(i, j int) bool { return comps[i].Key() < comps[j].Key() })
+
+	for _, c := range comps {
+		for _, r := range detectionFindings(c, opts.Root) {
+			if err := log.AddResult(r); err != nil {
+				return err
+			}
+		}
+	}
+	if opts.Policy != nil {
+		if err := opts.Policy.AddToSARIF(log); err != nil {
+			return err
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := log.Write(&buf); err != nil {
+		return err
+	}
+	return writeOutput(outputPath, bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+}
+
+// detectionFindings returns the findings about one component.
+func detectionFindings(c *model.Component, root string) []sarif.Result {
+	var includes, declarations []model.Evidence
+	for _, e := range c.Evidence {
+		if e.Strategy == "header-scan" {
+			includes = append(includes, e)
+		} else if e.File != "" {
+			declarations = append(declarations, e)
+		}
+	}
+
+	var out []sarif.Result
+	if len(includes) > 0 && len(declarations) == 0 {
+		for _, e := range includes {
+			out = append(out, finding(ruleUndeclaredInclude, c, root, e,
+				fmt.Sprintf("%s is included here but no manifest or build file declares it", c.Name)))
+		}
+		return out
+	}
+
+	if (c.Version == "" || c.Version == "unknown") && len(declarations) > 0 {
+		e := declarationSite(declarations)
+		out = append(out, finding(ruleUnknownVersion, c, root, e,
+			fmt.Sprintf("the version of %s could not be determined (%s)", c.Name, e.Strategy)))
+	}
+	return out
+}
+
+// manifestStrategies read the files where a dependency is declared, as
+// opposed to build outputs that merely show it in use.
+var manifestStrategies = map[string]bool{
+	"conan": true, "conan-graph": true, "vcpkg": true, "cmake": true, "meson": true,
+}
+
+// declarationSite picks where to report a finding about a declared library:
+// a manifest line if there is one, else any line, else the first file.
+func declarationSite(evidence []model.Evidence) model.Evidence {
+	best, rank := evidence[0], 0
+	for _, e := range evidence {
+		r := 0
+		if e.Line > 0 {
+			r = 1
+			if manifestStrategies[e.Strategy] {
+				r = 2
+			}
+		}
+		if r > rank {
+			best, rank = e, r
+		}
+	}
+	return best
+}
+
+func finding(rule sarif.Rule, c *model.Component, root string, e model.Evidence, message string) sarif.Result {
+	return sarif.Result{
+		RuleID:  rule.ID,
+		Level:   rule.DefaultConfiguration.Level,
+		Message: sarif.Message{Text: message},
+		Locations: []sarif.Location{{
+			PhysicalLocation: sarif.FileLocation(root, e.File, e.Line),
+			LogicalLocations: []sarif.LogicalLocation{{Name: c.Name, FullyQualifiedName: c.PURL, Kind: "module"}},
+		}},
+		// Fingerprint by component and matched text rather than line, so a
+		// finding keeps its identity when unrelated lines move.
+		PartialFingerprints: map[string]string{
+			"component/v1": rule.ID + ":" + c.NameKey() + ":" + e.Text,
+		},
+	}
+}
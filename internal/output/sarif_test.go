@@ -0,0 +1,104 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/StinkyLord/cpp-sbom-builder/internal/model"
+	"github.com/StinkyLord/cpp-sbom-builder/internal/policy"
+	"github.com/StinkyLord/cpp-sbom-builder/internal/scanner"
+)
+
+// TestWriteSARIF verifies that findings are placed at the include line, the
+// find_package line and the conanfile.txt requires line, and that policy
+// violations join them.
+func TestWriteSARIF(t *testing.T) {
+	result := &scanner.Result{Components: []*model.Component{
+		{
+			Name: "stb", Version: "unknown",
+			Evidence: []model.Evidence{
+				{Strategy: "header-scan", File: "/src/image.cpp", Line: 4, Text: "#include <stb_image.h>"},
+				{Strategy: "header-scan", File: "/src/font.cpp", Line: 7, Text: "#include <stb_truetype.h>"},
+			},
+		},
+		{
+			Name: "spdlog", Version: "unknown",
+			Evidence: []model.Evidence{
+				{Strategy: "header-scan", File: "/src/main.cpp", Line: 2, Text: "#include <spdlog/spdlog.h>"},
+				{Strategy: "compile_commands.json", File: "/src/build/compile_commands.json", Line: 9},
+				{Strategy: "cmake", File: "/src/CMakeLists.txt", Line: 12, Text: "find_package(spdlog REQUIRED)"},
+			},
+		},
+		{
+			Name: "readline", Version: "8.2", IsDirect: true, Licenses: []string{"GPL-3.0-only"},
+			Evidence: []model.Evidence{
+				{Strategy: "header-scan", File: "/src/repl.cpp", Line: 1, Text: "#include <readline/readline.h>"},
+				{Strategy: "conan", File: "/src/conanfile.txt", Line: 3, Text: "readline/8.2"},
+			},
+		},
+	}}
+	p, err := policy.Parse([]byte(`{"rules": [{"id": "no-gpl3", "licenses": ["GPL-3.0"]}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "findings.sarif")
+	opts := SARIFOptions{ToolVersion: "1.0.0-test", Root: "/src", Policy: policy.Evaluate(p, result, "/src")}
+	if err := WriteSARIF(result, path, opts); err != nil {
+		t.Fatalf("WriteSARIF failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var log struct {
+		Version string
+		Runs    []struct {
+			Results []struct {
+				RuleID    string
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI       string
+							URIBaseID string
+						}
+						Region struct{ StartLine int }
+					}
+				}
+			}
+		}
+	}
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatalf("invalid SARIF: %v", err)
+	}
+	if log.Version != "2.1.0" {
+		t.Errorf("version = %q, want 2.1.0", log.Version)
+	}
+
+	var got []string
+	for _, r := range log.Runs[0].Results {
+		loc := r.Locations[0].PhysicalLocation
+		if loc.ArtifactLocation.URIBaseID != "%SRCROOT%" {
+			t.Errorf("%s: uriBaseId = %q, want %%SRCROOT%%", r.RuleID, loc.ArtifactLocation.URIBaseID)
+		}
+		got = append(got, fmt.Sprintf("%s %s:%d", r.RuleID, loc.ArtifactLocation.URI, loc.Region.StartLine))
+	}
+	want := []string{
+		"detection/undeclared-include font.cpp:7",
+		"detection/undeclared-include image.cpp:4",
+		"detection/unknown-version CMakeLists.txt:12",
+		"no-gpl3 conanfile.txt:3",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("results = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("result %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
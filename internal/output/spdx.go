@@ -0,0 +1,266 @@
+package output
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+
+	"github.com/StinkyLord/cpp-sbom-builder/internal/model"
+	"github.com/StinkyLord/cpp-sbom-builder/internal/scanner"
+)
+
+// ---- SPDX 2.3 JSON schema types ----
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	DocumentDescribes []string           `json:"documentDescribes,omitempty"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	SPDXID           string            `json:"SPDXID"`
+	Name             string            `json:"name"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	LicenseConcluded string            `json:"licenseConcluded"`
+	LicenseDeclared  string            `json:"licenseDeclared"`
+	CopyrightText    string            `json:"copyrightText"`
+	Homepage         string            `json:"homepage,omitempty"`
+	Description      string            `json:"description,omitempty"`
+	Comment          string            `json:"comment,omitempty"`
+	PrimaryPurpose   string            `json:"primaryPackagePurpose,omitempty"`
+	ExternalRefs     []spdxExternalRef `json:"externalRefs,omitempty"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+const (
+	spdxDocumentID  = "SPDXRef-DOCUMENT"
+	spdxNoAssertion = "NOASSERTION"
+)
+
+// SPDXOptions controls how WriteSPDX renders a scan result.
+type SPDXOptions struct {
+	// ToolVersion is recorded in creationInfo.creators.
+	ToolVersion string
+
+	// DocumentName names the SPDX document, typically after the scanned
+	// project directory.
+	DocumentName string
+
+	// Reproducible derives the document namespace from the document content
+	// and timestamps it with SOURCE_DATE_EPOCH or the Unix epoch.
+	Reproducible bool
+}
+
+// WriteSPDX serialises the scan result as an SPDX 2.3 JSON document and writes
+// it to the given output path. If outputPath is "-", it writes to stdout.
+func WriteSPDX(result *scanner.Result, outputPath string, opts SPDXOptions) error {
+	doc, err := buildSPDX(result, opts)
+	if err != nil {
+		return err
+	}
+	return writeJSON(outputPath, doc)
+}
+
+func buildSPDX(result *scanner.Result, opts SPDXOptions) (*spdxDocument, error) {
+	name := opts.DocumentName
+	if name == "" {
+		name = "cpp-sbom-builder-scan"
+	}
+
+	comps := make([]*model.Component, len(result.Components))
+	copy(comps, result.Components)
+	sort.Slice(comps, func(i, j int) bool {
+		return comps[i].BOMRef() < comps[j].BOMRef()
+	})
+
+	tree := result.DependencyTree
+	if tree == nil {
+		tree = model.BuildDependencyTree(comps)
+	}
+
+	doc := &spdxDocument{
+		SPDXVersion: "SPDX-2.3",
+		DataLicense: "CC0-1.0",
+		SPDXID:      spdxDocumentID,
+		Name:        name,
+		CreationInfo: spdxCreationInfo{
+			Creators: []string{"Tool: cpp-sbom-builder-" + opts.ToolVersion},
+		},
+		Packages:      make([]spdxPackage, 0, len(comps)+1),
+		Relationships: []spdxRelationship{},
+	}
+
+	describe := func(id string) {
+		doc.DocumentDescribes = append(doc.DocumentDescribes, id)
+		doc.Relationships = append(doc.Relationships, spdxRelationship{
+			SPDXElementID:      spdxDocumentID,
+			RelationshipType:   "DESCRIBES",
+			RelatedSPDXElement: id,
+		})
+	}
+
+	// When the project itself is known, the document describes it and the
+	// project depends on the direct dependencies. Otherwise the direct
+	// dependencies are what the document describes. Transitive ones are only
+	// reachable through DEPENDS_ON edges either way.
+	if p := result.Project; p != nil {
+		id := spdxPackageID(p)
+		pkg := componentToSPDX(p, id)
+		pkg.PrimaryPurpose = "APPLICATION"
+		pkg.Comment = "Scanned project, identified from " + p.DetectionSource
+		doc.Packages = append(doc.Packages, pkg)
+		describe(id)
+
+		var children []string
+		for _, name := range p.Dependencies {
+			if child := tree.Lookup(name); child != nil {
+				children = append(children, spdxPackageID(child))
+			}
+		}
+		sort.Strings(children)
+		for _, childID := range children {
+			doc.Relationships = append(doc.Relationships, spdxRelationship{
+				SPDXElementID:      id,
+				RelationshipType:   "DEPENDS_ON",
+				RelatedSPDXElement: childID,
+			})
+		}
+	}
+
+	for _, c := range comps {
+		id := spdxPackageID(c)
+		doc.Packages = append(doc.Packages, componentToSPDX(c, id))
+		if c.IsDirect && result.Project == nil {
+			describe(id)
+		}
+
+		var children []string
+		seen := map[string]bool{}
+		for _, childName := range c.Dependencies {
+			child := tree.Lookup(childName)
+			if child == nil || child == c {
+				continue
+			}
+			childID := spdxPackageID(child)
+			if !seen[childID] {
+				seen[childID] = true
+				children = append(children, childID)
+			}
+		}
+		sort.Strings(children)
+		for _, childID := range children {
+			doc.Relationships = append(doc.Relationships, spdxRelationship{
+				SPDXElementID:      id,
+				RelationshipType:   "DEPENDS_ON",
+				RelatedSPDXElement: childID,
+			})
+		}
+	}
+
+	// Namespace and creation time are filled in last so a content-derived
+	// namespace only depends on the packages and relationships.
+	uuid := newRandomUUID()
+	if opts.Reproducible {
+		var err error
+		if uuid, err = contentUUID(doc); err != nil {
+			return nil, err
+		}
+	}
+	created, err := creationTime(opts.Reproducible)
+	if err != nil {
+		return nil, err
+	}
+	doc.DocumentNamespace = spdxNamespace(name, uuid)
+	doc.CreationInfo.Created = created
+
+	return doc, nil
+}
+
+// componentToSPDX maps a model.Component to an SPDX package. We never inspect
+// package files, so licenseConcluded and copyrightText carry NOASSERTION as
+// SPDX requires. licenseDeclared is the manifest's license when it is a valid
+// SPDX expression and NOASSERTION otherwise.
+func componentToSPDX(c *model.Component, id string) spdxPackage {
+	pkg := spdxPackage{
+		SPDXID:           id,
+		Name:             c.Name,
+		DownloadLocation: spdxNoAssertion,
+		FilesAnalyzed:    false,
+		LicenseConcluded: spdxNoAssertion,
+		LicenseDeclared:  spdxNoAssertion,
+		CopyrightText:    spdxNoAssertion,
+		Homepage:         c.Homepage,
+		Description:      c.Description,
+	}
+	if expr, ok := combinedLicenseExpression(c.Licenses); ok {
+		pkg.LicenseDeclared = expr
+	}
+	if c.Version != "" && c.Version != "unknown" {
+		pkg.VersionInfo = c.Version
+	}
+	if c.DetectionSource != "" {
+		pkg.Comment = "Detected by " + c.DetectionSource + " (" + c.DependencyType() + " dependency)"
+	}
+	if c.PURL != "" {
+		pkg.ExternalRefs = append(pkg.ExternalRefs, spdxExternalRef{
+			ReferenceCategory: "PACKAGE-MANAGER",
+			ReferenceType:     "purl",
+			ReferenceLocator:  c.PURL,
+		})
+	}
+	return pkg
+}
+
+// spdxPackageID derives an SPDX identifier from the component's bom-ref.
+// SPDX IDs may only contain letters, digits, '.' and '-', so sanitizing alone
+// folds refs such as "foo@1-2" and "foo-1@2", or "libc++" and "libc--",
+// together. A short hash of the exact bom-ref keeps them apart.
+func spdxPackageID(c *model.Component) string {
+	ref := c.BOMRef()
+	sum := sha256.Sum256([]byte(ref))
+	return "SPDXRef-Package-" + spdxSanitize(ref) + "-" + hex.EncodeToString(sum[:4])
+}
+
+func spdxSanitize(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('-')
+		}
+	}
+	return b.String()
+}
+
+// spdxNamespace returns a unique document namespace URI. SPDX only requires
+// it to be unique per document; it does not need to resolve.
+func spdxNamespace(name, uuid string) string {
+	return "https://spdx.org/spdxdocs/" + spdxSanitize(name) + "-" + uuid
+}
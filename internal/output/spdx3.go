@@ -0,0 +1,450 @@
+package output
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/StinkyLord/cpp-sbom-builder/internal/model"
+	"github.com/StinkyLord/cpp-sbom-builder/internal/scanner"
+)
+
+// ---- SPDX 3.0 JSON-LD types ----
+//
+// The document is a flat @graph of elements that reference each other by
+// spdxId. Property names follow the 3.0.1 JSON-LD context: Core properties are
+// unprefixed, Software and Build profile properties carry "software_" and
+// "build_" prefixes.
+
+const (
+	spdx3Context      = "https://spdx.org/rdf/3.0.1/spdx-context.jsonld"
+	spdx3SpecVersion  = "3.0.1"
+	spdx3CreationInfo = "_:creationinfo"
+
+	// spdx3BuildTypeBase prefixes the build_buildType URIs we emit. SPDX only
+	// requires a URI that identifies how to interpret the build parameters.
+	spdx3BuildTypeBase = "https://github.com/StinkyLord/IBuildHW/spdx/build-type/"
+)
+
+type spdx3Document struct {
+	Context string `json:"@context"`
+	Graph   []any  `json:"@graph"`
+}
+
+type spdx3CreationInfoElement struct {
+	Type         string   `json:"type"`
+	ID           string   `json:"@id"`
+	SpecVersion  string   `json:"specVersion"`
+	Created      string   `json:"created"`
+	CreatedBy    []string `json:"createdBy"`
+	CreatedUsing []string `json:"createdUsing,omitempty"`
+}
+
+type spdx3Agent struct {
+	Type         string `json:"type"`
+	SPDXID       string `json:"spdxId"`
+	CreationInfo string `json:"creationInfo"`
+	Name         string `json:"name"`
+}
+
+type spdx3SpdxDocument struct {
+	Type               string   `json:"type"`
+	SPDXID             string   `json:"spdxId"`
+	CreationInfo       string   `json:"creationInfo"`
+	Name               string   `json:"name"`
+	ProfileConformance []string `json:"profileConformance"`
+	RootElement        []string `json:"rootElement"`
+	Element            []string `json:"element"`
+}
+
+type spdx3Sbom struct {
+	Type         string   `json:"type"`
+	SPDXID       string   `json:"spdxId"`
+	CreationInfo string   `json:"creationInfo"`
+	SbomType     []string `json:"software_sbomType,omitempty"`
+	RootElement  []string `json:"rootElement,omitempty"`
+	Element      []string `json:"element"`
+}
+
+type spdx3Package struct {
+	Type               string                    `json:"type"`
+	SPDXID             string                    `json:"spdxId"`
+	CreationInfo       string                    `json:"creationInfo"`
+	Name               string                    `json:"name"`
+	Description        string                    `json:"description,omitempty"`
+	Comment            string                    `json:"comment,omitempty"`
+	ExternalIdentifier []spdx3ExternalIdentifier `json:"externalIdentifier,omitempty"`
+	PackageVersion     string                    `json:"software_packageVersion,omitempty"`
+	PackageURL         string                    `json:"software_packageUrl,omitempty"`
+	PrimaryPurpose     string                    `json:"software_primaryPurpose,omitempty"`
+}
+
+type spdx3ExternalIdentifier struct {
+	Type                   string `json:"type"`
+	ExternalIdentifierType string `json:"externalIdentifierType"`
+	Identifier             string `json:"identifier"`
+}
+
+type spdx3File struct {
+	Type           string `json:"type"`
+	SPDXID         string `json:"spdxId"`
+	CreationInfo   string `json:"creationInfo"`
+	Name           string `json:"name"`
+	PrimaryPurpose string `json:"software_primaryPurpose,omitempty"`
+}
+
+type spdx3Build struct {
+	Type                   string                `json:"type"`
+	SPDXID                 string                `json:"spdxId"`
+	CreationInfo           string                `json:"creationInfo"`
+	BuildType              string                `json:"build_buildType"`
+	ConfigSourceEntrypoint []string              `json:"build_configSourceEntrypoint,omitempty"`
+	ConfigSourceURI        []string              `json:"build_configSourceUri,omitempty"`
+	Parameter              []spdx3DictionaryItem `json:"build_parameter,omitempty"`
+	Environment            []spdx3DictionaryItem `json:"build_environment,omitempty"`
+}
+
+type spdx3DictionaryItem struct {
+	Type  string `json:"type"`
+	Key   string `json:"key"`
+	Value string `json:"value,omitempty"`
+}
+
+type spdx3Relationship struct {
+	Type             string   `json:"type"`
+	SPDXID           string   `json:"spdxId"`
+	CreationInfo     string   `json:"creationInfo"`
+	From             string   `json:"from"`
+	RelationshipType string   `json:"relationshipType"`
+	To               []string `json:"to"`
+}
+
+// SPDX3Options controls how WriteSPDX3 renders a scan result.
+type SPDX3Options struct {
+	// ToolVersion is recorded on the Tool element that created the document.
+	ToolVersion string
+
+	// DocumentName names the SpdxDocument, typically after the scanned
+	// project directory.
+	DocumentName string
+
+	// Reproducible derives the element ID namespace from the document content
+	// and timestamps it with SOURCE_DATE_EPOCH or the Unix epoch.
+	Reproducible bool
+}
+
+// WriteSPDX3 serialises the scan result as an SPDX 3.0 JSON-LD document with
+// the Software and Build profiles and writes it to the given output path. If
+// outputPath is "-", it writes to stdout.
+//
+// Packages come from result.Components; Build elements come from
+// result.BuildInvocations, so the scanner must run with CollectBuildInfo set
+// for the Build profile to be populated.
+func WriteSPDX3(result *scanner.Result, outputPath string, opts SPDX3Options) error {
+	doc, err := buildSPDX3(result, opts)
+	if err != nil {
+		return err
+	}
+	return writeJSON(outputPath, doc)
+}
+
+// spdx3Builder accumulates graph elements and hands out document-scoped IDs.
+type spdx3Builder struct {
+	base     string
+	graph    []any
+	elements []string
+	relSeq   int
+}
+
+func (b *spdx3Builder) id(local string) string {
+	return b.base + "#" + local
+}
+
+func (b *spdx3Builder) add(id string, element any) {
+	b.graph = append(b.graph, element)
+	b.elements = append(b.elements, id)
+}
+
+func (b *spdx3Builder) relate(from, relType string, to []string) {
+	if len(to) == 0 {
+		return
+	}
+	b.relSeq++
+	id := b.id(fmt.Sprintf("SPDXRef-Relationship-%d", b.relSeq))
+	b.add(id, spdx3Relationship{
+		Type:             "Relationship",
+		SPDXID:           id,
+		CreationInfo:     spdx3CreationInfo,
+		From:             from,
+		RelationshipType: relType,
+		To:               to,
+	})
+}
+
+func buildSPDX3(result *scanner.Result, opts SPDX3Options) (*spdx3Document, error) {
+	if opts.DocumentName == "" {
+		opts.DocumentName = "cpp-sbom-builder-scan"
+	}
+
+	created, err := creationTime(opts.Reproducible)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every element ID embeds the namespace, so a content-derived namespace
+	// is computed from a first rendering without namespace or timestamp.
+	uuid := newRandomUUID()
+	if opts.Reproducible {
+		if uuid, err = contentUUID(buildSPDX3Graph(result, opts, "", "")); err != nil {
+			return nil, err
+		}
+	}
+
+	return buildSPDX3Graph(result, opts, spdxNamespace(opts.DocumentName, uuid), created), nil
+}
+
+// buildSPDX3Graph renders the document with element IDs under base.
+func buildSPDX3Graph(result *scanner.Result, opts SPDX3Options, base, created string) *spdx3Document {
+	name := opts.DocumentName
+	b := &spdx3Builder{base: base}
+
+	agentID := b.id("SPDXRef-Agent-cpp-sbom-builder")
+	toolID := b.id("SPDXRef-Tool-cpp-sbom-builder")
+	creation := spdx3CreationInfoElement{
+		Type:         "CreationInfo",
+		ID:           spdx3CreationInfo,
+		SpecVersion:  spdx3SpecVersion,
+		Created:      created,
+		CreatedBy:    []string{agentID},
+		CreatedUsing: []string{toolID},
+	}
+	agent := spdx3Agent{
+		Type:         "SoftwareAgent",
+		SPDXID:       agentID,
+		CreationInfo: spdx3CreationInfo,
+		Name:         "cpp-sbom-builder",
+	}
+	tool := spdx3Agent{
+		Type:         "Tool",
+		SPDXID:       toolID,
+		CreationInfo: spdx3CreationInfo,
+		Name:         "cpp-sbom-builder-" + opts.ToolVersion,
+	}
+
+	// ---- Software profile: one package per component ----
+
+	comps := make([]*model.Component, len(result.Components))
+	copy(comps, result.Components)
+	sort.Slice(comps, func(i, j int) bool {
+		return comps[i].BOMRef() < comps[j].BOMRef()
+	})
+
+	tree := result.DependencyTree
+	if tree == nil {
+		tree = model.BuildDependencyTree(comps)
+	}
+
+	// The scanned project, when known, is the single root element and
+	// depends on the direct dependencies; otherwise those are the roots.
+	var projectID string
+	var roots []string
+	if p := result.Project; p != nil {
+		projectID = b.id(spdxPackageID(p))
+		pkg := componentToSPDX3(p, projectID)
+		pkg.PrimaryPurpose = "application"
+		pkg.Comment = "Scanned project, identified from " + p.DetectionSource
+		b.add(projectID, pkg)
+		roots = append(roots, projectID)
+	}
+
+	pkgIDs := map[*model.Component]string{}
+	var directs []string
+	for _, c := range comps {
+		id := b.id(spdxPackageID(c))
+		pkgIDs[c] = id
+		b.add(id, componentToSPDX3(c, id))
+		if c.IsDirect {
+			directs = append(directs, id)
+		}
+	}
+	if projectID != "" {
+		b.relate(projectID, "dependsOn", directs)
+	} else {
+		roots = directs
+	}
+
+	for _, c := range comps {
+		var children []string
+		seen := map[string]bool{}
+		for _, childName := range c.Dependencies {
+			child := tree.Lookup(childName)
+			if child == nil || child == c || seen[pkgIDs[child]] {
+				continue
+			}
+			seen[pkgIDs[child]] = true
+			children = append(children, pkgIDs[child])
+		}
+		sort.Strings(children)
+		b.relate(pkgIDs[c], "dependsOn", children)
+	}
+
+	// ---- Build profile: one Build per recorded compiler/linker command ----
+
+	sbomTypes := []string{"analyzed"}
+	if len(result.BuildInvocations) > 0 {
+		sbomTypes = []string{"build"}
+	}
+	for i := range result.BuildInvocations {
+		inv := &result.BuildInvocations[i]
+		buildID := b.id(fmt.Sprintf("SPDXRef-Build-%s-%d", inv.Kind, i+1))
+		b.add(buildID, invocationToSPDX3(inv, buildID))
+
+		b.relate(buildID, "hasInput", invocationInputs(inv, comps, pkgIDs))
+
+		if inv.Kind == "link" && inv.Output != "" {
+			fileID := b.id("SPDXRef-File-" + spdxSanitize(filepath.ToSlash(inv.Output)))
+			b.add(fileID, spdx3File{
+				Type:           "software_File",
+				SPDXID:         fileID,
+				CreationInfo:   spdx3CreationInfo,
+				Name:           filepath.ToSlash(inv.Output),
+				PrimaryPurpose: outputPurpose(inv.Output),
+			})
+			b.relate(buildID, "hasOutput", []string{fileID})
+		}
+	}
+
+	sbomID := b.id("SPDXRef-Sbom")
+	sbom := spdx3Sbom{
+		Type:         "software_Sbom",
+		SPDXID:       sbomID,
+		CreationInfo: spdx3CreationInfo,
+		SbomType:     sbomTypes,
+		RootElement:  roots,
+		Element:      b.elements,
+	}
+	docID := b.id("SPDXRef-DOCUMENT")
+	doc := spdx3SpdxDocument{
+		Type:               "SpdxDocument",
+		SPDXID:             docID,
+		CreationInfo:       spdx3CreationInfo,
+		Name:               name,
+		ProfileConformance: []string{"core", "software", "build"},
+		RootElement:        []string{sbomID},
+		Element:            append([]string{agentID, toolID, sbomID}, b.elements...),
+	}
+
+	graph := []any{creation, agent, tool, doc, sbom}
+	return &spdx3Document{
+		Context: spdx3Context,
+		Graph:   append(graph, b.graph...),
+	}
+}
+
+func componentToSPDX3(c *model.Component, id string) spdx3Package {
+	pkg := spdx3Package{
+		Type:           "software_Package",
+		SPDXID:         id,
+		CreationInfo:   spdx3CreationInfo,
+		Name:           c.Name,
+		Description:    c.Description,
+		PackageURL:     c.PURL,
+		PrimaryPurpose: "library",
+	}
+	if c.Version != "" && c.Version != "unknown" {
+		pkg.PackageVersion = c.Version
+	}
+	if c.DetectionSource != "" {
+		pkg.Comment = "Detected by " + c.DetectionSource + " (" + c.DependencyType() + " dependency)"
+	}
+	if c.PURL != "" {
+		pkg.ExternalIdentifier = []spdx3ExternalIdentifier{{
+			Type:                   "ExternalIdentifier",
+			ExternalIdentifierType: "packageUrl",
+			Identifier:             c.PURL,
+		}}
+	}
+	return pkg
+}
+
+func invocationToSPDX3(inv *model.BuildInvocation, id string) spdx3Build {
+	build := spdx3Build{
+		Type:         "build_Build",
+		SPDXID:       id,
+		CreationInfo: spdx3CreationInfo,
+		BuildType:    spdx3BuildTypeBase + inv.Kind,
+		Parameter: []spdx3DictionaryItem{
+			{Type: "DictionaryEntry", Key: "tool", Value: inv.Tool()},
+			{Type: "DictionaryEntry", Key: "command", Value: strings.Join(inv.Arguments, " ")},
+		},
+	}
+	if inv.Output != "" {
+		build.Parameter = append(build.Parameter, spdx3DictionaryItem{Type: "DictionaryEntry", Key: "output", Value: inv.Output})
+	}
+	if inv.Directory != "" {
+		build.Environment = []spdx3DictionaryItem{{Type: "DictionaryEntry", Key: "workingDirectory", Value: inv.Directory}}
+	}
+	if inv.Input != "" {
+		build.ConfigSourceEntrypoint = []string{inv.Input}
+	}
+	if inv.SourceFile != "" {
+		u := url.URL{Scheme: "file", Path: filepath.ToSlash(inv.SourceFile)}
+		build.ConfigSourceURI = []string{u.String()}
+	}
+	return build
+}
+
+// invocationInputs returns the package IDs a command line consumed: packages
+// whose detected include paths appear as -I arguments or whose link libraries
+// appear as -l flags or library paths.
+func invocationInputs(inv *model.BuildInvocation, comps []*model.Component, pkgIDs map[*model.Component]string) []string {
+	args := map[string]bool{}
+	for _, arg := range inv.Arguments {
+		arg = filepath.ToSlash(arg)
+		args[arg] = true
+		for _, prefix := range []string{"-I", "/I", "-isystem", "-l"} {
+			if strings.HasPrefix(arg, prefix) && len(arg) > len(prefix) {
+				args[arg[len(prefix):]] = true
+			}
+		}
+		args[filepath.Base(arg)] = true
+	}
+
+	var inputs []string
+	for _, c := range comps {
+		matched := false
+		for _, p := range c.IncludePaths {
+			if args[filepath.ToSlash(p)] {
+				matched = true
+				break
+			}
+		}
+		for _, l := range c.LinkLibraries {
+			if matched {
+				break
+			}
+			if args[l] {
+				matched = true
+			}
+		}
+		if matched {
+			inputs = append(inputs, pkgIDs[c])
+		}
+	}
+	return inputs
+}
+
+// outputPurpose classifies a link output by its extension.
+func outputPurpose(path string) string {
+	lower := strings.ToLower(path)
+	switch {
+	case strings.HasSuffix(lower, ".a"), strings.HasSuffix(lower, ".lib"),
+		strings.HasSuffix(lower, ".dll"), strings.HasSuffix(lower, ".dylib"),
+		strings.Contains(lower, ".so"):
+		return "library"
+	default:
+		return "executable"
+	}
+}
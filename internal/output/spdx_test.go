@@ -0,0 +1,253 @@
+package output
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/StinkyLord/cpp-sbom-builder/internal/model"
+	"github.com/StinkyLord/cpp-sbom-builder/internal/scanner"
+)
+
+// TestSPDXDocument verifies the SPDX 2.3 document-level fields.
+func TestSPDXDocument(t *testing.T) {
+	tmp := filepath.Join(t.TempDir(), "sbom.spdx.json")
+	if err := WriteSPDX(makeTestResult(), tmp, SPDXOptions{ToolVersion: "1.0.0-test", DocumentName: "my-project"}); err != nil {
+		t.Fatalf("WriteSPDX failed: %v", err)
+	}
+
+	data, err := os.ReadFile(tmp)
+	if err != nil {
+		t.Fatalf("cannot read output file: %v", err)
+	}
+
+	var doc spdxDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+
+	if doc.SPDXVersion != "SPDX-2.3" {
+		t.Errorf("spdxVersion = %q, want SPDX-2.3", doc.SPDXVersion)
+	}
+	if doc.DataLicense != "CC0-1.0" {
+		t.Errorf("dataLicense = %q, want CC0-1.0", doc.DataLicense)
+	}
+	if doc.SPDXID != "SPDXRef-DOCUMENT" {
+		t.Errorf("SPDXID = %q, want SPDXRef-DOCUMENT", doc.SPDXID)
+	}
+	if !strings.HasPrefix(doc.DocumentNamespace, "https://spdx.org/spdxdocs/my-project-") {
+		t.Errorf("documentNamespace = %q, want prefix https://spdx.org/spdxdocs/my-project-", doc.DocumentNamespace)
+	}
+	if doc.CreationInfo.Created == "" {
+		t.Error("creationInfo.created is empty")
+	}
+	if len(doc.CreationInfo.Creators) != 1 || doc.CreationInfo.Creators[0] != "Tool: cpp-sbom-builder-1.0.0-test" {
+		t.Errorf("creationInfo.creators = %v", doc.CreationInfo.Creators)
+	}
+}
+
+// TestSPDXPackages verifies that every component becomes a package with a
+// purl external reference.
+func TestSPDXPackages(t *testing.T) {
+	doc, err := buildSPDX(makeTestResult(), SPDXOptions{ToolVersion: "test"})
+	if err != nil {
+		t.Fatalf("buildSPDX failed: %v", err)
+	}
+
+	if len(doc.Packages) != 4 {
+		t.Fatalf("packages count = %d, want 4", len(doc.Packages))
+	}
+
+	for _, pkg := range doc.Packages {
+		if !strings.HasPrefix(pkg.SPDXID, "SPDXRef-") {
+			t.Errorf("package %q SPDXID = %q, want SPDXRef- prefix", pkg.Name, pkg.SPDXID)
+		}
+		if strings.ContainsAny(pkg.SPDXID, "@/_") {
+			t.Errorf("package %q SPDXID %q contains invalid characters", pkg.Name, pkg.SPDXID)
+		}
+		if len(pkg.ExternalRefs) != 1 || pkg.ExternalRefs[0].ReferenceType != "purl" {
+			t.Errorf("package %q externalRefs = %+v, want one purl", pkg.Name, pkg.ExternalRefs)
+		}
+		if pkg.Name == "nlohmann-json" && pkg.VersionInfo != "" {
+			t.Errorf("nlohmann-json versionInfo = %q, want empty for unknown version", pkg.VersionInfo)
+		}
+	}
+}
+
+// TestSPDXRelationships verifies DESCRIBES for direct and DEPENDS_ON for edges.
+func TestSPDXRelationships(t *testing.T) {
+	doc, err := buildSPDX(makeTestResult(), SPDXOptions{ToolVersion: "test"})
+	if err != nil {
+		t.Fatalf("buildSPDX failed: %v", err)
+	}
+
+	idByName := map[string]string{}
+	for _, pkg := range doc.Packages {
+		idByName[pkg.Name] = pkg.SPDXID
+	}
+
+	has := func(from, relType, to string) bool {
+		for _, r := range doc.Relationships {
+			if r.SPDXElementID == from && r.RelationshipType == relType && r.RelatedSPDXElement == to {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, direct := range []string{"boost", "openssl", "nlohmann-json"} {
+		if !has("SPDXRef-DOCUMENT", "DESCRIBES", idByName[direct]) {
+			t.Errorf("missing DOCUMENT DESCRIBES %s", direct)
+		}
+	}
+	if has("SPDXRef-DOCUMENT", "DESCRIBES", idByName["zlib"]) {
+		t.Error("transitive zlib must not be DESCRIBED by the document")
+	}
+	if !has(idByName["openssl"], "DEPENDS_ON", idByName["zlib"]) {
+		t.Error("missing openssl DEPENDS_ON zlib")
+	}
+	if len(doc.DocumentDescribes) != 3 {
+		t.Errorf("documentDescribes count = %d, want 3", len(doc.DocumentDescribes))
+	}
+}
+
+// TestSPDXProject verifies that a known project is the package the document
+// describes, with the direct dependencies hanging off it.
+func TestSPDXProject(t *testing.T) {
+	result := makeTestResult()
+	result.Project = &model.Component{
+		Name:            "MyApp",
+		Version:         "2.0.0",
+		DetectionSource: "cmake",
+		Dependencies:    []string{"boost", "openssl", "nlohmann-json"},
+	}
+	doc, err := buildSPDX(result, SPDXOptions{ToolVersion: "test"})
+	if err != nil {
+		t.Fatalf("buildSPDX failed: %v", err)
+	}
+
+	projectID := spdxPackageID(result.Project)
+	if !strings.HasPrefix(projectID, "SPDXRef-Package-myapp-2.0.0-") {
+		t.Errorf("project SPDXID = %q, want SPDXRef-Package-myapp-2.0.0-<hash>", projectID)
+	}
+	if len(doc.DocumentDescribes) != 1 || doc.DocumentDescribes[0] != projectID {
+		t.Fatalf("documentDescribes = %v, want the project only", doc.DocumentDescribes)
+	}
+	dependsOn := 0
+	for _, r := range doc.Relationships {
+		if r.SPDXElementID == projectID && r.RelationshipType == "DEPENDS_ON" {
+			dependsOn++
+		}
+	}
+	if dependsOn != 3 {
+		t.Errorf("project DEPENDS_ON count = %d, want 3", dependsOn)
+	}
+}
+
+// TestSPDXPackageIDCollisions verifies that components whose bom-refs only
+// differ in characters SPDX IDs cannot hold still get distinct IDs.
+func TestSPDXPackageIDCollisions(t *testing.T) {
+	pairs := [][2]*model.Component{
+		{{Name: "foo", Version: "1-2"}, {Name: "foo-1", Version: "2"}},
+		{{Name: "libc++", Version: "17"}, {Name: "libc--", Version: "17"}},
+	}
+	for _, p := range pairs {
+		a, b := spdxPackageID(p[0]), spdxPackageID(p[1])
+		if a == b {
+			t.Errorf("%s and %s share SPDXID %q", p[0].BOMRef(), p[1].BOMRef(), a)
+		}
+	}
+
+	result := &scanner.Result{Components: pairs[1][:]}
+	doc, err := buildSPDX(result, SPDXOptions{ToolVersion: "test"})
+	if err != nil {
+		t.Fatalf("buildSPDX failed: %v", err)
+	}
+	if len(doc.Packages) != 2 || doc.Packages[0].SPDXID == doc.Packages[1].SPDXID {
+		t.Errorf("packages = %+v, want two with distinct SPDXIDs", doc.Packages)
+	}
+}
+
+// TestSPDX3Graph verifies the SPDX 3.0 JSON-LD graph: packages from the
+// Software profile and Build elements from recorded build invocations.
+func TestSPDX3Graph(t *testing.T) {
+	result := makeTestResult()
+	result.BuildInvocations = []model.BuildInvocation{
+		{
+			Kind:       "compile",
+			Arguments:  []string{"/usr/bin/c++", "-I/usr/include/openssl", "-c", "main.cpp", "-o", "main.o"},
+			Directory:  "/src/build",
+			Input:      "main.cpp",
+			Output:     "main.o",
+			SourceFile: "/src/build/compile_commands.json",
+		},
+		{
+			Kind:       "link",
+			Arguments:  []string{"/usr/bin/c++", "main.o", "-o", "app", "-lboost_system"},
+			Directory:  "/src/build",
+			Output:     "app",
+			SourceFile: "/src/build/CMakeFiles/app.dir/link.txt",
+		},
+	}
+
+	built, err := buildSPDX3(result, SPDX3Options{ToolVersion: "test", DocumentName: "proj"})
+	if err != nil {
+		t.Fatalf("buildSPDX3 failed: %v", err)
+	}
+	data, err := json.Marshal(built)
+	if err != nil {
+		t.Fatalf("cannot marshal SPDX 3 document: %v", err)
+	}
+
+	var doc struct {
+		Context string                   `json:"@context"`
+		Graph   []map[string]interface{} `json:"@graph"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if !strings.Contains(doc.Context, "spdx.org/rdf/3.0") {
+		t.Errorf("@context = %q, want SPDX 3.0 context", doc.Context)
+	}
+
+	byType := map[string][]map[string]interface{}{}
+	ids := map[string]bool{}
+	for _, el := range doc.Graph {
+		typ, _ := el["type"].(string)
+		byType[typ] = append(byType[typ], el)
+		if id, ok := el["spdxId"].(string); ok {
+			ids[id] = true
+		}
+	}
+
+	if n := len(byType["software_Package"]); n != 4 {
+		t.Errorf("software_Package count = %d, want 4", n)
+	}
+	if n := len(byType["build_Build"]); n != 2 {
+		t.Errorf("build_Build count = %d, want 2", n)
+	}
+	if n := len(byType["SpdxDocument"]); n != 1 {
+		t.Fatalf("SpdxDocument count = %d, want 1", n)
+	}
+
+	// Every relationship endpoint must resolve to an element in the graph.
+	relTypes := map[string]int{}
+	for _, rel := range byType["Relationship"] {
+		relTypes[rel["relationshipType"].(string)]++
+		if from := rel["from"].(string); !ids[from] {
+			t.Errorf("relationship from %q does not resolve", from)
+		}
+		for _, to := range rel["to"].([]interface{}) {
+			if !ids[to.(string)] {
+				t.Errorf("relationship to %q does not resolve", to)
+			}
+		}
+	}
+	for _, want := range []string{"dependsOn", "hasInput", "hasOutput"} {
+		if relTypes[want] == 0 {
+			t.Errorf("missing %s relationship; got %v", want, relTypes)
+		}
+	}
+}
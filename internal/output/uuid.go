@@ -0,0 +1,74 @@
+package output
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// uuidNamespace is the RFC 4122 namespace for content-derived serial numbers:
+// the v5 UUID of the tool's URL within the standard URL namespace.
+var uuidNamespace = uuidV5([16]byte{
+	0x6b, 0xa7, 0xb8, 0x11, 0x9d, 0xad, 0x11, 0xd1,
+	0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8,
+}, []byte("https://github.com/StinkyLord/cpp-sbom-builder"))
+
+// newRandomUUID returns a random RFC 4122 version 4 UUID.
+func newRandomUUID() string {
+	var u [16]byte
+	if _, err := rand.Read(u[:]); err != nil {
+		// crypto/rand never fails on supported platforms.
+		panic(fmt.Sprintf("crypto/rand: %v", err))
+	}
+	u[6] = (u[6] & 0x0f) | 0x40
+	u[8] = (u[8] & 0x3f) | 0x80
+	return formatUUID(u)
+}
+
+// contentUUID returns an RFC 4122 version 5 UUID derived from the JSON
+// encoding of v, so identical content always yields the same UUID.
+func contentUUID(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash document content: %w", err)
+	}
+	return formatUUID(uuidV5(uuidNamespace, data)), nil
+}
+
+func uuidV5(namespace [16]byte, name []byte) [16]byte {
+	h := sha1.New()
+	h.Write(namespace[:])
+	h.Write(name)
+	var u [16]byte
+	copy(u[:], h.Sum(nil))
+	u[6] = (u[6] & 0x0f) | 0x50
+	u[8] = (u[8] & 0x3f) | 0x80
+	return u
+}
+
+func formatUUID(u [16]byte) string {
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16])
+}
+
+// creationTime returns the timestamp recorded in generated documents.
+// SOURCE_DATE_EPOCH (seconds since the Unix epoch) takes precedence when set,
+// as described by https://reproducible-builds.org/specs/source-date-epoch/.
+// Without it, reproducible output uses the Unix epoch itself and normal output
+// uses the current time.
+func creationTime(reproducible bool) (string, error) {
+	t := time.Now()
+	if epoch := os.Getenv("SOURCE_DATE_EPOCH"); epoch != "" {
+		secs, err := strconv.ParseInt(epoch, 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid SOURCE_DATE_EPOCH %q: %w", epoch, err)
+		}
+		t = time.Unix(secs, 0)
+	} else if reproducible {
+		t = time.Unix(0, 0)
+	}
+	return t.UTC().Format(time.RFC3339), nil
+}
@@ -0,0 +1,281 @@
+package policy
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/StinkyLord/cpp-sbom-builder/internal/model"
+	"github.com/StinkyLord/cpp-sbom-builder/internal/scanner"
+)
+
+// Exit codes for a policy gate. 1 is left to the tool itself failing, so
+// CI can tell a broken run from a policy verdict.
+const (
+	ExitOK       = 0
+	ExitWarnings = 2 // only warning-severity violations
+	ExitFailures = 3 // at least one error-severity violation
+)
+
+// Violation is one component breaking one rule.
+type Violation struct {
+	Rule      string `json:"rule"`
+	Severity  string `json:"severity"`
+	Component string `json:"component"`
+	Version   string `json:"version"`
+	PURL      string `json:"purl,omitempty"`
+	Message   string `json:"message"`
+
+	// File and Line locate the detection of the component the violation is
+	// reported against, when the scan recorded one.
+	File string `json:"file,omitempty"`
+	Line int    `json:"line,omitempty"`
+}
+
+// Report is the outcome of evaluating a policy.
+type Report struct {
+	Violations []Violation `json:"violations"`
+	Errors     int         `json:"errors"`
+	Warnings   int         `json:"warnings"`
+
+	rules []Rule
+	root  string
+}
+
+// ExitCode returns the exit status a policy gate should end with.
+func (r *Report) ExitCode() int {
+	switch {
+	case r.Errors > 0:
+		return ExitFailures
+	case r.Warnings > 0:
+		return ExitWarnings
+	}
+	return ExitOK
+}
+
+// Evaluate checks every component of result against every rule of p.
+// root is the scanned project directory, used to report file locations
+// relative to it; it may be empty.
+func Evaluate(p *Policy, result *scanner.Result, root string) *Report {
+	report := &Report{Violations: []Violation{}, rules: p.Rules, root: root}
+
+	comps := make([]*model.Component, len(result.Components))
+	copy(comps, result.Components)
+	sort.Slice(comps, func(i, j int) bool { return comps[i].Key() < comps[j].Key() })
+
+	for i := range p.Rules {
+		r := &p.Rules[i]
+		for _, c := range comps {
+			reasons := r.check(c)
+			if len(reasons) == 0 {
+				continue
+			}
+			v := Violation{
+				Rule:      r.ID,
+				Severity:  r.Severity,
+				Component: c.Name,
+				Version:   c.Version,
+				PURL:      c.PURL,
+				Message:   label(c) + ": " + strings.Join(reasons, "; "),
+			}
+			if e := primaryEvidence(c); e != nil {
+				v.File, v.Line = e.File, e.Line
+			}
+			report.Violations = append(report.Violations, v)
+			if r.Severity == SeverityWarning {
+				report.Warnings++
+			} else {
+				report.Errors++
+			}
+		}
+	}
+	return report
+}
+
+// check returns why c violates r, one reason per condition, or nil when the
+// rule does not apply to c or one of its conditions does not hold.
+func (r *Rule) check(c *model.Component) []string {
+	if len(r.Components) > 0 && !namedIn(c, r.Components) {
+		return nil
+	}
+	if r.Scope == ScopeDirect && !c.IsDirect || r.Scope == ScopeTransitive && c.IsDirect {
+		return nil
+	}
+
+	var reasons []string
+	if len(r.Licenses) > 0 {
+		lic := deniedLicense(c.Licenses, r.Licenses)
+		if lic == "" {
+			return nil
+		}
+		reasons = append(reasons, fmt.Sprintf("license %s is denied", lic))
+	}
+	if r.UnknownVersion {
+		if !unknownVersion(c.Version) {
+			return nil
+		}
+		reasons = append(reasons, "version is unknown")
+	}
+	if r.Version != "" {
+		if unknownVersion(c.Version) || !satisfies(c.Version, r.constraint) {
+			return nil
+		}
+		reasons = append(reasons, fmt.Sprintf("version %s matches %q", c.Version, r.Version))
+	}
+	if len(r.DetectedOnlyBy) > 0 {
+		strategies := detectedBy(c)
+		if len(strategies) == 0 {
+			return nil
+		}
+		for _, s := range strategies {
+			if !contains(r.DetectedOnlyBy, s) {
+				return nil
+			}
+		}
+		reasons = append(reasons, "only detected by "+strings.Join(strategies, ", "))
+	}
+	if r.Scope != "" {
+		reasons = append(reasons, r.Scope+" dependency")
+	}
+	return reasons
+}
+
+// label names a component in messages: "openssl 1.1.1k", or just "openssl"
+// when its version is unknown.
+func label(c *model.Component) string {
+	if unknownVersion(c.Version) {
+		return c.Name
+	}
+	return c.Name + " " + c.Version
+}
+
+func namedIn(c *model.Component, names []string) bool {
+	for _, n := range names {
+		if model.NormalizeName(n) == c.NameKey() {
+			return true
+		}
+	}
+	return false
+}
+
+func unknownVersion(v string) bool {
+	return v == "" || v == "unknown"
+}
+
+func satisfies(version string, constraint []versionClause) bool {
+	for _, cl := range constraint {
+		cmp := model.CompareVersions(version, cl.version)
+		ok := false
+		switch cl.op {
+		case "<":
+			ok = cmp < 0
+		case "<=":
+			ok = cmp <= 0
+		case ">":
+			ok = cmp > 0
+		case ">=":
+			ok = cmp >= 0
+		case "=":
+			ok = cmp == 0
+		case "!=":
+			ok = cmp != 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// deniedLicense returns the first license identifier in licenses, which may
+// be SPDX expressions, that matches one of the denied patterns.
+func deniedLicense(licenses, denied []string) string {
+	for _, l := range licenses {
+		for _, id := range strings.FieldsFunc(l, func(r rune) bool { return r == ' ' || r == '(' || r == ')' }) {
+			switch strings.ToUpper(id) {
+			case "AND", "OR", "WITH":
+				continue
+			}
+			for _, pattern := range denied {
+				if licenseMatches(id, pattern) {
+					return id
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// licenseMatches compares an SPDX ID with a pattern, case-insensitively.
+// "GPL-3.0" also matches GPL-3.0-only, GPL-3.0-or-later and GPL-3.0+; a
+// trailing "*" matches any suffix.
+func licenseMatches(id, pattern string) bool {
+	id, pattern = strings.ToLower(id), strings.ToLower(pattern)
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(id, prefix)
+	}
+	switch id {
+	case pattern, pattern + "+", pattern + "-only", pattern + "-or-later":
+		return true
+	}
+	return false
+}
+
+// detectedBy returns the strategies that reported c, from its sources, which
+// every strategy records. Components without sources fall back to their
+// evidence, then to their detection source (as when read from an SBOM).
+func detectedBy(c *model.Component) []string {
+	var out []string
+	for _, s := range c.Sources {
+		if !contains(out, s.Strategy) {
+			out = append(out, s.Strategy)
+		}
+	}
+	if len(out) == 0 {
+		for _, e := range c.Evidence {
+			if !contains(out, e.Strategy) {
+				out = append(out, e.Strategy)
+			}
+		}
+	}
+	if len(out) == 0 && c.DetectionSource != "" {
+		out = append(out, c.DetectionSource)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// primaryEvidence picks the observation a violation is reported at: a line
+// that declares the component (a requires entry, a find_package call) when
+// there is one, else an #include line, else the first file.
+func primaryEvidence(c *model.Component) *model.Evidence {
+	var best *model.Evidence
+	rank := 0
+	for i := range c.Evidence {
+		e := &c.Evidence[i]
+		r := 0
+		switch {
+		case e.File == "":
+			continue
+		case e.Line > 0 && e.Strategy != "header-scan":
+			r = 3
+		case e.Line > 0:
+			r = 2
+		default:
+			r = 1
+		}
+		if r > rank {
+			best, rank = e, r
+		}
+	}
+	return best
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
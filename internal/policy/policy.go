@@ -0,0 +1,169 @@
+// Package policy evaluates a scan result against a set of license and
+// dependency rules, for use as a release gate in CI.
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Severities of a rule.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Scopes a rule can be limited to.
+const (
+	ScopeDirect     = "direct"
+	ScopeTransitive = "transitive"
+)
+
+// Policy is a set of rules read from a policy file:
+//
+//	{
+//	  "rules": [
+//	    {"id": "no-gpl3-direct", "scope": "direct", "licenses": ["GPL-3.0"]},
+//	    {"id": "known-versions", "unknownVersion": true},
+//	    {"id": "modern-openssl", "components": ["openssl"], "version": "< 3.0"},
+//	    {"id": "header-only", "severity": "warning", "detectedOnlyBy": ["header-scan"]}
+//	  ]
+//	}
+type Policy struct {
+	Rules []Rule `json:"rules"`
+}
+
+// Rule denies components that match every condition it sets. Components and
+// Scope narrow which components the rule looks at; the other fields are the
+// conditions, and a rule must set at least one of them.
+type Rule struct {
+	// ID names the rule in reports. It is required and unique.
+	ID string `json:"id"`
+
+	// Description explains the rule to whoever reads a violation.
+	Description string `json:"description,omitempty"`
+
+	// Severity is "error" (the default), which fails the gate, or "warning".
+	Severity string `json:"severity,omitempty"`
+
+	// Components limits the rule to components with these names, compared
+	// the way the SBOM deduplicates them. Empty means every component.
+	Components []string `json:"components,omitempty"`
+
+	// Scope limits the rule to "direct" or "transitive" dependencies.
+	Scope string `json:"scope,omitempty"`
+
+	// Licenses matches components declaring any of these licenses. An SPDX
+	// ID also matches its -only, -or-later and + variants, so "GPL-3.0"
+	// covers "GPL-3.0-only"; a trailing "*" matches any suffix.
+	Licenses []string `json:"licenses,omitempty"`
+
+	// UnknownVersion matches components whose version could not be
+	// determined.
+	UnknownVersion bool `json:"unknownVersion,omitempty"`
+
+	// Version matches components whose version satisfies this constraint:
+	// comma-separated comparisons such as "< 3.0" or ">= 1.2, < 1.3".
+	// Components with an unknown version never match.
+	Version string `json:"version,omitempty"`
+
+	// DetectedOnlyBy matches components that no strategy other than these
+	// reported.
+	DetectedOnlyBy []string `json:"detectedOnlyBy,omitempty"`
+
+	constraint []versionClause
+}
+
+// Load reads and checks a policy file. Unknown fields are rejected, so a
+// misspelt condition cannot silently turn a rule into a no-op.
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read policy: %w", err)
+	}
+	p, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
+
+// Parse decodes and checks a policy document.
+func Parse(data []byte) (*Policy, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var p Policy
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("invalid policy: %w", err)
+	}
+	if len(p.Rules) == 0 {
+		return nil, fmt.Errorf("invalid policy: no rules")
+	}
+
+	seen := map[string]bool{}
+	for i := range p.Rules {
+		r := &p.Rules[i]
+		if r.ID == "" {
+			return nil, fmt.Errorf("invalid policy: rule %d has no id", i+1)
+		}
+		if seen[r.ID] {
+			return nil, fmt.Errorf("invalid policy: duplicate rule id %q", r.ID)
+		}
+		seen[r.ID] = true
+
+		switch r.Severity {
+		case "":
+			r.Severity = SeverityError
+		case SeverityError, SeverityWarning:
+		default:
+			return nil, fmt.Errorf("rule %q: severity must be %q or %q", r.ID, SeverityError, SeverityWarning)
+		}
+		if r.Scope != "" && r.Scope != ScopeDirect && r.Scope != ScopeTransitive {
+			return nil, fmt.Errorf("rule %q: scope must be %q or %q", r.ID, ScopeDirect, ScopeTransitive)
+		}
+		if r.Version != "" {
+			c, err := parseConstraint(r.Version)
+			if err != nil {
+				return nil, fmt.Errorf("rule %q: %w", r.ID, err)
+			}
+			r.constraint = c
+		}
+		if len(r.Licenses) == 0 && !r.UnknownVersion && r.Version == "" && len(r.DetectedOnlyBy) == 0 {
+			return nil, fmt.Errorf("rule %q: no condition (licenses, unknownVersion, version or detectedOnlyBy)", r.ID)
+		}
+	}
+	return &p, nil
+}
+
+// versionClause is one comparison of a version constraint.
+type versionClause struct {
+	op      string
+	version string
+}
+
+// parseConstraint parses comma-separated comparisons: "< 3.0", ">=1.2, <1.3".
+func parseConstraint(s string) ([]versionClause, error) {
+	var clauses []versionClause
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		op := ""
+		for _, candidate := range []string{"<=", ">=", "==", "!=", "<", ">", "="} {
+			if strings.HasPrefix(part, candidate) {
+				op = candidate
+				break
+			}
+		}
+		version := strings.TrimSpace(strings.TrimPrefix(part, op))
+		if op == "" || version == "" {
+			return nil, fmt.Errorf("invalid version constraint %q: want comparisons such as \"< 3.0\"", s)
+		}
+		if op == "==" {
+			op = "="
+		}
+		clauses = append(clauses, versionClause{op: op, version: version})
+	}
+	return clauses, nil
+}
@@ -0,0 +1,239 @@
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/StinkyLord/cpp-sbom-builder/internal/model"
+	"github.com/StinkyLord/cpp-sbom-builder/internal/scanner"
+)
+
+const testPolicy = `{
+	"rules": [
+		{"id": "no-gpl3-direct", "scope": "direct", "licenses": ["GPL-3.0"]},
+		{"id": "known-versions", "unknownVersion": true},
+		{"id": "modern-openssl", "components": ["OpenSSL"], "version": "< 3.0"},
+		{"id": "header-only", "severity": "warning", "detectedOnlyBy": ["header-scan"]}
+	]
+}`
+
+func testResult() *scanner.Result {
+	return &scanner.Result{Components: []*model.Component{
+		{
+			Name: "readline", Version: "8.2", IsDirect: true, Licenses: []string{"GPL-3.0-or-later"},
+			Evidence: []model.Evidence{{Strategy: "conan", File: "/src/conanfile.txt", Line: 3}},
+		},
+		// Transitive, so outside the scope of no-gpl3-direct.
+		{Name: "gmp", Version: "6.3.0", Licenses: []string{"LGPL-3.0-only OR GPL-2.0-or-later"}},
+		{
+			Name: "openssl", Version: "1.1.1k", IsDirect: true, Licenses: []string{"OpenSSL"},
+			Evidence: []model.Evidence{
+				{Strategy: "cmake", File: "/src/CMakeLists.txt"},
+				{Strategy: "header-scan", File: "/src/main.cpp", Line: 15},
+			},
+		},
+		{
+			Name: "fmt", Version: "unknown", IsDirect: true,
+			Evidence: []model.Evidence{{Strategy: "header-scan", File: "/src/main.cpp", Line: 2}},
+		},
+		{Name: "zlib", Version: "1.3", DetectionSource: "header-scan"},
+	}}
+}
+
+func TestParse_Errors(t *testing.T) {
+	cases := map[string]string{
+		`{"rules": []}`:                      "no rules",
+		`{"rules": [{"licenses": ["MIT"]}]}`: "has no id",
+		`{"rules": [{"id": "a", "unknownVersion": true}, {"id": "a", "unknownVersion": true}]}`: "duplicate",
+		`{"rules": [{"id": "a", "severity": "fatal", "unknownVersion": true}]}`:                 "severity",
+		`{"rules": [{"id": "a", "scope": "dev", "unknownVersion": true}]}`:                      "scope",
+		`{"rules": [{"id": "a", "version": "3.0"}]}`:                                            "invalid version constraint",
+		`{"rules": [{"id": "a", "components": ["zlib"]}]}`:                                      "no condition",
+		`{"rules": [{"id": "a", "license": ["MIT"]}]}`:                                          "unknown field",
+	}
+	for doc, want := range cases {
+		if _, err := Parse([]byte(doc)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Parse(%s) error = %v, want one containing %q", doc, err, want)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	p, err := Parse([]byte(testPolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := Evaluate(p, testResult(), "/src")
+
+	var got []string
+	for _, v := range report.Violations {
+		got = append(got, v.Rule+" "+v.Component)
+	}
+	want := []string{
+		"no-gpl3-direct readline",
+		"known-versions fmt",
+		"modern-openssl openssl",
+		"header-only fmt",
+		"header-only zlib",
+	}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("violations = %v, want %v", got, want)
+	}
+	if report.Errors != 3 || report.Warnings != 2 {
+		t.Errorf("errors, warnings = %d, %d, want 3, 2", report.Errors, report.Warnings)
+	}
+	if report.ExitCode() != ExitFailures {
+		t.Errorf("ExitCode() = %d, want %d", report.ExitCode(), ExitFailures)
+	}
+
+	gpl := report.Violations[0]
+	if gpl.Message != "readline 8.2: license GPL-3.0-or-later is denied; direct dependency" {
+		t.Errorf("message = %q", gpl.Message)
+	}
+	if gpl.File != "/src/conanfile.txt" || gpl.Line != 3 {
+		t.Errorf("location = %s:%d, want /src/conanfile.txt:3", gpl.File, gpl.Line)
+	}
+	// The evidence with a line number is preferred.
+	if ssl := report.Violations[2]; ssl.File != "/src/main.cpp" || ssl.Line != 15 {
+		t.Errorf("openssl location = %s:%d, want /src/main.cpp:15", ssl.File, ssl.Line)
+	}
+}
+
+func TestEvaluate_WarningsOnly(t *testing.T) {
+	p, err := Parse([]byte(`{"rules": [{"id": "w", "severity": "warning", "unknownVersion": true}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code := Evaluate(p, testResult(), "").ExitCode(); code != ExitWarnings {
+		t.Errorf("ExitCode() = %d, want %d", code, ExitWarnings)
+	}
+
+	p, _ = Parse([]byte(`{"rules": [{"id": "e", "licenses": ["AGPL-*"]}]}`))
+	if code := Evaluate(p, testResult(), "").ExitCode(); code != ExitOK {
+		t.Errorf("ExitCode() = %d, want %d", code, ExitOK)
+	}
+}
+
+func TestLicenseMatches(t *testing.T) {
+	cases := []struct {
+		id, pattern string
+		want        bool
+	}{
+		{"GPL-3.0-only", "GPL-3.0", true},
+		{"GPL-3.0-or-later", "gpl-3.0", true},
+		{"GPL-3.0+", "GPL-3.0", true},
+		{"LGPL-3.0-only", "GPL-3.0", false},
+		{"GPL-2.0-only", "GPL-*", true},
+		{"MIT", "MIT", true},
+		{"MIT-0", "MIT", false},
+	}
+	for _, c := range cases {
+		if got := licenseMatches(c.id, c.pattern); got != c.want {
+			t.Errorf("licenseMatches(%q, %q) = %v, want %v", c.id, c.pattern, got, c.want)
+		}
+	}
+}
+
+func TestReport_Formats(t *testing.T) {
+	p, _ := Parse([]byte(testPolicy))
+	report := Evaluate(p, testResult(), "/src")
+
+	var text bytes.Buffer
+	if err := report.Write(&text, "text", "1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"error   no-gpl3-direct: readline 8.2: license GPL-3.0-or-later is denied",
+		"        at conanfile.txt:3\n",
+		"warning header-only: zlib 1.3: only detected by header-scan\n",
+		"Policy: 3 error(s), 2 warning(s)",
+	} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("text report lacks %q:\n%s", want, text.String())
+		}
+	}
+
+	var js bytes.Buffer
+	if err := report.Write(&js, "json", "1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(js.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON report: %v", err)
+	}
+	if len(decoded.Violations) != 5 || decoded.Violations[0].File != "conanfile.txt" {
+		t.Errorf("JSON report = %s", js.String())
+	}
+
+	var sarifOut bytes.Buffer
+	if err := report.Write(&sarifOut, "sarif", "1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	var log struct {
+		Runs []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct{ ID string }
+				}
+			}
+			Results []struct {
+				RuleID    string
+				Level     string
+				Locations []struct {
+					PhysicalLocation *struct {
+						ArtifactLocation struct{ URI, URIBaseID string }
+						Region           struct{ StartLine int }
+					}
+				}
+			}
+		}
+	}
+	if err := json.Unmarshal(sarifOut.Bytes(), &log); err != nil {
+		t.Fatalf("invalid SARIF: %v", err)
+	}
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != 4 || len(run.Results) != 5 {
+		t.Fatalf("SARIF has %d rules and %d results, want 4 and 5", len(run.Tool.Driver.Rules), len(run.Results))
+	}
+	for _, r := range run.Results {
+		if r.RuleID != "no-gpl3-direct" {
+			continue
+		}
+		loc := r.Locations[0].PhysicalLocation
+		if r.Level != "error" || loc == nil || loc.ArtifactLocation.URI != "conanfile.txt" || loc.Region.StartLine != 3 {
+			t.Errorf("no-gpl3-direct result = %+v", r)
+		}
+	}
+
+	if err := report.Write(&bytes.Buffer{}, "xml", "1.0.0"); err == nil {
+		t.Error("Write accepted an unsupported format")
+	}
+}
+
+// TestEvaluate_DetectedOnlyBySources verifies that detectedOnlyBy counts
+// every strategy in a component's sources, including those that recorded no
+// evidence.
+func TestEvaluate_DetectedOnlyBySources(t *testing.T) {
+	p, err := Parse([]byte(`{"rules": [{"id": "header-only", "detectedOnlyBy": ["header-scan"]}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	headerEvidence := []model.Evidence{{Strategy: "header-scan", File: "/src/main.cpp", Line: 2}}
+	result := &scanner.Result{Components: []*model.Component{
+		{
+			Name: "spdlog", Version: "1.12.0", Evidence: headerEvidence,
+			Sources: []model.Source{{Strategy: "header-scan"}, {Strategy: "vcpkg", File: "vcpkg.json"}},
+		},
+		{
+			Name: "stb", Version: "unknown", Evidence: headerEvidence,
+			Sources: []model.Source{{Strategy: "header-scan"}},
+		},
+	}}
+
+	report := Evaluate(p, result, "/src")
+	if len(report.Violations) != 1 || report.Violations[0].Component != "stb" {
+		t.Errorf("violations = %+v, want header-only for stb only", report.Violations)
+	}
+}
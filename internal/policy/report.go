@@ -0,0 +1,134 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/StinkyLord/cpp-sbom-builder/internal/sarif"
+)
+
+// Formats a report can be written in.
+var Formats = []string{"text", "json", "sarif"}
+
+// Write writes the report in one of Formats.
+func (r *Report) Write(w io.Writer, format, toolVersion string) error {
+	switch format {
+	case "text":
+		return r.WriteText(w)
+	case "json":
+		return r.WriteJSON(w)
+	case "sarif":
+		return r.WriteSARIF(w, toolVersion)
+	}
+	return fmt.Errorf("unsupported policy report format %q (supported: %s)", format, strings.Join(Formats, ", "))
+}
+
+// WriteText writes one line per violation, followed by its location and a
+// summary line.
+func (r *Report) WriteText(w io.Writer) error {
+	for _, v := range r.Violations {
+		fmt.Fprintf(w, "%-7s %s: %s\n", v.Severity, v.Rule, v.Message)
+		if loc := r.location(v); loc != "" {
+			fmt.Fprintf(w, "        at %s\n", loc)
+		}
+	}
+	_, err := fmt.Fprintf(w, "Policy: %d error(s), %d warning(s)\n", r.Errors, r.Warnings)
+	return err
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	out := *r
+	out.Violations = make([]Violation, len(r.Violations))
+	for i, v := range r.Violations {
+		if v.File != "" {
+			v.File = r.relative(v.File)
+		}
+		out.Violations[i] = v
+	}
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal policy report: %w", err)
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// WriteSARIF writes the report as a SARIF log with one rule per policy rule,
+// so code-scanning services show violations at the line that declared the
+// offending component.
+func (r *Report) WriteSARIF(w io.Writer, toolVersion string) error {
+	log := sarif.New("cpp-sbom-builder", toolVersion, "https://github.com/StinkyLord/cpp-sbom-builder", r.root)
+	if err := r.AddToSARIF(log); err != nil {
+		return err
+	}
+	return log.Write(w)
+}
+
+// AddToSARIF adds the policy rules and violations to log, for logs that
+// carry other findings too.
+func (r *Report) AddToSARIF(log *sarif.Log) error {
+	for _, rule := range r.rules {
+		sr := sarif.Rule{
+			ID:                   rule.ID,
+			DefaultConfiguration: &sarif.Configuration{Level: sarifLevel(rule.Severity)},
+		}
+		if rule.Description != "" {
+			sr.ShortDescription = &sarif.Message{Text: rule.Description}
+		}
+		log.AddRule(sr)
+	}
+
+	for _, v := range r.Violations {
+		res := sarif.Result{
+			RuleID:  v.Rule,
+			Level:   sarifLevel(v.Severity),
+			Message: sarif.Message{Text: v.Message},
+			PartialFingerprints: map[string]string{
+				"component/v1": v.Rule + ":" + v.Component + "@" + v.Version,
+			},
+		}
+		loc := sarif.Location{LogicalLocations: []sarif.LogicalLocation{{
+			Name: v.Component, FullyQualifiedName: v.PURL, Kind: "module",
+		}}}
+		if v.File != "" {
+			loc.PhysicalLocation = sarif.FileLocation(r.root, v.File, v.Line)
+		}
+		res.Locations = []sarif.Location{loc}
+		if err := log.AddResult(res); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sarifLevel(severity string) string {
+	if severity == SeverityWarning {
+		return sarif.LevelWarning
+	}
+	return sarif.LevelError
+}
+
+// location formats where a violation was detected, relative to the project.
+func (r *Report) location(v Violation) string {
+	if v.File == "" {
+		return ""
+	}
+	if v.Line > 0 {
+		return fmt.Sprintf("%s:%d", r.relative(v.File), v.Line)
+	}
+	return r.relative(v.File)
+}
+
+func (r *Report) relative(file string) string {
+	if r.root == "" {
+		return file
+	}
+	if rel, err := filepath.Rel(r.root, file); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return file
+}
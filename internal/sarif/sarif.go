@@ -0,0 +1,214 @@
+// Package sarif writes SARIF 2.1.0 logs, the format code-scanning services
+// ingest to show findings inline in pull requests.
+package sarif
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	schemaURL = "https://json.schemastore.org/sarif-2.1.0.json"
+	version   = "2.1.0"
+
+	// SrcRoot is the base of relative artifact URIs: the scanned project
+	// directory, which code-scanning services map to the repository root.
+	SrcRoot = "%SRCROOT%"
+)
+
+// Levels of a result.
+const (
+	LevelError   = "error"
+	LevelWarning = "warning"
+	LevelNote    = "note"
+)
+
+// Log is a SARIF log holding a single run of one tool.
+type Log struct {
+	Schema  string `json:"$schema"`
+	Version string `json:"version"`
+	Runs    []Run  `json:"runs"`
+}
+
+// Run is the output of one tool invocation.
+type Run struct {
+	Tool    Tool     `json:"tool"`
+	Results []Result `json:"results"`
+
+	// OriginalURIBaseIDs anchors SrcRoot to the scanned directory, so
+	// consumers that read the log outside CI can resolve locations too.
+	OriginalURIBaseIDs map[string]ArtifactLocation `json:"originalUriBaseIds,omitempty"`
+}
+
+// Tool identifies the tool that produced a run, and the rules it checks.
+type Tool struct {
+	Driver Driver `json:"driver"`
+}
+
+type Driver struct {
+	Name           string `json:"name"`
+	Version        string `json:"version,omitempty"`
+	InformationURI string `json:"informationUri,omitempty"`
+	Rules          []Rule `json:"rules"`
+}
+
+// Rule describes one kind of finding.
+type Rule struct {
+	ID                   string         `json:"id"`
+	Name                 string         `json:"name,omitempty"`
+	ShortDescription     *Message       `json:"shortDescription,omitempty"`
+	FullDescription      *Message       `json:"fullDescription,omitempty"`
+	DefaultConfiguration *Configuration `json:"defaultConfiguration,omitempty"`
+}
+
+type Configuration struct {
+	Level string `json:"level"`
+}
+
+// Message is plain-text prose.
+type Message struct {
+	Text string `json:"text"`
+}
+
+// Result is one finding.
+type Result struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             Message           `json:"message"`
+	Locations           []Location        `json:"locations,omitempty"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+	Properties          map[string]any    `json:"properties,omitempty"`
+}
+
+// Location places a result in a file, a component, or both.
+type Location struct {
+	PhysicalLocation *PhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []LogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type PhysicalLocation struct {
+	ArtifactLocation ArtifactLocation `json:"artifactLocation"`
+	Region           *Region          `json:"region,omitempty"`
+}
+
+type ArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type Region struct {
+	StartLine int `json:"startLine"`
+}
+
+// LogicalLocation names the component a finding is about.
+type LogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName,omitempty"`
+	Kind               string `json:"kind,omitempty"`
+}
+
+// New returns a log for one run of the named tool over the project in root.
+func New(toolName, toolVersion, informationURI, root string) *Log {
+	run := Run{
+		Tool:    Tool{Driver: Driver{Name: toolName, Version: toolVersion, InformationURI: informationURI, Rules: []Rule{}}},
+		Results: []Result{},
+	}
+	if root != "" {
+		uri := fileURI(root)
+		if !strings.HasSuffix(uri, "/") {
+			uri += "/"
+		}
+		run.OriginalURIBaseIDs = map[string]ArtifactLocation{SrcRoot: {URI: uri}}
+	}
+	return &Log{Schema: schemaURL, Version: version, Runs: []Run{run}}
+}
+
+// AddRule registers a rule unless one with the same ID exists, and returns
+// its index for Result.RuleIndex.
+func (l *Log) AddRule(r Rule) int {
+	driver := &l.Runs[0].Tool.Driver
+	for i, existing := range driver.Rules {
+		if existing.ID == r.ID {
+			return i
+		}
+	}
+	driver.Rules = append(driver.Rules, r)
+	return len(driver.Rules) - 1
+}
+
+// AddResult appends a finding for a rule previously registered with AddRule.
+func (l *Log) AddResult(r Result) error {
+	rules := l.Runs[0].Tool.Driver.Rules
+	for i, rule := range rules {
+		if rule.ID == r.RuleID {
+			r.RuleIndex = i
+			l.Runs[0].Results = append(l.Runs[0].Results, r)
+			return nil
+		}
+	}
+	return fmt.Errorf("sarif: result for unknown rule %q", r.RuleID)
+}
+
+// Write encodes the log as indented JSON. Results are sorted by rule,
+// location and message so identical findings give identical logs.
+func (l *Log) Write(w io.Writer) error {
+	results := l.Runs[0].Results
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.RuleID != b.RuleID {
+			return a.RuleID < b.RuleID
+		}
+		if ka, kb := locationKey(a), locationKey(b); ka != kb {
+			return ka < kb
+		}
+		return a.Message.Text < b.Message.Text
+	})
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal SARIF: %w", err)
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+func locationKey(r Result) string {
+	if len(r.Locations) == 0 || r.Locations[0].PhysicalLocation == nil {
+		return ""
+	}
+	p := r.Locations[0].PhysicalLocation
+	line := 0
+	if p.Region != nil {
+		line = p.Region.StartLine
+	}
+	return fmt.Sprintf("%s:%09d", p.ArtifactLocation.URI, line)
+}
+
+// FileLocation returns the location of line in file. Files inside root are
+// given relative to SrcRoot; others as absolute file:// URIs. A line of 0
+// leaves the region out.
+func FileLocation(root, file string, line int) *PhysicalLocation {
+	loc := &PhysicalLocation{ArtifactLocation: ArtifactLocation{URI: fileURI(file)}}
+	if root != "" {
+		if rel, err := filepath.Rel(root, file); err == nil && !strings.HasPrefix(rel, "..") {
+			loc.ArtifactLocation = ArtifactLocation{URI: filepath.ToSlash(rel), URIBaseID: SrcRoot}
+		}
+	}
+	if line > 0 {
+		loc.Region = &Region{StartLine: line}
+	}
+	return loc
+}
+
+// fileURI converts an absolute path to a file:// URI.
+func fileURI(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path // C:/src → /C:/src
+	}
+	return "file://" + path
+}
@@ -0,0 +1,55 @@
+package sarif
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestFileLocation(t *testing.T) {
+	loc := FileLocation("/src/project", "/src/project/cmake/deps.cmake", 12)
+	if loc.ArtifactLocation.URI != "cmake/deps.cmake" || loc.ArtifactLocation.URIBaseID != SrcRoot {
+		t.Errorf("artifact location = %+v, want cmake/deps.cmake relative to %s", loc.ArtifactLocation, SrcRoot)
+	}
+	if loc.Region == nil || loc.Region.StartLine != 12 {
+		t.Errorf("region = %+v, want line 12", loc.Region)
+	}
+
+	outside := FileLocation("/src/project", "/usr/lib/libssl.so", 0)
+	if outside.ArtifactLocation.URI != "file:///usr/lib/libssl.so" || outside.ArtifactLocation.URIBaseID != "" {
+		t.Errorf("artifact location = %+v, want an absolute file URI", outside.ArtifactLocation)
+	}
+	if outside.Region != nil {
+		t.Errorf("region = %+v, want none for line 0", outside.Region)
+	}
+}
+
+func TestLog(t *testing.T) {
+	log := New("tool", "1.0.0", "", "/src/project")
+	log.AddRule(Rule{ID: "b"})
+	log.AddRule(Rule{ID: "a"})
+	if i := log.AddRule(Rule{ID: "b"}); i != 0 {
+		t.Errorf("AddRule of an existing rule = %d, want 0", i)
+	}
+	if err := log.AddResult(Result{RuleID: "c"}); err == nil {
+		t.Error("AddResult accepted a result for an unregistered rule")
+	}
+	log.AddResult(Result{RuleID: "b", Message: Message{Text: "second"}})
+	log.AddResult(Result{RuleID: "a", Message: Message{Text: "first"}})
+
+	var buf bytes.Buffer
+	if err := log.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var decoded Log
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	results := decoded.Runs[0].Results
+	if len(results) != 2 || results[0].RuleID != "a" || results[0].RuleIndex != 1 || results[1].RuleIndex != 0 {
+		t.Errorf("results = %+v, want a (index 1) before b (index 0)", results)
+	}
+	if base := decoded.Runs[0].OriginalURIBaseIDs[SrcRoot].URI; base != "file:///src/project/" {
+		t.Errorf("%s = %q, want file:///src/project/", SrcRoot, base)
+	}
+}
@@ -0,0 +1,91 @@
+package scanner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/StinkyLord/cpp-sbom-builder/internal/strategies"
+)
+
+// registry lists every strategy the scanner can run, in the order their
+// results are merged. Strategies are selected by their Name().
+var registry = []Strategy{
+	&strategies.ConanGraphStrategy{},
+	&strategies.ConanStrategy{},
+	&strategies.LinkerMapStrategy{},
+	&strategies.BinaryEdgesStrategy{},
+	&strategies.CompileCommandsStrategy{},
+	&strategies.BuildLogsStrategy{},
+	&strategies.CMakeStrategy{},
+	&strategies.VcpkgStrategy{},
+	&strategies.MesonStrategy{},
+	&strategies.HeadersStrategy{},
+	&strategies.CMakeConfigureStrategy{},
+	&strategies.LddStrategy{},
+}
+
+// optIn maps the strategies that are off by default, because they run
+// external tools, to the Scanner flag that turns them on. Naming one in
+// Scanner.Strategies turns it on too.
+var optIn = map[string]func(s *Scanner) bool{
+	"cmake-configure": func(s *Scanner) bool { return s.CMakeConfigure },
+	"ldd":             func(s *Scanner) bool { return s.UseLdd },
+}
+
+// StrategyNames returns the names of every registered strategy, in merge
+// order.
+func StrategyNames() []string {
+	names := make([]string, len(registry))
+	for i, st := range registry {
+		names[i] = st.Name()
+	}
+	return names
+}
+
+// CheckStrategyNames returns an error naming the first of names that is not
+// a registered strategy, so a typo cannot silently disable a strategy.
+func CheckStrategyNames(names []string) error {
+	known := StrategyNames()
+	for _, name := range names {
+		if !appearsIn(known, name) {
+			return fmt.Errorf("unknown strategy %q (available: %s)", name, strings.Join(known, ", "))
+		}
+	}
+	return nil
+}
+
+// selectStrategies resolves Strategies and SkipStrategies into the set of
+// strategy names to run.
+func (s *Scanner) selectStrategies() (map[string]bool, error) {
+	for _, list := range [][]string{s.Strategies, s.SkipStrategies} {
+		if err := CheckStrategyNames(list); err != nil {
+			return nil, err
+		}
+	}
+
+	known := StrategyNames()
+	enabled := map[string]bool{}
+	for _, name := range known {
+		switch {
+		case len(s.Strategies) > 0:
+			enabled[name] = appearsIn(s.Strategies, name)
+		case optIn[name] != nil:
+			enabled[name] = optIn[name](s)
+		default:
+			enabled[name] = true
+		}
+		if appearsIn(s.SkipStrategies, name) {
+			enabled[name] = false
+		}
+	}
+	return enabled, nil
+}
+
+func appearsIn(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+package scanner
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/StinkyLord/cpp-sbom-builder/internal/model"
+)
+
+func TestSelectStrategies(t *testing.T) {
+	cases := []struct {
+		name    string
+		scanner Scanner
+		on, off []string
+	}{
+		{
+			name:    "defaults",
+			scanner: Scanner{},
+			on:      []string{"conan", "cmake", "header-scan"},
+			off:     []string{"cmake-configure", "ldd"},
+		},
+		{
+			name:    "opt-in flag",
+			scanner: Scanner{UseLdd: true},
+			on:      []string{"ldd", "header-scan"},
+			off:     []string{"cmake-configure"},
+		},
+		{
+			name:    "skip",
+			scanner: Scanner{SkipStrategies: []string{"header-scan"}},
+			on:      []string{"conan", "cmake"},
+			off:     []string{"header-scan"},
+		},
+		{
+			name:    "only, enabling an opt-in strategy",
+			scanner: Scanner{Strategies: []string{"conan", "ldd"}},
+			on:      []string{"conan", "ldd"},
+			off:     []string{"conan-graph", "cmake", "header-scan"},
+		},
+		{
+			name:    "only and skip",
+			scanner: Scanner{Strategies: []string{"conan", "vcpkg"}, SkipStrategies: []string{"vcpkg"}},
+			on:      []string{"conan"},
+			off:     []string{"vcpkg", "cmake"},
+		},
+	}
+	for _, c := range cases {
+		enabled, err := c.scanner.selectStrategies()
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		for _, name := range c.on {
+			if !enabled[name] {
+				t.Errorf("%s: %s is disabled, want enabled", c.name, name)
+			}
+		}
+		for _, name := range c.off {
+			if enabled[name] {
+				t.Errorf("%s: %s is enabled, want disabled", c.name, name)
+			}
+		}
+	}
+
+	s := Scanner{SkipStrategies: []string{"headers"}}
+	if _, err := s.selectStrategies(); err == nil || !strings.Contains(err.Error(), `"headers"`) {
+		t.Errorf("unknown strategy error = %v", err)
+	}
+}
+
+// TestScan_StrategySelection verifies that a disabled strategy neither
+// reports components nor marks them direct.
+func TestScan_StrategySelection(t *testing.T) {
+	s := New("../../testdata/sample-cpp-project", nil)
+	s.Strategies = []string{"cmake"}
+	result, err := s.Scan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(result.StrategiesUsed, ",") != "cmake" {
+		t.Errorf("StrategiesUsed = %v, want [cmake]", result.StrategiesUsed)
+	}
+	for _, c := range result.Components {
+		if strings.Contains(c.DependencyTypeReason, "header-scan") {
+			t.Errorf("%s: reason %q cites a disabled strategy", c.Name, c.DependencyTypeReason)
+		}
+	}
+}
+
+// TestScan_Timeouts verifies that a strategy out of time is skipped while the
+// scan goes on, and that the scan fails once its own timeout expires.
+func TestScan_Timeouts(t *testing.T) {
+	// header-scan needs source files to run out of time on; a strategy
+	// with nothing to do finishes within any timeout.
+	s := New("../../testdata/strategies", nil)
+	s.Strategies = []string{"conan", "header-scan"}
+	s.StrategyTimeouts = map[string]time.Duration{"header-scan": time.Nanosecond}
+	var events []ProgressEvent
+	s.Progress = func(e ProgressEvent) { events = append(events, e) }
+	result, err := s.Scan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(result.StrategiesUsed, ",") != "conan" || strings.Join(result.StrategiesSkipped, ",") != "header-scan" {
+		t.Errorf("used %v, skipped %v; want [conan], [header-scan]", result.StrategiesUsed, result.StrategiesSkipped)
+	}
+	if len(events) != 4 {
+		t.Fatalf("got %d progress events, want 4", len(events))
+	}
+	for _, e := range events {
+		if !e.Finished {
+			continue
+		}
+		if timedOut := e.Err != nil && strings.Contains(e.Err.Error(), "timed out"); timedOut != (e.Strategy == "header-scan") {
+			t.Errorf("%s finished with error %v", e.Strategy, e.Err)
+		}
+	}
+
+	s = New("../../testdata/sample-cpp-project", nil)
+	s.Timeout = time.Nanosecond
+	if _, err := s.Scan(); err == nil || !strings.Contains(err.Error(), "scan timed out") {
+		t.Errorf("Scan() error = %v, want a scan timeout", err)
+	}
+}
+
+// TestScan_Sources verifies that merging keeps every strategy that reported
+// a component, with the file and version each one reported.
+func TestScan_Sources(t *testing.T) {
+	s := New("../../testdata/strategies", nil)
+	s.Strategies = []string{"conan-graph", "compile_commands.json"}
+	s.Reproducible = true
+	result, err := s.Scan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var boost *model.Component
+	for _, c := range result.Components {
+		if c.Name == "boost" {
+			boost = c
+		}
+	}
+	if boost == nil {
+		t.Fatal("boost not found")
+	}
+	want := []model.Source{
+		{Strategy: "compile_commands.json", File: "compile_commands.json", Version: "1.82.0"},
+		{Strategy: "conan-graph", File: "graph.json", Version: "1.84.0"},
+	}
+	if len(boost.Sources) != len(want) {
+		t.Fatalf("boost sources = %v, want %v", boost.Sources, want)
+	}
+	for i := range want {
+		if boost.Sources[i] != want[i] {
+			t.Errorf("boost source %d = %v, want %v", i, boost.Sources[i], want[i])
+		}
+	}
+}
@@ -2,10 +2,16 @@
 package scanner
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
+	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/StinkyLord/cpp-sbom-builder/internal/logging"
 	"github.com/StinkyLord/cpp-sbom-builder/internal/model"
 	"github.com/StinkyLord/cpp-sbom-builder/internal/strategies"
 )
@@ -13,22 +19,70 @@ import (
 // Strategy is the interface every detection strategy must implement.
 type Strategy interface {
 	Name() string
-	Scan(projectRoot string, verbose bool) ([]*model.Component, error)
+	// Scan returns the components found in the indexed project. It returns
+	// ctx.Err() when ctx is done before it finishes, and must stop any
+	// external process it started.
+	// Problems it meets along the way (files it cannot read or parse) go to
+	// diags; what it is doing goes to log, which is never nil.
+	Scan(ctx context.Context, ix *strategies.FileIndex, diags *strategies.Diagnostics, log *slog.Logger) ([]*model.Component, error)
+}
+
+// ProgressEvent reports a strategy starting or finishing.
+type ProgressEvent struct {
+	Strategy string
+
+	// Finished is false when the strategy starts and true when it returns.
+	Finished bool
+
+	// Components is the number of components the strategy found, and Err
+	// why it failed or was cut short (a timeout among others). Elapsed is
+	// how long it ran. They are only set when Finished.
+	Components int
+	Err        error
+	Elapsed    time.Duration
 }
 
 // Result holds the final merged list of components and metadata about which
 // strategies fired.
 type Result struct {
+	// Project describes the scanned project itself. Its Dependencies are the
+	// direct dependencies, making it the root of the dependency graph. Nil
+	// when no project name could be determined.
+	Project *model.Component
+
+	// Assemblies are the sub-projects Project is built from, each with
+	// Dependencies naming its own direct dependencies. Only set for merged
+	// SBOMs, where every input becomes one assembly.
+	Assemblies []*model.Component
+
 	Components        []*model.Component
 	DependencyTree    *model.DependencyTree
 	StrategiesUsed    []string
 	StrategiesSkipped []string
+
+	// BuildInvocations holds the compiler and linker command lines recorded
+	// by the build system. Only populated when Scanner.CollectBuildInfo is set.
+	BuildInvocations []model.BuildInvocation
+
+	// Diagnostics are the problems met while scanning: files that could not
+	// be read or parsed, strategies that failed or timed out. Files are
+	// relative to the project root when inside it. Sorted by strategy, file
+	// and line.
+	Diagnostics []model.Diagnostic
+
+	// Vulnerabilities holds the known advisories matching Components. The
+	// scanner does not fill it; it is set by matching against a vulnerability
+	// database (see the vulns package).
+	Vulnerabilities []model.Vulnerability
 }
 
 // Scanner runs all strategies against a project root and merges the results.
 type Scanner struct {
 	ProjectRoot string
-	Verbose     bool
+
+	// Log receives the scan's debug and progress messages, each strategy's
+	// tagged with a "strategy" attribute. Nil discards them.
+	Log *slog.Logger
 
 	// ConanGraph enables the conan-graph strategy active mode.
 	// When true the strategy walks the project tree for conanfile.py/txt files
@@ -47,25 +101,148 @@ type Scanner struct {
 	// When true the strategy reads ldd-results.json (produced by the Docker
 	// entrypoint) to extract runtime dependency edges from .so files.
 	UseLdd bool
+
+	// CollectBuildInfo records every compile_commands.json entry and link.txt
+	// command line in Result.BuildInvocations, for output formats that
+	// describe how the project was built.
+	CollectBuildInfo bool
+
+	// ProjectName and ProjectVersion override the project name and version
+	// detected from CMakeLists.txt, meson.build or conanfile.py.
+	ProjectName    string
+	ProjectVersion string
+
+	// Strategies, when set, limits the scan to the strategies with these
+	// names (see StrategyNames); SkipStrategies excludes strategies. Both
+	// apply to the direct-dependency pass too. Naming cmake-configure or ldd
+	// in Strategies enables it without CMakeConfigure or UseLdd.
+	Strategies     []string
+	SkipStrategies []string
+
+	// Reproducible sorts every order-sensitive list in the result (strategy
+	// names, dependency edges, include paths, link libraries, artifacts) so
+	// two scans of an identical tree produce identical output.
+	Reproducible bool
+
+	// Timeout bounds the whole scan; ScanContext fails once it expires. Zero
+	// means no limit.
+	Timeout time.Duration
+
+	// StrategyTimeouts bounds single strategies, by name. A strategy that
+	// runs out of time is reported as skipped and the scan goes on without
+	// its results.
+	StrategyTimeouts map[string]time.Duration
+
+	// Progress, when set, is called as each strategy starts and finishes.
+	// Calls are never concurrent, although strategies are.
+	Progress func(ProgressEvent)
 }
 
-// New creates a Scanner.
-func New(projectRoot string, verbose bool) *Scanner {
+// New creates a Scanner logging to log, which may be nil.
+func New(projectRoot string, log *slog.Logger) *Scanner {
 	return &Scanner{
 		ProjectRoot: projectRoot,
-		Verbose:     verbose,
+		Log:         log,
 	}
 }
 
 // Scan runs all strategies concurrently and returns merged, deduplicated results
 // with a full dependency hierarchy (direct vs. transitive).
 func (s *Scanner) Scan() (*Result, error) {
+	return s.ScanContext(context.Background())
+}
+
+// ScanContext is Scan with a context: when ctx is done, or Timeout expires,
+// running strategies are stopped (external tools such as conan are killed)
+// and the scan fails with the cause.
+func (s *Scanner) ScanContext(ctx context.Context) (*Result, error) {
 	type stratResult struct {
+		// order is the strategy's submission index. Results are merged in
+		// this order rather than in completion order, so the component that
+		// wins a merge does not depend on goroutine scheduling.
+		order      int
 		name       string
 		components []*model.Component
 		err        error
 	}
 
+	enabled, err := s.selectStrategies()
+	if err != nil {
+		return nil, err
+	}
+
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, s.Timeout, fmt.Errorf("scan timed out after %s", s.Timeout))
+		defer cancel()
+	}
+
+	// Walk the project once; every strategy looks its files up in the index.
+	log := s.Log
+	if log == nil {
+		log = logging.Discard()
+	}
+	ix := strategies.NewFileIndex(s.ProjectRoot)
+	log.Info("indexed project", "root", s.ProjectRoot, "files", ix.Len())
+	if ctx.Err() != nil {
+		return nil, context.Cause(ctx)
+	}
+
+	// run runs one strategy under its timeout, reporting its progress and
+	// collecting its diagnostics. scan returns the number of components found.
+	var progressMu, diagMu sync.Mutex
+	diagnostics := ix.Diagnostics()
+	report := func(e ProgressEvent) {
+		if s.Progress == nil {
+			return
+		}
+		progressMu.Lock()
+		defer progressMu.Unlock()
+		s.Progress(e)
+	}
+	run := func(name string, scan func(ctx context.Context, diags *strategies.Diagnostics, log *slog.Logger) (int, error)) error {
+		sctx, cancel := s.strategyContext(ctx, name)
+		defer cancel()
+		report(ProgressEvent{Strategy: name})
+		stratLog := log.With("strategy", name)
+		stratLog.Debug("running strategy")
+		start := time.Now()
+		diags := strategies.NewDiagnostics(name)
+		n, err := scan(sctx, diags, stratLog)
+		if err != nil && sctx.Err() != nil {
+			// Cut short: report the timeout or cancellation rather than
+			// the context error it surfaced as. A strategy that finished
+			// before its context ended keeps its results.
+			n, err = 0, context.Cause(sctx)
+		}
+		if err != nil {
+			stratLog.Warn("strategy failed", "err", err)
+			diags.Errorf("", "strategy failed, its results are not used: %v", err)
+		} else {
+			stratLog.Info("strategy finished", "components", n, "elapsed", time.Since(start))
+		}
+		diagMu.Lock()
+		diagnostics = append(diagnostics, diags.List()...)
+		diagMu.Unlock()
+		report(ProgressEvent{Strategy: name, Finished: true, Components: n, Err: err, Elapsed: time.Since(start)})
+		return err
+	}
+
+	// runEdges runs a strategy whose result carries graph edges. It reports
+	// whether the result can be used.
+	errs := map[string]error{}
+	runEdges := func(name string, scan func(ctx context.Context, diags *strategies.Diagnostics, log *slog.Logger) int) bool {
+		if !enabled[name] {
+			return false
+		}
+		errs[name] = run(name, func(ctx context.Context, diags *strategies.Diagnostics, log *slog.Logger) (int, error) {
+			// These strategies stop early when ctx ends without saying
+			// so, as their Scan methods do.
+			return scan(ctx, diags, log), ctx.Err()
+		})
+		return errs[name] == nil
+	}
+
 	// --- Strategies that return graph edges run separately ---
 
 	// ConanGraphStrategy: runs first if --conan-graph is set or a graph.json exists.
@@ -73,110 +250,138 @@ func (s *Scanner) Scan() (*Result, error) {
 	conanGraphStrat := &strategies.ConanGraphStrategy{
 		RunConan: s.ConanGraph,
 	}
-	conanGraphFullResult := conanGraphStrat.ScanWithGraph(s.ProjectRoot, s.Verbose)
+	conanGraphFullResult := &strategies.ConanScanResult{}
+	var conanGraphRun *strategies.ConanScanResult
+	if runEdges(conanGraphStrat.Name(), func(ctx context.Context, diags *strategies.Diagnostics, log *slog.Logger) int {
+		conanGraphRun = conanGraphStrat.ScanWithGraph(ctx, ix, diags, log)
+		return len(conanGraphRun.Components)
+	}) {
+		conanGraphFullResult = conanGraphRun
+	}
 
 	// Plain ConanStrategy (conanfile.txt/py + conan.lock) — used as fallback
 	// when conan-graph produced no results.
 	conanStrat := &strategies.ConanStrategy{}
-	conanLockResult := conanStrat.ScanWithGraph(s.ProjectRoot, s.Verbose)
+	conanLockResult := &strategies.ConanScanResult{}
+	var conanLockRun *strategies.ConanScanResult
+	if runEdges(conanStrat.Name(), func(ctx context.Context, diags *strategies.Diagnostics, log *slog.Logger) int {
+		conanLockRun = conanStrat.ScanWithGraph(ctx, ix, diags, log)
+		return len(conanLockRun.Components)
+	}) {
+		conanLockResult = conanLockRun
+	}
 
 	// Decide which conan result to use for the dependency graph.
 	// conan-graph wins if it found any components (it has richer data).
-	var activeConanResult *strategies.ConanScanResult
+	// When neither is enabled, activeConanName stays empty.
+	activeConanResult := conanLockResult
 	var activeConanName string
 	if len(conanGraphFullResult.Components) > 0 {
 		activeConanResult = conanGraphFullResult
 		activeConanName = conanGraphStrat.Name()
-	} else {
-		activeConanResult = conanLockResult
+	} else if enabled[conanStrat.Name()] {
 		activeConanName = conanStrat.Name()
+	} else if enabled[conanGraphStrat.Name()] {
+		activeConanName = conanGraphStrat.Name()
 	}
 
 	linkerMapStrat := &strategies.LinkerMapStrategy{}
-	linkerMapResult := linkerMapStrat.ScanWithEdges(s.ProjectRoot, s.Verbose)
+	linkerMapResult := &strategies.LinkerMapResult{}
+	var linkerMapRun *strategies.LinkerMapResult
+	if runEdges(linkerMapStrat.Name(), func(ctx context.Context, diags *strategies.Diagnostics, log *slog.Logger) int {
+		linkerMapRun = linkerMapStrat.ScanWithEdges(ctx, ix, diags, log)
+		return len(linkerMapRun.Components)
+	}) {
+		linkerMapResult = linkerMapRun
+	}
 
 	binaryEdgesStrat := &strategies.BinaryEdgesStrategy{}
-	binaryEdgesResult := binaryEdgesStrat.ScanWithEdges(s.ProjectRoot, s.Verbose)
-
-	// All other strategies (simple component lists, no graph edges)
-	otherStrategies := []Strategy{
-		&strategies.CompileCommandsStrategy{},
-		&strategies.BuildLogsStrategy{},
-		&strategies.CMakeStrategy{},
-		&strategies.VcpkgStrategy{},
-		&strategies.MesonStrategy{},
-		&strategies.HeadersStrategy{},
+	binaryEdgesResult := &strategies.BinaryEdgeResult{}
+	var binaryEdgesRun *strategies.BinaryEdgeResult
+	if runEdges(binaryEdgesStrat.Name(), func(ctx context.Context, diags *strategies.Diagnostics, log *slog.Logger) int {
+		binaryEdgesRun = binaryEdgesStrat.ScanWithEdges(ctx, ix, diags, log)
+		return len(binaryEdgesRun.Components)
+	}) {
+		binaryEdgesResult = binaryEdgesRun
 	}
 
-	// Optional strategies activated by flags
-	if s.CMakeConfigure {
-		otherStrategies = append(otherStrategies, &strategies.CMakeConfigureStrategy{})
+	// All other enabled strategies (simple component lists, no graph edges)
+	var otherStrategies []Strategy
+	for _, st := range registry {
+		switch st.Name() {
+		case conanGraphStrat.Name(), conanStrat.Name(), linkerMapStrat.Name(), binaryEdgesStrat.Name(), "ldd":
+			continue // run above and below, for their edges
+		}
+		if enabled[st.Name()] {
+			otherStrategies = append(otherStrategies, st)
+		}
 	}
 
-	// Channel capacity: base strategies + 3 edge strategies + optional ldd
-	capacity := len(otherStrategies) + 3
-	if s.UseLdd {
-		capacity++
-	}
-	resultCh := make(chan stratResult, capacity)
+	// Channel capacity: other strategies + 4 edge results + ldd
+	resultCh := make(chan stratResult, len(otherStrategies)+5)
 	var wg sync.WaitGroup
 
-	// Submit active conan results
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		resultCh <- stratResult{
-			name:       activeConanName,
-			components: activeConanResult.Components,
-		}
-	}()
-
-	// Submit linker map results
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		resultCh <- stratResult{
-			name:       linkerMapStrat.Name(),
-			components: linkerMapResult.Components,
-		}
-	}()
-
-	// Submit binary edges results
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		resultCh <- stratResult{
-			name:       binaryEdgesStrat.Name(),
-			components: binaryEdgesResult.Components,
+	// Submit the enabled edge strategies' results. A conan-graph failure is
+	// reported even when the plain conan result stands in for it.
+	var failedConanGraph string
+	if errs[conanGraphStrat.Name()] != nil && activeConanName != conanGraphStrat.Name() {
+		failedConanGraph = conanGraphStrat.Name()
+	}
+	edgeResults := []stratResult{
+		{order: 0, name: failedConanGraph, err: errs[conanGraphStrat.Name()]},
+		{order: 1, name: activeConanName, components: activeConanResult.Components, err: errs[activeConanName]},
+		{order: 2, name: linkerMapStrat.Name(), components: linkerMapResult.Components, err: errs[linkerMapStrat.Name()]},
+		{order: 3, name: binaryEdgesStrat.Name(), components: binaryEdgesResult.Components, err: errs[binaryEdgesStrat.Name()]},
+	}
+	for _, r := range edgeResults {
+		if r.name == "" || !enabled[r.name] {
+			continue
 		}
-	}()
+		wg.Add(1)
+		go func(r stratResult) {
+			defer wg.Done()
+			resultCh <- r
+		}(r)
+	}
 
 	// Submit all other strategies
-	for _, strat := range otherStrategies {
+	for i, strat := range otherStrategies {
 		wg.Add(1)
-		go func(st Strategy) {
+		go func(order int, st Strategy) {
 			defer wg.Done()
-			if s.Verbose {
-				fmt.Printf("[scanner] Running strategy: %s\n", st.Name())
-			}
-			comps, err := st.Scan(s.ProjectRoot, s.Verbose)
-			resultCh <- stratResult{name: st.Name(), components: comps, err: err}
-		}(strat)
+			var comps []*model.Component
+			err := run(st.Name(), func(ctx context.Context, diags *strategies.Diagnostics, log *slog.Logger) (int, error) {
+				var err error
+				comps, err = st.Scan(ctx, ix, diags, log)
+				return len(comps), err
+			})
+			resultCh <- stratResult{order: order, name: st.Name(), components: comps, err: err}
+		}(4+i, strat)
 	}
 
 	// LDD strategy: run synchronously here so we can also capture edges,
 	// then submit the components to the channel before closing it.
 	var lddEdges map[string][]string
-	if s.UseLdd {
-		lddStrat := &strategies.LddStrategy{}
-		lddResult := lddStrat.ScanWithEdges(s.ProjectRoot, s.Verbose)
+	lddStrat := &strategies.LddStrategy{}
+	if enabled[lddStrat.Name()] {
+		lddResult := &strategies.LddScanResult{}
+		var lddRun *strategies.LddScanResult
+		if runEdges(lddStrat.Name(), func(ctx context.Context, diags *strategies.Diagnostics, log *slog.Logger) int {
+			lddRun = lddStrat.ScanWithEdges(ctx, ix, diags, log)
+			return len(lddRun.Components)
+		}) {
+			lddResult = lddRun
+		}
 		lddEdges = lddResult.Edges
+		lddErr := errs[lddStrat.Name()]
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
 			resultCh <- stratResult{
+				order:      4 + len(otherStrategies),
 				name:       lddStrat.Name(),
 				components: lddResult.Components,
+				err:        lddErr,
 			}
 		}()
 	}
@@ -191,11 +396,30 @@ func (s *Scanner) Scan() (*Result, error) {
 	merged := map[string]*model.Component{}
 	var used, skipped []string
 
+	var results []stratResult
 	for r := range resultCh {
+		results = append(results, r)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].order < results[j].order })
+
+	if ctx.Err() != nil {
+		return nil, context.Cause(ctx)
+	}
+
+	// Remember the names each strategy found, for the direct-dependency pass
+	// below. They are taken before merging, which folds components together.
+	foundBy := map[string][]string{}
+	for _, r := range results {
+		if r.err != nil {
+			continue
+		}
+		for _, c := range r.components {
+			foundBy[r.name] = append(foundBy[r.name], c.Name)
+		}
+	}
+
+	for _, r := range results {
 		if r.err != nil {
-			if s.Verbose {
-				fmt.Printf("[scanner] Strategy %s error: %v\n", r.name, r.err)
-			}
 			skipped = append(skipped, r.name)
 			continue
 		}
@@ -204,6 +428,7 @@ func (s *Scanner) Scan() (*Result, error) {
 			continue
 		}
 		used = append(used, r.name)
+		s.recordSources(r.name, r.components)
 		for _, c := range r.components {
 			mergeComponent(merged, c)
 		}
@@ -214,6 +439,9 @@ func (s *Scanner) Scan() (*Result, error) {
 	for _, c := range merged {
 		allComponents = append(allComponents, c)
 	}
+	sort.Slice(allComponents, func(i, j int) bool {
+		return normalizeName(allComponents[i].Name) < normalizeName(allComponents[j].Name)
+	})
 	strategies.ScanVersionHints(allComponents, s.ProjectRoot)
 
 	// ---- Build Dependency Hierarchy ----
@@ -230,64 +458,61 @@ func (s *Scanner) Scan() (*Result, error) {
 	// A component is TRANSITIVE if it only appears in the conan.lock full graph
 	// but NOT in the project's own manifest files.
 
-	// Collect all "direct" names from all manifest strategies
-	allDirectNames := map[string]bool{}
+	// Collect all "direct" names from all manifest strategies, with the
+	// reasons each one counts as direct
+	allDirectNames := map[string][]string{}
+	markDirect := func(name, reason, source string) {
+		key := normalizeName(name)
+		allDirectNames[key] = appendUniqueStr(allDirectNames[key], reason+" ("+source+")")
+	}
 
 	// From Conan manifests (conanfile.txt/py) or conan-graph DirectNames
 	for name := range activeConanResult.DirectNames {
-		allDirectNames[normalizeName(name)] = true
-	}
-
-	// From vcpkg.json — run a quick vcpkg scan to get direct names
-	vcpkgStrat := &strategies.VcpkgStrategy{}
-	vcpkgComps, _ := vcpkgStrat.Scan(s.ProjectRoot, false)
-	for _, c := range vcpkgComps {
-		allDirectNames[normalizeName(c.Name)] = true
-	}
-
-	// From CMake find_package / FetchContent — these are direct
-	cmakeStrat := &strategies.CMakeStrategy{}
-	cmakeComps, _ := cmakeStrat.Scan(s.ProjectRoot, false)
-	for _, c := range cmakeComps {
-		allDirectNames[normalizeName(c.Name)] = true
+		markDirect(name, "declared in the project's Conan manifest", activeConanName)
 	}
 
-	// From compile_commands.json — external -I paths are direct (the project's build uses them)
-	ccStrat := &strategies.CompileCommandsStrategy{}
-	ccComps, _ := ccStrat.Scan(s.ProjectRoot, false)
-	for _, c := range ccComps {
-		allDirectNames[normalizeName(c.Name)] = true
+	// From the other manifest and build strategies, as far as they are
+	// enabled: vcpkg.json, CMake find_package / FetchContent,
+	// compile_commands.json external -I paths, build logs (link.txt, .tlog,
+	// ninja) and the project's own #includes. Their results from the scan
+	// above are reused rather than computed again.
+	directPasses := []struct {
+		strategy string
+		reason   string
+	}{
+		{"vcpkg", "declared in the project's vcpkg manifest"},
+		{"cmake", "referenced by the project's CMake files"},
+		{"compile_commands.json", "on the project's compiler command lines"},
+		{"build-logs", "linked by the project's build"},
+		{"header-scan", "included by the project's sources"},
 	}
-
-	// From build logs (link.txt, .tlog, ninja) — direct linker references
-	blStrat := &strategies.BuildLogsStrategy{}
-	blComps, _ := blStrat.Scan(s.ProjectRoot, false)
-	for _, c := range blComps {
-		allDirectNames[normalizeName(c.Name)] = true
-	}
-
-	// From header scan — the project's own source files include these
-	hStrat := &strategies.HeadersStrategy{}
-	hComps, _ := hStrat.Scan(s.ProjectRoot, false)
-	for _, c := range hComps {
-		allDirectNames[normalizeName(c.Name)] = true
+	for _, p := range directPasses {
+		for _, name := range foundBy[p.strategy] {
+			markDirect(name, p.reason, p.strategy)
+		}
 	}
 
-	// Merge all edge sources into a single map: normalizedName -> []childName
+	// Merge all edge sources into a single map: normalizedName -> []childName,
+	// remembering which strategies reported each edge.
 	allEdges := map[string][]string{}
-	mergeEdges := func(src map[string][]string) {
+	edgeSources := map[string]map[string][]string{}
+	mergeEdges := func(source string, src map[string][]string) {
 		for parent, children := range src {
 			pk := normalizeName(parent)
+			if edgeSources[pk] == nil {
+				edgeSources[pk] = map[string][]string{}
+			}
 			for _, child := range children {
 				allEdges[pk] = appendUniqueStr(allEdges[pk], child)
+				edgeSources[pk][child] = appendUniqueStr(edgeSources[pk][child], source)
 			}
 		}
 	}
-	mergeEdges(activeConanResult.Edges)
-	mergeEdges(linkerMapResult.Edges)
-	mergeEdges(binaryEdgesResult.Edges)
+	mergeEdges(activeConanName, activeConanResult.Edges)
+	mergeEdges(linkerMapStrat.Name(), linkerMapResult.Edges)
+	mergeEdges(binaryEdgesStrat.Name(), binaryEdgesResult.Edges)
 	if lddEdges != nil {
-		mergeEdges(lddEdges)
+		mergeEdges(lddStrat.Name(), lddEdges)
 	}
 
 	// Step 2: Apply Dependencies to each merged component (from all edge sources).
@@ -297,6 +522,7 @@ func (s *Scanner) Scan() (*Result, error) {
 			for _, child := range children {
 				c.Dependencies = appendUniqueStr(c.Dependencies, child)
 			}
+			c.DependencySources = edgeSources[key]
 		}
 	}
 
@@ -318,20 +544,84 @@ func (s *Scanner) Scan() (*Result, error) {
 	//      (i.e. nothing depends on it → it must be a root dependency of the project).
 	for _, c := range allComponents {
 		key := normalizeName(c.Name)
-		c.IsDirect = allDirectNames[key] || !referencedAsChild[key]
+		c.IsDirect = len(allDirectNames[key]) > 0 || !referencedAsChild[key]
+		c.DependencyTypeReason = dependencyTypeReason(c, allDirectNames[key], allComponents)
+	}
+
+	if s.Reproducible {
+		sort.Strings(used)
+		sort.Strings(skipped)
+		for _, c := range allComponents {
+			sort.Strings(c.Dependencies)
+			sort.Strings(c.IncludePaths)
+			sort.Strings(c.LinkLibraries)
+			sort.Slice(c.Artifacts, func(i, j int) bool { return c.Artifacts[i].Path < c.Artifacts[j].Path })
+			sortEvidence(c.Evidence)
+			sortSources(c.Sources)
+		}
 	}
 
 	// Step 5: Build the DependencyTree
 	tree := model.BuildDependencyTree(allComponents)
 
+	// Step 6: The project itself is the root of the graph, depending on
+	// every direct dependency.
+	project := s.projectComponent()
+	if project != nil {
+		for _, c := range allComponents {
+			if c.IsDirect {
+				project.Dependencies = append(project.Dependencies, c.Name)
+			}
+		}
+		sort.Strings(project.Dependencies)
+	}
+
+	var invocations []model.BuildInvocation
+	if s.CollectBuildInfo {
+		invocations = strategies.CollectBuildInvocations(ix)
+	}
+
 	return &Result{
+		Project:           project,
 		Components:        allComponents,
 		DependencyTree:    tree,
 		StrategiesUsed:    used,
 		StrategiesSkipped: skipped,
+		BuildInvocations:  invocations,
+		Diagnostics:       s.sortDiagnostics(diagnostics),
 	}, nil
 }
 
+// projectComponent returns the component describing the scanned project,
+// detected from its build files and overridden by ProjectName and
+// ProjectVersion. A version override without any detected or given name
+// names the project after its directory.
+func (s *Scanner) projectComponent() *model.Component {
+	project := strategies.DetectProject(s.ProjectRoot)
+	if project == nil {
+		if s.ProjectName == "" && s.ProjectVersion == "" {
+			return nil
+		}
+		project = &model.Component{
+			Name:            filepath.Base(s.ProjectRoot),
+			Version:         "unknown",
+			DetectionSource: "user",
+		}
+	}
+	if s.ProjectName != "" {
+		project.Name = s.ProjectName
+	}
+	if s.ProjectVersion != "" {
+		project.Version = s.ProjectVersion
+	}
+
+	project.PURL = "pkg:generic/" + strings.ToLower(project.Name)
+	if project.Version != "unknown" {
+		project.PURL += "@" + project.Version
+	}
+	return project
+}
+
 // normalizeName normalises a library name for deduplication:
 // lowercases and replaces underscores/hyphens/dots with a canonical separator.
 func normalizeName(name string) string {
@@ -342,7 +632,7 @@ func normalizeName(name string) string {
 }
 
 // mergeComponent merges a newly detected component into the accumulated map.
-// Higher-confidence sources (manifest > compiler > header) win on version.
+// Components are keyed by normalized name, so each library appears once.
 func mergeComponent(merged map[string]*model.Component, incoming *model.Component) {
 	key := normalizeName(incoming.Name)
 	existing, ok := merged[key]
@@ -350,7 +640,13 @@ func mergeComponent(merged map[string]*model.Component, incoming *model.Componen
 		merged[key] = incoming
 		return
 	}
+	MergeInto(existing, incoming)
+}
 
+// MergeInto folds incoming into existing, which describe the same library:
+// a known version replaces "unknown", the higher-confidence detection source
+// wins, and paths, libraries, edges, references and artifacts are unioned.
+func MergeInto(existing, incoming *model.Component) {
 	// Prefer known version over "unknown"
 	if existing.Version == "unknown" && incoming.Version != "unknown" {
 		existing.Version = incoming.Version
@@ -376,6 +672,114 @@ func mergeComponent(merged map[string]*model.Component, incoming *model.Componen
 	if existing.Description == "" && incoming.Description != "" {
 		existing.Description = incoming.Description
 	}
+
+	// Package metadata: the first source that declares it wins
+	if len(existing.Licenses) == 0 {
+		existing.Licenses = incoming.Licenses
+	}
+	if existing.Homepage == "" {
+		existing.Homepage = incoming.Homepage
+	}
+	for _, ref := range incoming.ExternalReferences {
+		if !containsRef(existing.ExternalReferences, ref) {
+			existing.ExternalReferences = append(existing.ExternalReferences, ref)
+		}
+	}
+
+	// Merge library files
+	for _, a := range incoming.Artifacts {
+		existing.AddArtifact(a)
+	}
+
+	// Merge detection evidence and the strategies that reported it
+	for _, e := range incoming.Evidence {
+		existing.AddEvidence(e)
+	}
+	for _, src := range incoming.Sources {
+		existing.AddSource(src)
+	}
+
+	// Merge dependency edges and their provenance
+	for _, d := range incoming.Dependencies {
+		existing.Dependencies = appendUniqueStr(existing.Dependencies, d)
+	}
+	for child, sources := range incoming.DependencySources {
+		if existing.DependencySources == nil {
+			existing.DependencySources = map[string][]string{}
+		}
+		for _, src := range sources {
+			existing.DependencySources[child] = appendUniqueStr(existing.DependencySources[child], src)
+		}
+	}
+	existing.IsDirect = existing.IsDirect || incoming.IsDirect
+}
+
+// dependencyTypeReason explains c.IsDirect: the manifest and build
+// references that make it direct, or else the components that depend on it.
+func dependencyTypeReason(c *model.Component, directReasons []string, all []*model.Component) string {
+	if len(directReasons) > 0 {
+		return strings.Join(directReasons, "; ")
+	}
+	key := normalizeName(c.Name)
+	var parents []string
+	for _, p := range all {
+		for _, child := range p.Dependencies {
+			if normalizeName(child) != key {
+				continue
+			}
+			parent := p.Name
+			if sources := p.DependencySources[child]; len(sources) > 0 {
+				parent += " (" + strings.Join(sources, ", ") + ")"
+			}
+			parents = append(parents, parent)
+			break
+		}
+	}
+	if len(parents) == 0 {
+		return "no other component depends on it"
+	}
+	sort.Strings(parents)
+	return "required by " + strings.Join(parents, ", ")
+}
+
+// sortEvidence orders evidence by strategy, file, line and text.
+func sortEvidence(ev []model.Evidence) {
+	sort.Slice(ev, func(i, j int) bool {
+		a, b := ev[i], ev[j]
+		if a.Strategy != b.Strategy {
+			return a.Strategy < b.Strategy
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Text < b.Text
+	})
+}
+
+// sortSources orders sources by strategy, file and version.
+func sortSources(sources []model.Source) {
+	sort.Slice(sources, func(i, j int) bool {
+		a, b := sources[i], sources[j]
+		if a.Strategy != b.Strategy {
+			return a.Strategy < b.Strategy
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Version < b.Version
+	})
+}
+
+func containsRef(refs []model.ExternalReference, ref model.ExternalReference) bool {
+	for _, r := range refs {
+		if r.Type == ref.Type && r.URL == ref.URL {
+			return true
+		}
+	}
+	return false
 }
 
 // sourceRank returns a priority score for a detection source.
@@ -411,3 +815,63 @@ func appendUniqueStr(slice []string, s string) []string {
 	}
 	return append(slice, s)
 }
+
+// strategyContext derives the context a strategy runs under, bounded by its
+// entry in StrategyTimeouts.
+func (s *Scanner) strategyContext(ctx context.Context, name string) (context.Context, context.CancelFunc) {
+	if d := s.StrategyTimeouts[name]; d > 0 {
+		return context.WithTimeoutCause(ctx, d, fmt.Errorf("%s timed out after %s", name, d))
+	}
+	return context.WithCancel(ctx)
+}
+
+// sortDiagnostics makes the files of diags relative to the project root and
+// sorts them by strategy, file and line.
+func (s *Scanner) sortDiagnostics(diags []model.Diagnostic) []model.Diagnostic {
+	for i, d := range diags {
+		diags[i].File = s.relPath(d.File)
+	}
+	sort.SliceStable(diags, func(i, j int) bool {
+		a, b := diags[i], diags[j]
+		if a.Strategy != b.Strategy {
+			return a.Strategy < b.Strategy
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+	return diags
+}
+
+// recordSources notes on each component of one strategy's result that the
+// strategy reported it, from the files its evidence names and at the version
+// it found. It runs before merging, which keeps a single version.
+func (s *Scanner) recordSources(strategy string, comps []*model.Component) {
+	for _, c := range comps {
+		var files []string
+		for _, e := range c.Evidence {
+			if e.Strategy == strategy {
+				files = appendUniqueStr(files, e.File)
+			}
+		}
+		if len(files) == 0 {
+			files = []string{""}
+		}
+		for _, f := range files {
+			c.AddSource(model.Source{Strategy: strategy, File: s.relPath(f), Version: c.Version})
+		}
+	}
+}
+
+// relPath returns path relative to the project root, with forward slashes,
+// when it lies inside the root; other paths are returned unchanged.
+func (s *Scanner) relPath(path string) string {
+	if path == "" {
+		return ""
+	}
+	if rel, err := filepath.Rel(s.ProjectRoot, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
@@ -0,0 +1,76 @@
+package strategies
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/StinkyLord/cpp-sbom-builder/internal/model"
+)
+
+// Several strategies see the same library file (a .so found by binary-edges is
+// often also a LOAD line in a map file and an absolute path in link.txt), so
+// digests are cached per path for as long as the file is unchanged.
+var (
+	artifactMu    sync.Mutex
+	artifactCache = map[string]cachedArtifact{}
+)
+
+type cachedArtifact struct {
+	modTime  time.Time
+	artifact model.Artifact
+}
+
+// addArtifact hashes the library file at path and records it on c. Paths that
+// do not name a readable regular file on this machine (map files and build
+// logs are often copied from another host) are silently skipped.
+func addArtifact(c *model.Component, path string) {
+	if a, ok := hashArtifact(path); ok {
+		c.AddArtifact(a)
+	}
+}
+
+func hashArtifact(path string) (model.Artifact, bool) {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return model.Artifact{}, false
+	}
+
+	artifactMu.Lock()
+	cached, ok := artifactCache[path]
+	artifactMu.Unlock()
+	if ok && cached.modTime.Equal(info.ModTime()) && cached.artifact.Size == info.Size() {
+		return cached.artifact, true
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return model.Artifact{}, false
+	}
+	defer f.Close()
+
+	h256 := sha256.New()
+	h512 := sha512.New()
+	size, err := io.Copy(io.MultiWriter(h256, h512), f)
+	if err != nil {
+		return model.Artifact{}, false
+	}
+
+	a := model.Artifact{
+		Path: path,
+		Size: size,
+		Hashes: []model.Hash{
+			{Algorithm: "SHA-256", Value: hex.EncodeToString(h256.Sum(nil))},
+			{Algorithm: "SHA-512", Value: hex.EncodeToString(h512.Sum(nil))},
+		},
+	}
+
+	artifactMu.Lock()
+	artifactCache[path] = cachedArtifact{modTime: info.ModTime(), artifact: a}
+	artifactMu.Unlock()
+	return a, true
+}
@@ -18,9 +18,10 @@ package strategies
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"debug/elf"
 	"debug/pe"
-	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -42,49 +43,44 @@ type BinaryEdgeResult struct {
 	Edges map[string][]string
 }
 
-func (s *BinaryEdgesStrategy) Scan(projectRoot string, verbose bool) ([]*model.Component, error) {
-	r := s.ScanWithEdges(projectRoot, verbose)
-	return r.Components, nil
+func (s *BinaryEdgesStrategy) Scan(ctx context.Context, ix *FileIndex, diags *Diagnostics, log *slog.Logger) ([]*model.Component, error) {
+	r := s.ScanWithEdges(ctx, ix, diags, log)
+	return r.Components, ctx.Err()
 }
 
 // ScanWithEdges returns both components and the dependency edges.
-func (s *BinaryEdgesStrategy) ScanWithEdges(projectRoot string, verbose bool) *BinaryEdgeResult {
+func (s *BinaryEdgesStrategy) ScanWithEdges(ctx context.Context, ix *FileIndex, diags *Diagnostics, log *slog.Logger) *BinaryEdgeResult {
+	projectRoot := ix.Root
 	result := &BinaryEdgeResult{
 		Edges: map[string][]string{},
 	}
 
 	seen := map[string]*model.Component{}
 
-	_ = filepath.WalkDir(projectRoot, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			return nil
+	libraries := ix.Select(func(f File) bool {
+		if f.InDir(isNodeModules) {
+			return false
 		}
-		if d.IsDir() {
-			name := d.Name()
-			if strings.HasPrefix(name, ".git") || name == "node_modules" {
-				return filepath.SkipDir
-			}
-			return nil
+		switch strings.ToLower(filepath.Ext(f.Name)) {
+		case ".so", ".dll", ".lib":
+			return true
 		}
-
-		ext := strings.ToLower(filepath.Ext(d.Name()))
-		switch ext {
-		case ".so":
-			// Also match versioned .so files like libssl.so.3
-			s.processELF(path, projectRoot, seen, result.Edges, verbose)
+		// Versioned .so files (e.g. libssl.so.3.1.4)
+		return strings.Contains(f.Name, ".so.")
+	})
+	for _, f := range libraries {
+		if ctx.Err() != nil {
+			break
+		}
+		switch strings.ToLower(filepath.Ext(f.Name)) {
 		case ".dll":
-			s.processPE(path, projectRoot, seen, result.Edges, verbose)
+			s.processPE(f.Path, projectRoot, seen, result.Edges, diags, log)
 		case ".lib":
-			s.processMSVCLib(path, projectRoot, seen, result.Edges, verbose)
+			s.processMSVCLib(f.Path, projectRoot, seen, result.Edges, diags, log)
 		default:
-			// Check for versioned .so files (e.g. libssl.so.3.1.4)
-			base := d.Name()
-			if idx := strings.Index(base, ".so."); idx != -1 {
-				s.processELF(path, projectRoot, seen, result.Edges, verbose)
-			}
+			s.processELF(f.Path, projectRoot, seen, result.Edges, diags, log)
 		}
-		return nil
-	})
+	}
 
 	for _, c := range seen {
 		result.Components = append(result.Components, c)
@@ -98,7 +94,8 @@ func (s *BinaryEdgesStrategy) processELF(
 	path, projectRoot string,
 	seen map[string]*model.Component,
 	edges map[string][]string,
-	verbose bool,
+	diags *Diagnostics,
+	log *slog.Logger,
 ) {
 	// Only process external libraries (outside project root)
 	if !isExternalPath(path, projectRoot) {
@@ -107,21 +104,25 @@ func (s *BinaryEdgesStrategy) processELF(
 
 	f, err := elf.Open(path)
 	if err != nil {
-		return // not a valid ELF file
+		// Not necessarily a problem: some .so files are linker scripts
+		diags.Infof(path, "not an ELF file, skipped: %v", unwrapPathError(err))
+		return
 	}
 	defer f.Close()
 
 	needed, err := f.DynString(elf.DT_NEEDED)
-	if err != nil || len(needed) == 0 {
+	if err != nil {
+		diags.Warnf(path, "cannot read the ELF dynamic section: %v", err)
 		return
 	}
-
-	if verbose {
-		fmt.Printf("  [binary-edges] ELF %s → needs: %v\n", filepath.Base(path), needed)
+	if len(needed) == 0 {
+		return
 	}
 
+	log.Debug("ELF needs", "file", path, "needed", needed)
+
 	// Map this .so file to a package
-	parentPkg := libNameToPackage(filepath.Base(path))
+	matched, parentPkg := matchLibName(filepath.Base(path))
 	if parentPkg == nil {
 		return
 	}
@@ -140,10 +141,12 @@ func (s *BinaryEdgesStrategy) processELF(
 		}
 		seen[parentPkg.Name] = c
 	}
+	addArtifact(seen[parentPkg.Name], path)
+	seen[parentPkg.Name].AddEvidence(evidence(s.Name(), path, 0, filepath.Base(path), matched))
 
 	// Map each needed library to a package and record the edge
 	for _, dep := range needed {
-		childPkg := libNameToPackage(dep)
+		matched, childPkg := matchLibName(dep)
 		if childPkg == nil {
 			continue
 		}
@@ -165,6 +168,7 @@ func (s *BinaryEdgesStrategy) processELF(
 			}
 			seen[childPkg.Name] = c
 		}
+		seen[childPkg.Name].AddEvidence(evidence(s.Name(), path, 0, "DT_NEEDED "+dep, matched))
 
 		edges[parentPkg.Name] = appendUnique(edges[parentPkg.Name], childPkg.Name)
 	}
@@ -176,7 +180,8 @@ func (s *BinaryEdgesStrategy) processPE(
 	path, projectRoot string,
 	seen map[string]*model.Component,
 	edges map[string][]string,
-	verbose bool,
+	diags *Diagnostics,
+	log *slog.Logger,
 ) {
 	if !isExternalPath(path, projectRoot) {
 		return
@@ -184,6 +189,7 @@ func (s *BinaryEdgesStrategy) processPE(
 
 	f, err := pe.Open(path)
 	if err != nil {
+		diags.Warnf(path, "not a PE file, skipped: %v", unwrapPathError(err))
 		return
 	}
 	defer f.Close()
@@ -205,11 +211,9 @@ func (s *BinaryEdgesStrategy) processPE(
 		return
 	}
 
-	if verbose {
-		fmt.Printf("  [binary-edges] PE %s → imports: %v\n", filepath.Base(path), importedDLLs)
-	}
+	log.Debug("PE imports", "file", path, "dlls", importedDLLs)
 
-	parentPkg := libNameToPackage(filepath.Base(path))
+	matched, parentPkg := matchLibName(filepath.Base(path))
 	if parentPkg == nil {
 		return
 	}
@@ -227,9 +231,11 @@ func (s *BinaryEdgesStrategy) processPE(
 		}
 		seen[parentPkg.Name] = c
 	}
+	addArtifact(seen[parentPkg.Name], path)
+	seen[parentPkg.Name].AddEvidence(evidence(s.Name(), path, 0, filepath.Base(path), matched))
 
 	for _, dll := range importedDLLs {
-		childPkg := libNameToPackage(dll)
+		matched, childPkg := matchLibName(dll)
 		if childPkg == nil || childPkg.Name == parentPkg.Name {
 			continue
 		}
@@ -243,6 +249,7 @@ func (s *BinaryEdgesStrategy) processPE(
 			}
 			seen[childPkg.Name] = c
 		}
+		seen[childPkg.Name].AddEvidence(evidence(s.Name(), path, 0, "imports "+dll, matched))
 		edges[parentPkg.Name] = appendUnique(edges[parentPkg.Name], childPkg.Name)
 	}
 }
@@ -264,7 +271,8 @@ func (s *BinaryEdgesStrategy) processMSVCLib(
 	path, projectRoot string,
 	seen map[string]*model.Component,
 	edges map[string][]string,
-	verbose bool,
+	diags *Diagnostics,
+	log *slog.Logger,
 ) {
 	if !isExternalPath(path, projectRoot) {
 		return
@@ -272,6 +280,7 @@ func (s *BinaryEdgesStrategy) processMSVCLib(
 
 	data, err := os.ReadFile(path)
 	if err != nil {
+		diags.ReadError(path, err)
 		return
 	}
 
@@ -290,7 +299,7 @@ func (s *BinaryEdgesStrategy) processMSVCLib(
 		return
 	}
 
-	parentPkg := libNameToPackage(filepath.Base(path))
+	parentMatched, parentPkg := matchLibName(filepath.Base(path))
 	if parentPkg == nil {
 		return
 	}
@@ -298,6 +307,7 @@ func (s *BinaryEdgesStrategy) processMSVCLib(
 	scanner := bufio.NewScanner(bytes.NewReader(chunk))
 	scanner.Buffer(make([]byte, 4096), 4096)
 	var deps []string
+	found := map[string]model.Evidence{}
 	for scanner.Scan() {
 		line := scanner.Text()
 		for _, m := range reMSVCDefaultLib.FindAllStringSubmatch(line, -1) {
@@ -306,9 +316,12 @@ func (s *BinaryEdgesStrategy) processMSVCLib(
 			if isCRTLib(depName) {
 				continue
 			}
-			childPkg := libNameToPackage(depName)
+			matched, childPkg := matchLibName(depName)
 			if childPkg != nil && childPkg.Name != parentPkg.Name {
 				deps = append(deps, childPkg.Name)
+				if _, ok := found[childPkg.Name]; !ok {
+					found[childPkg.Name] = evidence(s.Name(), path, 0, m[0], matched)
+				}
 			}
 		}
 	}
@@ -317,9 +330,7 @@ func (s *BinaryEdgesStrategy) processMSVCLib(
 		return
 	}
 
-	if verbose {
-		fmt.Printf("  [binary-edges] MSVC lib %s → DEFAULTLIB: %v\n", filepath.Base(path), deps)
-	}
+	log.Debug("MSVC lib default libraries", "file", path, "defaultlib", deps)
 
 	if _, ok := seen[parentPkg.Name]; !ok {
 		c := &model.Component{
@@ -334,6 +345,8 @@ func (s *BinaryEdgesStrategy) processMSVCLib(
 		}
 		seen[parentPkg.Name] = c
 	}
+	addArtifact(seen[parentPkg.Name], path)
+	seen[parentPkg.Name].AddEvidence(evidence(s.Name(), path, 0, filepath.Base(path), parentMatched))
 
 	for _, childName := range deps {
 		if _, ok := seen[childName]; !ok {
@@ -348,6 +361,9 @@ func (s *BinaryEdgesStrategy) processMSVCLib(
 				}
 			}
 		}
+		if c, ok := seen[childName]; ok {
+			c.AddEvidence(found[childName])
+		}
 		edges[parentPkg.Name] = appendUnique(edges[parentPkg.Name], childName)
 	}
 }
@@ -357,6 +373,13 @@ func (s *BinaryEdgesStrategy) processMSVCLib(
 // libNameToPackage maps a library filename (e.g. "libssl.so.3", "ssl.dll", "libssl.a")
 // to a fingerprint package entry.
 func libNameToPackage(libName string) *fingerprints.LibraryFingerprint {
+	_, fp := matchLibName(libName)
+	return fp
+}
+
+// matchLibName is libNameToPackage that also returns the string the
+// fingerprint matched, for recording as evidence.
+func matchLibName(libName string) (string, *fingerprints.LibraryFingerprint) {
 	// Strip versioned suffix: libssl.so.3.1.4 -> libssl.so -> libssl
 	base := strings.ToLower(libName)
 
@@ -373,13 +396,13 @@ func libNameToPackage(libName string) *fingerprints.LibraryFingerprint {
 
 	// Try the cleaned name
 	if fp := fingerprints.MatchLibrary(base); fp != nil {
-		return fp
+		return base, fp
 	}
 	// Try the original name
 	if fp := fingerprints.MatchLibrary(libName); fp != nil {
-		return fp
+		return libName, fp
 	}
-	return nil
+	return "", nil
 }
 
 // isCRTLib returns true for well-known MSVC C runtime library names that
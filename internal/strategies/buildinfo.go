@@ -0,0 +1,107 @@
+package strategies
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/StinkyLord/cpp-sbom-builder/internal/model"
+)
+
+// CollectBuildInvocations recovers the compiler and linker command lines the
+// project's build system recorded:
+//   - every entry of every compile_commands.json (compile invocations)
+//   - every CMakeFiles/<target>/link.txt (link invocations)
+//
+// Unlike the detection strategies it keeps the full command line, so output
+// formats that describe the build itself (SPDX 3.0 Build profile) can use it.
+func CollectBuildInvocations(ix *FileIndex) []model.BuildInvocation {
+	var invocations []model.BuildInvocation
+
+	for _, ccPath := range findCompileCommands(ix) {
+		invocations = append(invocations, parseCompileInvocations(ccPath)...)
+	}
+
+	for _, f := range ix.Named("link.txt") {
+		if inv := parseLinkInvocation(f.Path); inv != nil {
+			invocations = append(invocations, *inv)
+		}
+	}
+
+	return invocations
+}
+
+func parseCompileInvocations(ccPath string) []model.BuildInvocation {
+	data, err := os.ReadFile(ccPath)
+	if err != nil {
+		return nil
+	}
+	var commands []compileCommand
+	if err := json.Unmarshal(data, &commands); err != nil {
+		return nil
+	}
+
+	invocations := make([]model.BuildInvocation, 0, len(commands))
+	for _, cmd := range commands {
+		args := cmd.Arguments
+		if len(args) == 0 {
+			args = strings.Fields(cmd.Command)
+		}
+		if len(args) == 0 {
+			continue
+		}
+		invocations = append(invocations, model.BuildInvocation{
+			Kind:       "compile",
+			Arguments:  args,
+			Directory:  cmd.Directory,
+			Input:      cmd.File,
+			Output:     outputArgument(args),
+			SourceFile: ccPath,
+		})
+	}
+	return invocations
+}
+
+func parseLinkInvocation(path string) *model.BuildInvocation {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	args := strings.Fields(string(data))
+	if len(args) == 0 {
+		return nil
+	}
+
+	// CMake runs link.txt from the build directory that contains CMakeFiles/.
+	dir := filepath.Dir(path)
+	if idx := strings.LastIndex(filepath.ToSlash(dir), "/CMakeFiles/"); idx != -1 {
+		dir = dir[:idx]
+	}
+
+	return &model.BuildInvocation{
+		Kind:       "link",
+		Arguments:  args,
+		Directory:  dir,
+		Output:     outputArgument(args),
+		SourceFile: path,
+	}
+}
+
+// outputArgument returns the output file named by -o <file>, -o<file>,
+// /Fo<file> or /OUT:<file>, or "" if none is present.
+func outputArgument(args []string) string {
+	for i, arg := range args {
+		switch {
+		case arg == "-o" && i+1 < len(args):
+			return args[i+1]
+		case strings.HasPrefix(arg, "-o") && len(arg) > 2:
+			return arg[2:]
+		case strings.HasPrefix(arg, "/Fo") && len(arg) > 3:
+			return arg[3:]
+		case strings.HasPrefix(strings.ToUpper(arg), "/OUT:"):
+			return arg[5:]
+		}
+	}
+	return ""
+}
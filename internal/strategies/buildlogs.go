@@ -2,12 +2,14 @@ package strategies
 
 import (
 	"bufio"
-	"fmt"
+	"context"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
 
+	"github.com/StinkyLord/cpp-sbom-builder/internal/fingerprints"
 	"github.com/StinkyLord/cpp-sbom-builder/internal/model"
 )
 
@@ -39,58 +41,55 @@ var reNinjaLib = regexp.MustCompile(`(?i)\s-l([^\s\\]+)`)
 // reMakefileLib matches -l flags in Makefile lines
 var reMakefileLib = regexp.MustCompile(`(?i)\s-l([^\s\\]+)`)
 
-func (s *BuildLogsStrategy) Scan(projectRoot string, verbose bool) ([]*model.Component, error) {
-	externalIncludes := map[string]bool{}
-	externalLibs := map[string]bool{}
-	externalLibPaths := map[string]bool{}
+func (s *BuildLogsStrategy) Scan(ctx context.Context, ix *FileIndex, diags *Diagnostics, log *slog.Logger) ([]*model.Component, error) {
+	projectRoot := ix.Root
+	externalIncludes := sightings{}
+	externalLibs := sightings{}
+	externalLibPaths := sightings{}
 
-	_ = filepath.WalkDir(projectRoot, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			return nil
-		}
-		if d.IsDir() {
-			name := d.Name()
-			if strings.HasPrefix(name, ".git") {
-				return filepath.SkipDir
-			}
-			return nil
+	logs := ix.Select(func(f File) bool {
+		lname := strings.ToLower(f.Name)
+		return lname == "link.txt" || strings.HasSuffix(lname, ".tlog") || lname == "build.ninja" ||
+			lname == "makefile" || lname == "gnumakefile"
+	})
+	for _, f := range logs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
 		}
-
-		name := d.Name()
-		lname := strings.ToLower(name)
+		path := f.Path
+		lname := strings.ToLower(f.Name)
 
 		switch {
 		case lname == "link.txt":
 			// CMakeFiles/<target>/link.txt
-			parseLinkTxt(path, projectRoot, externalLibs, externalLibPaths, externalIncludes, verbose)
+			parseLinkTxt(path, projectRoot, externalLibs, externalLibPaths, externalIncludes, diags, log)
 
 		case strings.HasSuffix(lname, ".tlog"):
 			// MSBuild tracking log
-			parseTlog(path, projectRoot, externalLibPaths, verbose)
+			parseTlog(path, projectRoot, externalLibPaths, diags, log)
 
 		case lname == "build.ninja":
-			parseNinja(path, projectRoot, externalLibs, externalIncludes, verbose)
+			parseNinja(path, projectRoot, externalLibs, externalIncludes, diags, log)
 
 		case lname == "makefile" || lname == "gnumakefile":
-			parseMakefile(path, projectRoot, externalLibs, externalIncludes, verbose)
+			parseMakefile(path, projectRoot, externalLibs, externalIncludes, diags, log)
 		}
-		return nil
-	})
+	}
 
 	// Merge all sources into components
-	allIncludes := map[string]bool{}
-	for k := range externalIncludes {
-		allIncludes[k] = true
+	allIncludes := sightings{}
+	for k, at := range externalIncludes {
+		allIncludes.add(k, at.File, at.Line)
 	}
-	for k := range externalLibPaths {
+	for k, at := range externalLibPaths {
 		// Treat the directory of the lib path as an include hint
-		allIncludes[filepath.ToSlash(filepath.Dir(k))] = true
+		allIncludes.add(filepath.ToSlash(filepath.Dir(k)), at.File, at.Line)
 	}
 
 	components := buildComponentsFromPaths(allIncludes, externalLibs, s.Name())
 
 	// Also try to match raw lib paths
-	for libPath := range externalLibPaths {
+	for libPath, at := range externalLibPaths {
 		found := false
 		for _, c := range components {
 			for _, ll := range c.LinkLibraries {
@@ -106,7 +105,7 @@ func (s *BuildLogsStrategy) Scan(projectRoot string, verbose bool) ([]*model.Com
 		if !found {
 			// Try to match by path
 			extra := buildComponentsFromPaths(
-				map[string]bool{filepath.ToSlash(libPath): true},
+				sightings{filepath.ToSlash(libPath): at},
 				nil,
 				s.Name(),
 			)
@@ -114,17 +113,34 @@ func (s *BuildLogsStrategy) Scan(projectRoot string, verbose bool) ([]*model.Com
 		}
 	}
 
+	// Hash every library file the link commands name, so the SBOM records
+	// exactly which binary was linked.
+	for libPath := range externalLibPaths {
+		fp := fingerprints.MatchLibrary(libPath)
+		if fp == nil {
+			fp = libNameToPackage(filepath.Base(libPath))
+		}
+		if fp == nil {
+			continue
+		}
+		for _, c := range components {
+			if c.Name == fp.Name {
+				addArtifact(c, filepath.FromSlash(libPath))
+				break
+			}
+		}
+	}
+
 	return components, nil
 }
 
-func parseLinkTxt(path, projectRoot string, libs, libPaths, includes map[string]bool, verbose bool) {
+func parseLinkTxt(path, projectRoot string, libs, libPaths, includes sightings, diags *Diagnostics, log *slog.Logger) {
 	data, err := os.ReadFile(path)
 	if err != nil {
+		diags.ReadError(path, err)
 		return
 	}
-	if verbose {
-		fmt.Printf("  [build-logs] Parsing link.txt: %s\n", path)
-	}
+	log.Debug("parsing link.txt", "file", path)
 	content := string(data)
 
 	for _, m := range reLinkTxtLib.FindAllStringSubmatch(content, -1) {
@@ -135,94 +151,106 @@ func parseLinkTxt(path, projectRoot string, libs, libPaths, includes map[string]
 			lib = m[2]
 		}
 		if lib != "" {
-			libs[lib] = true
+			libs.add(lib, path, lineOf(content, m[0]))
 		}
 	}
 
 	for _, m := range reLinkTxtLibPath.FindAllStringSubmatch(content, -1) {
 		if len(m) > 1 && isExternalPath(m[1], projectRoot) {
-			libPaths[filepath.ToSlash(m[1])] = true
+			libPaths.add(filepath.ToSlash(m[1]), path, lineOf(content, m[0]))
 		}
 	}
 
 	for _, m := range reLinkTxtInclude.FindAllStringSubmatch(content, -1) {
 		if len(m) > 1 && isExternalPath(m[1], projectRoot) {
-			includes[filepath.ToSlash(m[1])] = true
+			includes.add(filepath.ToSlash(m[1]), path, lineOf(content, m[0]))
 		}
 	}
 }
 
-func parseTlog(path, projectRoot string, libPaths map[string]bool, verbose bool) {
+func parseTlog(path, projectRoot string, libPaths sightings, diags *Diagnostics, log *slog.Logger) {
 	f, err := os.Open(path)
 	if err != nil {
+		diags.ReadError(path, err)
 		return
 	}
 	defer f.Close()
-	if verbose {
-		fmt.Printf("  [build-logs] Parsing tlog: %s\n", path)
-	}
+	log.Debug("parsing tlog", "file", path)
 
 	scanner := bufio.NewScanner(f)
+	lineNo := 0
 	for scanner.Scan() {
+		lineNo++
 		line := scanner.Text()
 		for _, m := range reTlogLibPath.FindAllStringSubmatch(line, -1) {
 			if len(m) > 1 && isExternalPath(m[1], projectRoot) {
-				libPaths[filepath.ToSlash(m[1])] = true
+				libPaths.add(filepath.ToSlash(m[1]), path, lineNo)
 			}
 		}
 	}
+	if err := scanner.Err(); err != nil {
+		diags.Warnf(path, "stopped reading early: %v", err)
+	}
 }
 
-func parseNinja(path, projectRoot string, libs, includes map[string]bool, verbose bool) {
+func parseNinja(path, projectRoot string, libs, includes sightings, diags *Diagnostics, log *slog.Logger) {
 	f, err := os.Open(path)
 	if err != nil {
+		diags.ReadError(path, err)
 		return
 	}
 	defer f.Close()
-	if verbose {
-		fmt.Printf("  [build-logs] Parsing build.ninja: %s\n", path)
-	}
+	log.Debug("parsing build.ninja", "file", path)
 
 	scanner := bufio.NewScanner(f)
+	lineNo := 0
 	for scanner.Scan() {
+		lineNo++
 		line := scanner.Text()
 		// Link flags
 		for _, m := range reNinjaLib.FindAllStringSubmatch(line, -1) {
 			if len(m) > 1 {
-				libs[m[1]] = true
+				libs.add(m[1], path, lineNo)
 			}
 		}
 		// Include flags
 		for _, m := range reLinkTxtInclude.FindAllStringSubmatch(line, -1) {
 			if len(m) > 1 && isExternalPath(m[1], projectRoot) {
-				includes[filepath.ToSlash(m[1])] = true
+				includes.add(filepath.ToSlash(m[1]), path, lineNo)
 			}
 		}
 	}
+	if err := scanner.Err(); err != nil {
+		diags.Warnf(path, "stopped reading early: %v", err)
+	}
 }
 
-func parseMakefile(path, projectRoot string, libs, includes map[string]bool, verbose bool) {
+func parseMakefile(path, projectRoot string, libs, includes sightings, diags *Diagnostics, log *slog.Logger) {
 	f, err := os.Open(path)
 	if err != nil {
+		diags.ReadError(path, err)
 		return
 	}
 	defer f.Close()
-	if verbose {
-		fmt.Printf("  [build-logs] Parsing Makefile: %s\n", path)
-	}
+	log.Debug("parsing Makefile", "file", path)
 
 	scanner := bufio.NewScanner(f)
+	lineNo := 0
 	for scanner.Scan() {
+		lineNo++
 		line := scanner.Text()
 		for _, m := range reMakefileLib.FindAllStringSubmatch(line, -1) {
 			if len(m) > 1 {
-				libs[m[1]] = true
+				libs.add(m[1], path, lineNo)
 			}
 		}
 		for _, m := range reLinkTxtInclude.FindAllStringSubmatch(line, -1) {
 			if len(m) > 1 && isExternalPath(m[1], projectRoot) {
-				includes[filepath.ToSlash(m[1])] = true
+				includes.add(filepath.ToSlash(m[1]), path, lineNo)
 			}
 		}
 	}
+	if err := scanner.Err(); err != nil {
+		diags.Warnf(path, "stopped reading early: %v", err)
+	}
 }
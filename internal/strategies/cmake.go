@@ -2,7 +2,8 @@ package strategies
 
 import (
 	"bufio"
-	"fmt"
+	"context"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -46,7 +47,8 @@ var reCMakeProjectVersion = regexp.MustCompile(`(?i)project\s*\([^)]*VERSION\s+(
 // reCMakeGitTag matches GIT_TAG in FetchContent blocks
 var reCMakeGitTag = regexp.MustCompile(`(?i)GIT_TAG\s+([^\s)]+)`)
 
-func (s *CMakeStrategy) Scan(projectRoot string, verbose bool) ([]*model.Component, error) {
+func (s *CMakeStrategy) Scan(ctx context.Context, ix *FileIndex, diags *Diagnostics, log *slog.Logger) ([]*model.Component, error) {
+	projectRoot := ix.Root
 	seen := map[string]*model.Component{}
 	versions := map[string]string{} // library name (lower) -> version
 
@@ -63,32 +65,21 @@ func (s *CMakeStrategy) Scan(projectRoot string, verbose bool) ([]*model.Compone
 		if _, err := os.Stat(cf); err != nil {
 			continue
 		}
-		if verbose {
-			fmt.Printf("  [cmake] Parsing CMakeCache.txt: %s\n", cf)
-		}
-		parseCMakeCache(cf, projectRoot, seen, versions, verbose)
+		log.Debug("parsing CMakeCache.txt", "file", cf)
+		parseCMakeCache(cf, projectRoot, seen, versions, diags, log)
 	}
 
-	// Second pass: walk all CMakeLists.txt files
-	_ = filepath.WalkDir(projectRoot, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			return nil
-		}
-		if d.IsDir() {
-			name := d.Name()
-			if strings.HasPrefix(name, ".git") || name == "node_modules" {
-				return filepath.SkipDir
-			}
-			return nil
+	// Second pass: all CMakeLists.txt files
+	for _, f := range ix.Named("CMakeLists.txt") {
+		if err := ctx.Err(); err != nil {
+			return nil, err
 		}
-		if strings.EqualFold(d.Name(), "CMakeLists.txt") {
-			if verbose {
-				fmt.Printf("  [cmake] Parsing CMakeLists.txt: %s\n", path)
-			}
-			parseCMakeLists(path, seen, versions, verbose)
+		if f.InDir(isNodeModules) {
+			continue
 		}
-		return nil
-	})
+		log.Debug("parsing CMakeLists.txt", "file", f.Path)
+		parseCMakeLists(f.Path, seen, versions, diags, log)
+	}
 
 	// Apply collected versions
 	for name, c := range seen {
@@ -110,15 +101,18 @@ func (s *CMakeStrategy) Scan(projectRoot string, verbose bool) ([]*model.Compone
 	return result, nil
 }
 
-func parseCMakeCache(path, projectRoot string, seen map[string]*model.Component, versions map[string]string, verbose bool) {
+func parseCMakeCache(path, projectRoot string, seen map[string]*model.Component, versions map[string]string, diags *Diagnostics, log *slog.Logger) {
 	f, err := os.Open(path)
 	if err != nil {
+		diags.ReadError(path, err)
 		return
 	}
 	defer f.Close()
 
 	scanner := bufio.NewScanner(f)
+	lineNo := 0
 	for scanner.Scan() {
+		lineNo++
 		line := strings.TrimSpace(scanner.Text())
 		if strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
 			continue
@@ -143,14 +137,17 @@ func parseCMakeCache(path, projectRoot string, seen map[string]*model.Component,
 			if !isExternalPath(dirPath, projectRoot) {
 				continue
 			}
+			matched := libPrefix
 			fp := fingerprints.MatchLibrary(libPrefix)
 			if fp == nil {
+				matched = dirPath
 				fp = fingerprints.MatchLibrary(dirPath)
 			}
 			if fp == nil {
 				continue
 			}
-			addOrUpdate(seen, fp, dirPath, "", "cmake")
+			c := addOrUpdate(seen, fp, dirPath, "", "cmake")
+			c.AddEvidence(evidence("cmake", path, lineNo, line, matched))
 		}
 
 		// Library path entries
@@ -160,32 +157,40 @@ func parseCMakeCache(path, projectRoot string, seen map[string]*model.Component,
 			if libPath == "" || strings.HasSuffix(libPath, "-NOTFOUND") {
 				continue
 			}
+			matched := libPrefix
 			fp := fingerprints.MatchLibrary(libPrefix)
 			if fp == nil {
+				matched = libPath
 				fp = fingerprints.MatchLibrary(libPath)
 			}
 			if fp == nil {
 				continue
 			}
-			addOrUpdate(seen, fp, "", filepath.Base(libPath), "cmake")
+			c := addOrUpdate(seen, fp, "", filepath.Base(libPath), "cmake")
+			c.AddEvidence(evidence("cmake", path, lineNo, line, matched))
 		}
 	}
+	if err := scanner.Err(); err != nil {
+		diags.Warnf(path, "stopped reading early: %v", err)
+	}
 }
 
-func parseCMakeLists(path string, seen map[string]*model.Component, versions map[string]string, verbose bool) {
+func parseCMakeLists(path string, seen map[string]*model.Component, versions map[string]string, diags *Diagnostics, log *slog.Logger) {
 	data, err := os.ReadFile(path)
 	if err != nil {
+		diags.ReadError(path, err)
 		return
 	}
 	content := string(data)
 
 	// find_package(Foo ...)
-	for _, m := range reCMakeFindPackage.FindAllStringSubmatch(content, -1) {
-		pkgName := m[1]
+	for _, loc := range reCMakeFindPackage.FindAllStringSubmatchIndex(content, -1) {
+		pkgName := content[loc[2]:loc[3]]
 		// Skip CMake built-in modules
 		if isCMakeBuiltin(pkgName) {
 			continue
 		}
+		matched := pkgName
 		fp := fingerprints.MatchLibrary(pkgName)
 		if fp == nil {
 			// Create a generic component for unknown packages
@@ -194,8 +199,10 @@ func parseCMakeLists(path string, seen map[string]*model.Component, versions map
 				PURL:        "pkg:generic/" + strings.ToLower(pkgName),
 				Description: "Detected via CMake find_package()",
 			}
+			matched = ""
 		}
-		addOrUpdate(seen, fp, "", "", "cmake")
+		c := addOrUpdate(seen, fp, "", "", "cmake")
+		c.AddEvidence(evidence("cmake", path, lineAt(content, loc[0]), content[loc[0]:loc[1]], matched))
 	}
 
 	// FetchContent_Declare(foo GIT_REPOSITORY ... GIT_TAG ...)
@@ -203,6 +210,7 @@ func parseCMakeLists(path string, seen map[string]*model.Component, versions map
 	fetchMatches := reCMakeFetchContent.FindAllStringSubmatchIndex(content, -1)
 	for _, loc := range fetchMatches {
 		pkgName := content[loc[2]:loc[3]]
+		matched := pkgName
 		fp := fingerprints.MatchLibrary(pkgName)
 		if fp == nil {
 			fp = &fingerprints.LibraryFingerprint{
@@ -210,6 +218,7 @@ func parseCMakeLists(path string, seen map[string]*model.Component, versions map
 				PURL:        "pkg:generic/" + strings.ToLower(pkgName),
 				Description: "Detected via CMake FetchContent_Declare()",
 			}
+			matched = ""
 		}
 		// Look for GIT_TAG in the next 500 chars
 		end := loc[1] + 500
@@ -223,23 +232,27 @@ func parseCMakeLists(path string, seen map[string]*model.Component, versions map
 			tag = strings.TrimPrefix(tag, "v")
 			versions[strings.ToLower(pkgName)] = tag
 		}
-		addOrUpdate(seen, fp, "", "", "cmake")
+		c := addOrUpdate(seen, fp, "", "", "cmake")
+		c.AddEvidence(evidence("cmake", path, lineAt(content, loc[0]), content[loc[0]:loc[1]], matched))
 	}
 
 	// target_link_libraries with Foo::Bar namespace tokens
-	for _, m := range reCMakeLibToken.FindAllStringSubmatch(content, -1) {
-		ns := m[1]
+	for _, loc := range reCMakeLibToken.FindAllStringSubmatchIndex(content, -1) {
+		ns := content[loc[2]:loc[3]]
 		if isCMakeBuiltin(ns) {
 			continue
 		}
 		fp := fingerprints.MatchLibrary(ns)
 		if fp != nil {
-			addOrUpdate(seen, fp, "", "", "cmake")
+			c := addOrUpdate(seen, fp, "", "", "cmake")
+			c.AddEvidence(evidence("cmake", path, lineAt(content, loc[0]), content[loc[0]:loc[1]], ns))
 		}
 	}
 }
 
-func addOrUpdate(seen map[string]*model.Component, fp *fingerprints.LibraryFingerprint, incPath, lib, source string) {
+// addOrUpdate returns the component for fp, creating it on first sight, and
+// records the include path and library it was found through.
+func addOrUpdate(seen map[string]*model.Component, fp *fingerprints.LibraryFingerprint, incPath, lib, source string) *model.Component {
 	c, ok := seen[fp.Name]
 	if !ok {
 		c = &model.Component{
@@ -257,6 +270,7 @@ func addOrUpdate(seen map[string]*model.Component, fp *fingerprints.LibraryFinge
 	if lib != "" {
 		c.LinkLibraries = appendUnique(c.LinkLibraries, lib)
 	}
+	return c
 }
 
 // cmakeBuiltins is a set of CMake built-in module names to skip.
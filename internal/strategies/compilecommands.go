@@ -2,8 +2,9 @@
 package strategies
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -36,72 +37,35 @@ type CompileCommandsStrategy struct{}
 
 func (s *CompileCommandsStrategy) Name() string { return "compile_commands.json" }
 
-func (s *CompileCommandsStrategy) Scan(projectRoot string, verbose bool) ([]*model.Component, error) {
-	// compile_commands.json can live in the project root or in a build subdirectory.
-	candidates := []string{
-		filepath.Join(projectRoot, "compile_commands.json"),
-		filepath.Join(projectRoot, "build", "compile_commands.json"),
-		filepath.Join(projectRoot, "out", "compile_commands.json"),
-		filepath.Join(projectRoot, "cmake-build-debug", "compile_commands.json"),
-		filepath.Join(projectRoot, "cmake-build-release", "compile_commands.json"),
-		filepath.Join(projectRoot, ".build", "compile_commands.json"),
-	}
-
-	// Also walk up to 3 levels deep looking for compile_commands.json
-	found := []string{}
-	for _, c := range candidates {
-		if _, err := os.Stat(c); err == nil {
-			found = append(found, c)
-		}
-	}
-
-	// Walk build directories for compile_commands.json
-	_ = filepath.WalkDir(projectRoot, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			return nil
-		}
-		if d.IsDir() {
-			// Skip hidden dirs and common non-build dirs
-			name := d.Name()
-			if strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor" {
-				return filepath.SkipDir
-			}
-		}
-		if !d.IsDir() && d.Name() == "compile_commands.json" {
-			// Avoid duplicates
-			for _, f := range found {
-				if f == path {
-					return nil
-				}
-			}
-			found = append(found, path)
-		}
-		return nil
-	})
+func (s *CompileCommandsStrategy) Scan(ctx context.Context, ix *FileIndex, diags *Diagnostics, log *slog.Logger) ([]*model.Component, error) {
+	projectRoot := ix.Root
+	found := findCompileCommands(ix)
 
 	if len(found) == 0 {
-		if verbose {
-			fmt.Println("  [compile_commands] No compile_commands.json found")
-		}
+		log.Debug("no compile_commands.json found")
 		return nil, nil
 	}
 
 	// Collect all external include paths across all compile_commands.json files
-	externalIncludes := map[string]bool{}
-	externalLibs := map[string]bool{}
+	externalIncludes := sightings{}
+	externalLibs := sightings{}
 
 	for _, ccPath := range found {
-		if verbose {
-			fmt.Printf("  [compile_commands] Parsing %s\n", ccPath)
+		if err := ctx.Err(); err != nil {
+			return nil, err
 		}
+		log.Debug("parsing", "file", ccPath)
 		data, err := os.ReadFile(ccPath)
 		if err != nil {
+			diags.ReadError(ccPath, err)
 			continue
 		}
 		var commands []compileCommand
 		if err := json.Unmarshal(data, &commands); err != nil {
+			diags.ParseError(ccPath, data, err)
 			continue
 		}
+		content := string(data)
 
 		for _, cmd := range commands {
 			// Build a single string to parse from either command or arguments
@@ -115,7 +79,7 @@ func (s *CompileCommandsStrategy) Scan(projectRoot string, verbose bool) ([]*mod
 				if len(m) > 1 {
 					incPath := strings.TrimSpace(m[1])
 					if isExternalPath(incPath, projectRoot) {
-						externalIncludes[filepath.ToSlash(incPath)] = true
+						externalIncludes.add(filepath.ToSlash(incPath), ccPath, lineOf(content, incPath))
 					}
 				}
 			}
@@ -129,7 +93,7 @@ func (s *CompileCommandsStrategy) Scan(projectRoot string, verbose bool) ([]*mod
 					lib = m[2]
 				}
 				if lib != "" {
-					externalLibs[lib] = true
+					externalLibs.add(lib, ccPath, lineOf(content, strings.TrimSpace(m[0])))
 				}
 			}
 
@@ -139,15 +103,15 @@ func (s *CompileCommandsStrategy) Scan(projectRoot string, verbose bool) ([]*mod
 				if strings.HasPrefix(arg, "-I") && len(arg) > 2 {
 					incPath := arg[2:]
 					if isExternalPath(incPath, projectRoot) {
-						externalIncludes[filepath.ToSlash(incPath)] = true
+						externalIncludes.add(filepath.ToSlash(incPath), ccPath, lineOf(content, incPath))
 					}
 				} else if strings.HasPrefix(arg, "/I") && len(arg) > 2 {
 					incPath := arg[2:]
 					if isExternalPath(incPath, projectRoot) {
-						externalIncludes[filepath.ToSlash(incPath)] = true
+						externalIncludes.add(filepath.ToSlash(incPath), ccPath, lineOf(content, incPath))
 					}
 				} else if strings.HasPrefix(arg, "-l") && len(arg) > 2 {
-					externalLibs[arg[2:]] = true
+					externalLibs.add(arg[2:], ccPath, lineOf(content, arg))
 				}
 			}
 		}
@@ -156,6 +120,51 @@ func (s *CompileCommandsStrategy) Scan(projectRoot string, verbose bool) ([]*mod
 	return buildComponentsFromPaths(externalIncludes, externalLibs, s.Name()), nil
 }
 
+// findCompileCommands returns every compile_commands.json in the well-known
+// build directories and anywhere else in the project tree.
+func findCompileCommands(ix *FileIndex) []string {
+	projectRoot := ix.Root
+	// compile_commands.json can live in the project root or in a build subdirectory.
+	candidates := []string{
+		filepath.Join(projectRoot, "compile_commands.json"),
+		filepath.Join(projectRoot, "build", "compile_commands.json"),
+		filepath.Join(projectRoot, "out", "compile_commands.json"),
+		filepath.Join(projectRoot, "cmake-build-debug", "compile_commands.json"),
+		filepath.Join(projectRoot, "cmake-build-release", "compile_commands.json"),
+		filepath.Join(projectRoot, ".build", "compile_commands.json"),
+	}
+
+	// Also walk up to 3 levels deep looking for compile_commands.json
+	found := []string{}
+	for _, c := range candidates {
+		if _, err := os.Stat(c); err == nil {
+			found = append(found, c)
+		}
+	}
+
+	// Any other compile_commands.json outside hidden and non-build dirs
+	skip := func(name string) bool {
+		return strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor"
+	}
+	for _, f := range ix.Named("compile_commands.json") {
+		if f.Name != "compile_commands.json" || f.InDir(skip) {
+			continue
+		}
+		duplicate := false
+		for _, existing := range found {
+			if existing == f.Path {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			found = append(found, f.Path)
+		}
+	}
+
+	return found
+}
+
 // isExternalPath returns true if the given path is outside the project root.
 func isExternalPath(path, projectRoot string) bool {
 	if path == "" {
@@ -178,10 +187,11 @@ func isExternalPath(path, projectRoot string) bool {
 }
 
 // buildComponentsFromPaths maps external include paths and link libs to known library fingerprints.
-func buildComponentsFromPaths(includes map[string]bool, libs map[string]bool, source string) []*model.Component {
+// Each component records where the paths and libraries that identified it were seen.
+func buildComponentsFromPaths(includes, libs sightings, source string) []*model.Component {
 	seen := map[string]*model.Component{}
 
-	addComponent := func(fp *fingerprints.LibraryFingerprint, incPath, lib string) {
+	addComponent := func(fp *fingerprints.LibraryFingerprint, incPath, lib string, at model.Evidence) {
 		c, ok := seen[fp.Name]
 		if !ok {
 			c = &model.Component{
@@ -193,6 +203,7 @@ func buildComponentsFromPaths(includes map[string]bool, libs map[string]bool, so
 			}
 			seen[fp.Name] = c
 		}
+		c.AddEvidence(evidence(source, at.File, at.Line, at.Text, at.Text))
 		if incPath != "" {
 			c.IncludePaths = appendUnique(c.IncludePaths, incPath)
 			// Try to extract version from path (e.g. boost_1_82_0, openssl-3.1.4)
@@ -210,15 +221,15 @@ func buildComponentsFromPaths(includes map[string]bool, libs map[string]bool, so
 		}
 	}
 
-	for incPath := range includes {
+	for incPath, at := range includes {
 		if fp := fingerprints.MatchLibrary(incPath); fp != nil {
-			addComponent(fp, incPath, "")
+			addComponent(fp, incPath, "", at)
 		}
 	}
 
-	for lib := range libs {
+	for lib, at := range libs {
 		if fp := fingerprints.MatchLibrary(lib); fp != nil {
-			addComponent(fp, "", lib)
+			addComponent(fp, "", lib, at)
 		}
 	}
 
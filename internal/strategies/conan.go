@@ -2,10 +2,10 @@ package strategies
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
-	"fmt"
+	"log/slog"
 	"os"
-	"path/filepath"
 	"regexp"
 	"strings"
 
@@ -60,37 +60,27 @@ type ConanScanResult struct {
 	Edges map[string][]string
 }
 
-func (s *ConanStrategy) Scan(projectRoot string, verbose bool) ([]*model.Component, error) {
-	result := s.ScanWithGraph(projectRoot, verbose)
-	return result.Components, nil
+func (s *ConanStrategy) Scan(ctx context.Context, ix *FileIndex, diags *Diagnostics, log *slog.Logger) ([]*model.Component, error) {
+	result := s.ScanWithGraph(ctx, ix, diags, log)
+	return result.Components, ctx.Err()
 }
 
 // ScanWithGraph returns the full graph information including direct/transitive edges.
-func (s *ConanStrategy) ScanWithGraph(projectRoot string, verbose bool) *ConanScanResult {
+func (s *ConanStrategy) ScanWithGraph(ctx context.Context, ix *FileIndex, diags *Diagnostics, log *slog.Logger) *ConanScanResult {
 	result := &ConanScanResult{
 		DirectNames: map[string]bool{},
 		Edges:       map[string][]string{},
 	}
 
-	_ = filepath.WalkDir(projectRoot, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			return nil
+	for _, f := range ix.Named("conan.lock", "conanfile.txt", "conanfile.py") {
+		if ctx.Err() != nil {
+			break
 		}
-		if d.IsDir() {
-			name := d.Name()
-			if strings.HasPrefix(name, ".git") {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-
-		lname := strings.ToLower(d.Name())
-		switch lname {
+		path := f.Path
+		switch strings.ToLower(f.Name) {
 		case "conan.lock":
-			if verbose {
-				fmt.Printf("  [conan] Parsing conan.lock: %s\n", path)
-			}
-			lockResult := parseConanLockWithGraph(path)
+			log.Debug("parsing conan.lock", "file", path)
+			lockResult := parseConanLockWithGraph(path, diags)
 			result.Components = append(result.Components, lockResult.Components...)
 			for k, v := range lockResult.DirectNames {
 				result.DirectNames[k] = v
@@ -100,27 +90,22 @@ func (s *ConanStrategy) ScanWithGraph(projectRoot string, verbose bool) *ConanSc
 			}
 
 		case "conanfile.txt":
-			if verbose {
-				fmt.Printf("  [conan] Parsing conanfile.txt: %s\n", path)
-			}
-			comps, directNames := parseConanfileTxtWithDirect(path)
+			log.Debug("parsing conanfile.txt", "file", path)
+			comps, directNames := parseConanfileTxtWithDirect(path, diags)
 			result.Components = append(result.Components, comps...)
 			for k, v := range directNames {
 				result.DirectNames[k] = v
 			}
 
 		case "conanfile.py":
-			if verbose {
-				fmt.Printf("  [conan] Parsing conanfile.py: %s\n", path)
-			}
-			comps, directNames := parseConanfilePyWithDirect(path)
+			log.Debug("parsing conanfile.py", "file", path)
+			comps, directNames := parseConanfilePyWithDirect(path, diags)
 			result.Components = append(result.Components, comps...)
 			for k, v := range directNames {
 				result.DirectNames[k] = v
 			}
 		}
-		return nil
-	})
+	}
 
 	return result
 }
@@ -132,9 +117,10 @@ type lockGraphResult struct {
 	Edges       map[string][]string
 }
 
-func parseConanLockWithGraph(path string) *lockGraphResult {
+func parseConanLockWithGraph(path string, diags *Diagnostics) *lockGraphResult {
 	data, err := os.ReadFile(path)
 	if err != nil {
+		diags.ReadError(path, err)
 		return &lockGraphResult{DirectNames: map[string]bool{}, Edges: map[string][]string{}}
 	}
 
@@ -157,6 +143,7 @@ func parseConanLockWithGraph(path string) *lockGraphResult {
 			if c == nil {
 				continue
 			}
+			c.AddEvidence(evidence("conan", path, lineOf(string(data), node.Ref), node.Ref, ""))
 			nodeNames[idx] = c.Name
 			result.Components = append(result.Components, c)
 		}
@@ -190,29 +177,42 @@ func parseConanLockWithGraph(path string) *lockGraphResult {
 
 	// Try v2 format: flat JSON with "requires" / "build_requires" arrays at top level
 	var raw map[string]json.RawMessage
-	if err := json.Unmarshal(data, &raw); err == nil {
-		for _, key := range []string{"requires", "build_requires"} {
-			if reqRaw, ok := raw[key]; ok {
-				var refs []string
-				if err := json.Unmarshal(reqRaw, &refs); err == nil {
-					for _, ref := range refs {
-						c := conanRefToComponent(ref, "conan")
-						if c != nil {
-							result.Components = append(result.Components, c)
-							result.DirectNames[c.Name] = true
-						}
-					}
-				}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		diags.ParseError(path, data, err)
+		return result
+	}
+	recognised := false
+	for _, key := range []string{"requires", "build_requires"} {
+		reqRaw, ok := raw[key]
+		if !ok {
+			continue
+		}
+		recognised = true
+		var refs []string
+		if err := json.Unmarshal(reqRaw, &refs); err != nil {
+			diags.ParseError(path, data, err)
+			continue
+		}
+		for _, ref := range refs {
+			c := conanRefToComponent(ref, "conan")
+			if c != nil {
+				c.AddEvidence(evidence("conan", path, lineOf(string(data), ref), ref, ""))
+				result.Components = append(result.Components, c)
+				result.DirectNames[c.Name] = true
 			}
 		}
 	}
+	if !recognised {
+		diags.Warnf(path, "unrecognised conan.lock format: no graph_lock nodes and no requires list")
+	}
 
 	return result
 }
 
-func parseConanfileTxtWithDirect(path string) ([]*model.Component, map[string]bool) {
+func parseConanfileTxtWithDirect(path string, diags *Diagnostics) ([]*model.Component, map[string]bool) {
 	f, err := os.Open(path)
 	if err != nil {
+		diags.ReadError(path, err)
 		return nil, nil
 	}
 	defer f.Close()
@@ -231,7 +231,9 @@ func parseConanfileTxtWithDirect(path string) ([]*model.Component, map[string]bo
 	currentSection := sectionNone
 
 	sc := bufio.NewScanner(f)
+	lineNo := 0
 	for sc.Scan() {
+		lineNo++
 		line := strings.TrimSpace(sc.Text())
 		if line == "" || strings.HasPrefix(line, "#") {
 			continue
@@ -257,16 +259,21 @@ func parseConanfileTxtWithDirect(path string) ([]*model.Component, map[string]bo
 			channel := strings.TrimPrefix(m[3], "@")
 			revision := m[4]
 			c := makeConanComponentFull(m[1], m[2], channel, revision, "conan")
+			c.AddEvidence(evidence("conan", path, lineNo, strings.TrimSpace(m[0]), ""))
 			components = append(components, c)
 			directNames[c.Name] = true
 		}
 	}
+	if err := sc.Err(); err != nil {
+		diags.Warnf(path, "stopped reading early: %v", err)
+	}
 	return components, directNames
 }
 
-func parseConanfilePyWithDirect(path string) ([]*model.Component, map[string]bool) {
+func parseConanfilePyWithDirect(path string, diags *Diagnostics) ([]*model.Component, map[string]bool) {
 	data, err := os.ReadFile(path)
 	if err != nil {
+		diags.ReadError(path, err)
 		return nil, nil
 	}
 	content := string(data)
@@ -274,41 +281,51 @@ func parseConanfilePyWithDirect(path string) ([]*model.Component, map[string]boo
 	var components []*model.Component
 	directNames := map[string]bool{}
 
-	// self.requires(...) and self.build_requires(...)
-	// m[1]=name, m[2]=version, m[3]=@user/channel, m[4]=#revision
-	for _, m := range reConanfilePyRequires.FindAllStringSubmatch(content, -1) {
+	add := func(m []string, off int) {
 		channel := strings.TrimPrefix(m[3], "@")
 		revision := m[4]
 		c := makeConanComponentFull(m[1], m[2], channel, revision, "conan")
+		c.AddEvidence(evidence("conan", path, lineAt(content, off), strings.TrimSpace(m[0]), ""))
 		components = append(components, c)
 		directNames[c.Name] = true
 	}
 
+	// self.requires(...) and self.build_requires(...)
+	// m[1]=name, m[2]=version, m[3]=@user/channel, m[4]=#revision
+	for _, loc := range reConanfilePyRequires.FindAllStringSubmatchIndex(content, -1) {
+		add(submatches(content, loc), loc[0])
+	}
+
 	// python_requires = "name/version@channel#rev"
-	for _, m := range reConanfilePyPythonRequires.FindAllStringSubmatch(content, -1) {
-		channel := strings.TrimPrefix(m[3], "@")
-		revision := m[4]
-		c := makeConanComponentFull(m[1], m[2], channel, revision, "conan")
-		components = append(components, c)
-		directNames[c.Name] = true
+	for _, loc := range reConanfilePyPythonRequires.FindAllStringSubmatchIndex(content, -1) {
+		add(submatches(content, loc), loc[0])
 	}
 
 	// requires = ["foo/1.0", ...] list syntax
 	reList := regexp.MustCompile(`(?i)(?:^|\s)requires\s*=\s*\[([^\]]+)\]`)
-	if lm := reList.FindStringSubmatch(content); lm != nil {
+	if lm := reList.FindStringSubmatchIndex(content); lm != nil {
+		list := content[lm[2]:lm[3]]
 		reItem := regexp.MustCompile(`["']([A-Za-z0-9_\-\.]+)/([A-Za-z0-9_\-\.]+)(@[^#"']*)?(?:#([a-f0-9\-_]+))?[^"']*["']`)
-		for _, im := range reItem.FindAllStringSubmatch(lm[1], -1) {
-			channel := strings.TrimPrefix(im[3], "@")
-			revision := im[4]
-			c := makeConanComponentFull(im[1], im[2], channel, revision, "conan")
-			components = append(components, c)
-			directNames[c.Name] = true
+		for _, loc := range reItem.FindAllStringSubmatchIndex(list, -1) {
+			add(submatches(list, loc), lm[2]+loc[0])
 		}
 	}
 
 	return components, directNames
 }
 
+// submatches converts the indices from FindAllStringSubmatchIndex into the
+// strings FindAllStringSubmatch would return, with "" for unmatched groups.
+func submatches(s string, loc []int) []string {
+	out := make([]string, len(loc)/2)
+	for i := range out {
+		if loc[2*i] >= 0 {
+			out[i] = s[loc[2*i]:loc[2*i+1]]
+		}
+	}
+	return out
+}
+
 // conanRefToComponent parses a full Conan reference string and returns a Component.
 // ref format: "name/version@user/channel#revision" — all parts after name/version are optional.
 func conanRefToComponent(ref, source string) *model.Component {
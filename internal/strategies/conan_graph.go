@@ -21,12 +21,14 @@
 package strategies
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"time"
+	"strings"
 
 	"github.com/StinkyLord/cpp-sbom-builder/internal/model"
 )
@@ -87,33 +89,37 @@ type ConanGraphStrategy struct {
 func (s *ConanGraphStrategy) Name() string { return "conan-graph" }
 
 // Scan implements the Strategy interface (returns flat component list).
-func (s *ConanGraphStrategy) Scan(projectRoot string, verbose bool) ([]*model.Component, error) {
-	result := s.ScanWithGraph(projectRoot, verbose)
-	return result.Components, nil
+func (s *ConanGraphStrategy) Scan(ctx context.Context, ix *FileIndex, diags *Diagnostics, log *slog.Logger) ([]*model.Component, error) {
+	result := s.ScanWithGraph(ctx, ix, diags, log)
+	return result.Components, ctx.Err()
 }
 
 // ScanWithGraph returns the full graph result including edges and direct names.
 // It merges results from all conanfiles found anywhere in the project tree.
-func (s *ConanGraphStrategy) ScanWithGraph(projectRoot string, verbose bool) *ConanScanResult {
+func (s *ConanGraphStrategy) ScanWithGraph(ctx context.Context, ix *FileIndex, diags *Diagnostics, log *slog.Logger) *ConanScanResult {
 	merged := &ConanScanResult{
 		DirectNames: map[string]bool{},
 		Edges:       map[string][]string{},
 	}
 
 	// Step 1: collect all pre-existing graph.json files in the tree (passive)
-	graphFiles := s.findExistingGraphJSONs(projectRoot, verbose)
+	graphFiles := s.findExistingGraphJSONs(ix, log)
 
 	// Step 2: if RunConan is set, find all conanfile dirs and run conan graph info
 	if s.RunConan {
-		conanDirs := s.findConanfileDirs(projectRoot, verbose)
+		conanDirs := s.findConanfileDirs(ix, log)
 		for _, dir := range conanDirs {
-			path, err := s.runConanLocally(dir, verbose)
+			if ctx.Err() != nil {
+				break
+			}
+			path, err := s.runConanLocally(ctx, dir, log)
 			if err != nil {
-				if verbose {
-					fmt.Printf("  [conan-graph] conan failed in %s: %v\n", dir, err)
-				}
+				diags.Errorf(dir, "%v", err)
+				log.Debug("conan failed", "dir", dir, "err", err)
 				continue
 			}
+			// The graph is written to a temp file; drop it once parsed.
+			defer os.Remove(path)
 			// avoid duplicates
 			found := false
 			for _, gf := range graphFiles {
@@ -129,25 +135,31 @@ func (s *ConanGraphStrategy) ScanWithGraph(projectRoot string, verbose bool) *Co
 	}
 
 	if len(graphFiles) == 0 {
-		if verbose {
-			fmt.Println("  [conan-graph] No graph.json files found and --conan-graph not set")
-		}
+		log.Debug("no graph.json files found and --conan-graph not set")
 		return merged
 	}
 
 	// Step 3: parse and merge all graph.json files
 	for _, gf := range graphFiles {
+		if ctx.Err() != nil {
+			break
+		}
 		data, err := os.ReadFile(gf)
 		if err != nil {
-			if verbose {
-				fmt.Printf("  [conan-graph] cannot read %s: %v\n", gf, err)
-			}
+			diags.ReadError(gf, err)
+			log.Debug("cannot read graph.json", "file", gf, "err", err)
 			continue
 		}
-		if verbose {
-			fmt.Printf("  [conan-graph] Parsing %s\n", gf)
+		log.Debug("parsing", "file", gf)
+		r, err := parseConanGraphJSON(data)
+		if err != nil {
+			diags.ParseError(gf, data, err)
+			continue
+		}
+		for _, c := range r.Components {
+			ref := c.Name + "/" + c.Version
+			c.AddEvidence(evidence(s.Name(), gf, lineOf(string(data), `"`+ref), ref, ""))
 		}
-		r := parseConanGraphJSON(data)
 		merged.Components = append(merged.Components, r.Components...)
 		for k, v := range r.DirectNames {
 			merged.DirectNames[k] = v
@@ -162,65 +174,44 @@ func (s *ConanGraphStrategy) ScanWithGraph(projectRoot string, verbose bool) *Co
 	return merged
 }
 
-// findExistingGraphJSONs walks the project tree and returns all graph.json /
-// conan-graph.json files found (passive mode — no conan invocation).
-func (s *ConanGraphStrategy) findExistingGraphJSONs(projectRoot string, verbose bool) []string {
+// findExistingGraphJSONs returns all graph.json / conan-graph.json files in
+// the project tree (passive mode — no conan invocation).
+func (s *ConanGraphStrategy) findExistingGraphJSONs(ix *FileIndex, log *slog.Logger) []string {
+	skip := func(name string) bool {
+		return name == "node_modules" || name == ".conan"
+	}
 	var found []string
-	_ = filepath.WalkDir(projectRoot, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			return nil
-		}
-		if d.IsDir() {
-			name := d.Name()
-			if name == ".git" || name == "node_modules" || name == ".conan" {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-		name := d.Name()
-		if name == "graph.json" || name == "conan-graph.json" {
-			if verbose {
-				fmt.Printf("  [conan-graph] Found existing graph.json: %s\n", path)
-			}
-			found = append(found, path)
+	for _, f := range ix.Named("graph.json", "conan-graph.json") {
+		if (f.Name != "graph.json" && f.Name != "conan-graph.json") || f.InDir(skip) {
+			continue
 		}
-		return nil
-	})
+		log.Debug("found existing graph.json", "file", f.Path)
+		found = append(found, f.Path)
+	}
 	return found
 }
 
-// findConanfileDirs walks the project tree and returns the directory of every
-// conanfile.py or conanfile.txt found (at any depth).
+// findConanfileDirs returns the directory of every conanfile.py or
+// conanfile.txt in the project tree (at any depth), outside build output.
 // Each directory is returned only once even if both files exist in it.
-func (s *ConanGraphStrategy) findConanfileDirs(projectRoot string, verbose bool) []string {
+func (s *ConanGraphStrategy) findConanfileDirs(ix *FileIndex, log *slog.Logger) []string {
+	skip := func(name string) bool {
+		return name == "node_modules" || name == ".conan" ||
+			name == "build" || name == "_build" || name == "cmake-build"
+	}
 	seen := map[string]bool{}
 	var dirs []string
-
-	_ = filepath.WalkDir(projectRoot, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			return nil
+	for _, f := range ix.Named("conanfile.py", "conanfile.txt") {
+		if (f.Name != "conanfile.py" && f.Name != "conanfile.txt") || f.InDir(skip) {
+			continue
 		}
-		if d.IsDir() {
-			name := d.Name()
-			if name == ".git" || name == "node_modules" || name == ".conan" ||
-				name == "build" || name == "_build" || name == "cmake-build" {
-				return filepath.SkipDir
-			}
-			return nil
+		dir := filepath.Dir(f.Path)
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+			log.Debug("found conanfile", "dir", dir)
 		}
-		name := d.Name()
-		if name == "conanfile.py" || name == "conanfile.txt" {
-			dir := filepath.Dir(path)
-			if !seen[dir] {
-				seen[dir] = true
-				dirs = append(dirs, dir)
-				if verbose {
-					fmt.Printf("  [conan-graph] Found conanfile in: %s\n", dir)
-				}
-			}
-		}
-		return nil
-	})
+	}
 	return dirs
 }
 
@@ -231,7 +222,10 @@ func (s *ConanGraphStrategy) findConanfileDirs(projectRoot string, verbose bool)
 // runConanLocally runs `conan graph info <conanfileDir> --format=json` as a
 // local process. Conan must be on PATH — it is pre-installed in the
 // cpp-sbom-builder Docker image. No Docker-in-Docker is required.
-func (s *ConanGraphStrategy) runConanLocally(conanfileDir string, verbose bool) (string, error) {
+// The process is killed when ctx is done, so --timeout and --strategy-timeout
+// bound it. It returns the path of a temp file holding the graph, which the
+// caller removes.
+func (s *ConanGraphStrategy) runConanLocally(ctx context.Context, conanfileDir string, log *slog.Logger) (string, error) {
 	conanBin, err := exec.LookPath("conan")
 	if err != nil {
 		return "", fmt.Errorf("conan not found on PATH — " +
@@ -246,9 +240,7 @@ func (s *ConanGraphStrategy) runConanLocally(conanfileDir string, verbose bool)
 	tmpPath := tmpFile.Name()
 	tmpFile.Close()
 
-	if verbose {
-		fmt.Printf("  [conan-graph] Running: %s graph info %s --format=json\n", conanBin, conanfileDir)
-	}
+	log.Debug("running conan graph info", "conan", conanBin, "dir", conanfileDir)
 
 	outFile, err := os.Create(tmpPath)
 	if err != nil {
@@ -256,7 +248,7 @@ func (s *ConanGraphStrategy) runConanLocally(conanfileDir string, verbose bool)
 		return "", fmt.Errorf("cannot open temp file for writing: %w", err)
 	}
 
-	cmd := exec.Command(conanBin,
+	cmd := exec.CommandContext(ctx, conanBin,
 		"graph", "info", conanfileDir,
 		"--format=json",
 		"-s", "build_type=Release",
@@ -264,26 +256,17 @@ func (s *ConanGraphStrategy) runConanLocally(conanfileDir string, verbose bool)
 	cmd.Stdout = outFile
 	cmd.Stderr = os.Stderr
 
-	done := make(chan error, 1)
-	go func() { done <- cmd.Run() }()
-
-	select {
-	case runErr := <-done:
-		outFile.Close()
-		if runErr != nil {
-			os.Remove(tmpPath)
-			return "", fmt.Errorf("conan graph info failed in %s: %w", conanfileDir, runErr)
-		}
-	case <-time.After(5 * time.Minute):
-		cmd.Process.Kill()
-		outFile.Close()
+	runErr := cmd.Run()
+	outFile.Close()
+	if runErr != nil {
 		os.Remove(tmpPath)
-		return "", fmt.Errorf("conan graph info timed out after 5 minutes (dir: %s)", conanfileDir)
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("conan graph info stopped in %s: %w", conanfileDir, context.Cause(ctx))
+		}
+		return "", fmt.Errorf("conan graph info failed in %s: %w", conanfileDir, runErr)
 	}
 
-	if verbose {
-		fmt.Printf("  [conan-graph] graph.json written to %s\n", tmpPath)
-	}
+	log.Debug("graph.json written", "file", tmpPath)
 	return tmpPath, nil
 }
 
@@ -293,7 +276,7 @@ func (s *ConanGraphStrategy) runConanLocally(conanfileDir string, verbose bool)
 
 // parseConanGraphJSON parses the JSON produced by `conan graph info . --format=json`
 // and returns a ConanScanResult with full graph edges and direct/transitive info.
-func parseConanGraphJSON(data []byte) *ConanScanResult {
+func parseConanGraphJSON(data []byte) (*ConanScanResult, error) {
 	result := &ConanScanResult{
 		DirectNames: map[string]bool{},
 		Edges:       map[string][]string{},
@@ -301,7 +284,7 @@ func parseConanGraphJSON(data []byte) *ConanScanResult {
 
 	var g conanGraphJSON
 	if err := json.Unmarshal(data, &g); err != nil {
-		return result
+		return result, err
 	}
 
 	nodes := g.Graph.Nodes
@@ -335,10 +318,18 @@ func parseConanGraphJSON(data []byte) *ConanScanResult {
 			Revision:        node.Rrev,
 			DetectionSource: "conan-graph",
 			Description:     node.Description,
+			Licenses:        conanLicenses(node.License),
+			Homepage:        node.Homepage,
 		}
 
-		if node.Homepage != "" && c.Description == "" {
-			c.Description = node.Homepage
+		// "url" is the recipe repository (usually conan-center-index), not
+		// the upstream project.
+		if node.URL != "" {
+			c.ExternalReferences = append(c.ExternalReferences, model.ExternalReference{
+				Type:    "build-meta",
+				URL:     node.URL,
+				Comment: "Conan recipe repository",
+			})
 		}
 
 		c.PURL = "pkg:conan/" + node.Name + "@" + node.Version
@@ -359,5 +350,24 @@ func parseConanGraphJSON(data []byte) *ConanScanResult {
 		}
 	}
 
-	return result
+	return result, nil
+}
+
+// conanLicenses normalises the recipe "license" attribute, which conan emits
+// as a single string or a list of strings.
+func conanLicenses(v any) []string {
+	var licenses []string
+	switch l := v.(type) {
+	case string:
+		if l = strings.TrimSpace(l); l != "" {
+			licenses = append(licenses, l)
+		}
+	case []any:
+		for _, item := range l {
+			if s, ok := item.(string); ok && strings.TrimSpace(s) != "" {
+				licenses = appendUnique(licenses, strings.TrimSpace(s))
+			}
+		}
+	}
+	return licenses
 }
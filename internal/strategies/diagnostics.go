@@ -0,0 +1,100 @@
+package strategies
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/StinkyLord/cpp-sbom-builder/internal/model"
+)
+
+// Diagnostics collects the problems one strategy run meets, so a file that
+// failed to parse shows up in the scan result instead of looking like a
+// project without dependencies.
+//
+// A nil *Diagnostics discards everything, for callers that do not report.
+type Diagnostics struct {
+	strategy string
+	list     []model.Diagnostic
+}
+
+// NewDiagnostics returns a collector for the named strategy.
+func NewDiagnostics(strategy string) *Diagnostics {
+	return &Diagnostics{strategy: strategy}
+}
+
+// Errorf records that file (which may be empty) could not be used.
+func (d *Diagnostics) Errorf(file string, format string, args ...any) {
+	d.add(model.SeverityError, file, 0, fmt.Sprintf(format, args...))
+}
+
+// Warnf records that file (which may be empty) was only partly understood.
+func (d *Diagnostics) Warnf(file string, format string, args ...any) {
+	d.add(model.SeverityWarning, file, 0, fmt.Sprintf(format, args...))
+}
+
+// Infof records something worth knowing about file that lost nothing.
+func (d *Diagnostics) Infof(file string, format string, args ...any) {
+	d.add(model.SeverityInfo, file, 0, fmt.Sprintf(format, args...))
+}
+
+// ReadError records that file could not be read.
+func (d *Diagnostics) ReadError(file string, err error) {
+	d.Errorf(file, "cannot read: %v", unwrapPathError(err))
+}
+
+// ParseError records that the JSON in file could not be decoded, at the
+// line of the syntax error when there is one.
+func (d *Diagnostics) ParseError(file string, data []byte, err error) {
+	line := 0
+	var syntax *json.SyntaxError
+	var typ *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntax):
+		line = lineAtOffset(data, syntax.Offset)
+	case errors.As(err, &typ):
+		line = lineAtOffset(data, typ.Offset)
+	}
+	d.add(model.SeverityError, file, line, "invalid JSON: "+err.Error())
+}
+
+// List returns the diagnostics recorded so far.
+func (d *Diagnostics) List() []model.Diagnostic {
+	if d == nil {
+		return nil
+	}
+	return d.list
+}
+
+func (d *Diagnostics) add(severity, file string, line int, message string) {
+	if d == nil {
+		return
+	}
+	d.list = append(d.list, model.Diagnostic{
+		Severity: severity,
+		Strategy: d.strategy,
+		File:     file,
+		Line:     line,
+		Message:  message,
+	})
+}
+
+// unwrapPathError drops the operation and path of an *fs.PathError, which
+// the diagnostic already names.
+func unwrapPathError(err error) error {
+	var pe *fs.PathError
+	if errors.As(err, &pe) {
+		return pe.Err
+	}
+	return err
+}
+
+// lineAtOffset returns the 1-based line holding byte offset of data.
+func lineAtOffset(data []byte, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	return strings.Count(string(data[:offset]), "\n") + 1
+}
@@ -0,0 +1,48 @@
+package strategies
+
+import (
+	"strings"
+
+	"github.com/StinkyLord/cpp-sbom-builder/internal/fingerprints"
+	"github.com/StinkyLord/cpp-sbom-builder/internal/model"
+)
+
+// evidence builds the record of one observation. matched is the string that
+// was handed to fingerprints.MatchLibrary to identify the library, or "" when
+// the library was named outright (a manifest entry, a package reference).
+func evidence(strategy, file string, line int, text, matched string) model.Evidence {
+	e := model.Evidence{Strategy: strategy, File: file, Line: line, Text: text}
+	if matched != "" {
+		if m := fingerprints.Explain(matched); m != nil {
+			e.Fingerprint = m.String()
+		}
+	}
+	return e
+}
+
+// lineAt returns the 1-based line number of byte offset off in content.
+func lineAt(content string, off int) int {
+	return strings.Count(content[:off], "\n") + 1
+}
+
+// lineOf returns the 1-based line of the first occurrence of text in
+// content, or 0 if it does not occur. It locates entries in files that are
+// parsed as a whole, such as JSON manifests.
+func lineOf(content, text string) int {
+	off := strings.Index(content, text)
+	if off < 0 {
+		return 0
+	}
+	return lineAt(content, off)
+}
+
+// sightings maps a string found in build files (an include path, a library
+// name) to where it was first seen.
+type sightings map[string]model.Evidence
+
+// add records that value was seen at file:line, keeping the first sighting.
+func (s sightings) add(value, file string, line int) {
+	if _, ok := s[value]; !ok {
+		s[value] = model.Evidence{File: file, Line: line, Text: value}
+	}
+}
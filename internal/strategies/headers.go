@@ -2,7 +2,8 @@ package strategies
 
 import (
 	"bufio"
-	"fmt"
+	"context"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -40,43 +41,35 @@ var cppSourceExts = map[string]bool{
 	".inl": true, ".ipp": true, ".tpp": true,
 }
 
-func (s *HeadersStrategy) Scan(projectRoot string, verbose bool) ([]*model.Component, error) {
+func (s *HeadersStrategy) Scan(ctx context.Context, ix *FileIndex, diags *Diagnostics, log *slog.Logger) ([]*model.Component, error) {
+	projectRoot := ix.Root
 	seen := map[string]*model.Component{}
 	fileCount := 0
 
-	_ = filepath.WalkDir(projectRoot, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			return nil
-		}
-		if d.IsDir() {
-			name := d.Name()
-			// Skip hidden dirs, build output dirs, and vendor dirs
-			if strings.HasPrefix(name, ".") ||
-				name == "node_modules" ||
-				name == "CMakeFiles" ||
-				name == "build" ||
-				name == "out" ||
-				name == "_build" ||
-				name == ".build" {
-				return filepath.SkipDir
-			}
-			return nil
+	// Skip hidden dirs, build output dirs, and vendor dirs
+	skip := func(name string) bool {
+		return strings.HasPrefix(name, ".") ||
+			name == "node_modules" ||
+			name == "CMakeFiles" ||
+			name == "build" ||
+			name == "out" ||
+			name == "_build"
+	}
+	for _, f := range ix.Select(func(f File) bool {
+		return cppSourceExts[strings.ToLower(filepath.Ext(f.Name))]
+	}) {
+		if f.InDir(skip) {
+			continue
 		}
-
-		ext := strings.ToLower(filepath.Ext(d.Name()))
-		if !cppSourceExts[ext] {
-			return nil
+		if err := ctx.Err(); err != nil {
+			return nil, err
 		}
-
 		fileCount++
-		scanSourceFile(path, projectRoot, seen, verbose)
-		return nil
-	})
-
-	if verbose {
-		fmt.Printf("  [header-scan] Scanned %d source/header files\n", fileCount)
+		scanSourceFile(f.Path, projectRoot, seen, diags, log)
 	}
 
+	log.Debug("scanned source and header files", "files", fileCount)
+
 	result := make([]*model.Component, 0, len(seen))
 	for _, c := range seen {
 		result = append(result, c)
@@ -84,15 +77,18 @@ func (s *HeadersStrategy) Scan(projectRoot string, verbose bool) ([]*model.Compo
 	return result, nil
 }
 
-func scanSourceFile(path, projectRoot string, seen map[string]*model.Component, verbose bool) {
+func scanSourceFile(path, projectRoot string, seen map[string]*model.Component, diags *Diagnostics, log *slog.Logger) {
 	f, err := os.Open(path)
 	if err != nil {
+		diags.ReadError(path, err)
 		return
 	}
 	defer f.Close()
 
 	scanner := bufio.NewScanner(f)
+	lineNo := 0
 	for scanner.Scan() {
+		lineNo++
 		line := scanner.Text()
 		m := reIncludeDirective.FindStringSubmatch(line)
 		if m == nil {
@@ -137,6 +133,10 @@ func scanSourceFile(path, projectRoot string, seen map[string]*model.Component,
 			seen[fp.Name] = c
 		}
 		c.IncludePaths = appendUnique(c.IncludePaths, include)
+		c.AddEvidence(evidence("header-scan", path, lineNo, strings.TrimSpace(line), include))
+	}
+	if err := scanner.Err(); err != nil {
+		diags.Warnf(path, "stopped reading early: %v", err)
 	}
 }
 
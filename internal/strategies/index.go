@@ -0,0 +1,156 @@
+package strategies
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/StinkyLord/cpp-sbom-builder/internal/model"
+)
+
+// FileIndex lists the files of a project tree, collected in a single walk and
+// keyed by name and extension, so strategies look up the files they read
+// instead of each walking the tree.
+//
+// The walk skips .git* directories, which no strategy reads. Directories
+// that only some strategies ignore (build output for the header scan,
+// node_modules for the manifest strategies...) are indexed, and filtered per
+// strategy with File.InDir.
+type FileIndex struct {
+	// Root is the absolute or as-given project root the index was built from.
+	Root string
+
+	files  []File           // in walk (lexical) order
+	byName map[string][]int // lower-case base name -> indices into files
+	byExt  map[string][]int // lower-case extension -> indices into files
+	diags  *Diagnostics     // directories the walk could not read
+}
+
+// File is one indexed file.
+type File struct {
+	Path string // path as found by the walk, rooted at FileIndex.Root
+	Name string // base name
+
+	// dirs are the names of the directories between the root and the file.
+	dirs []string
+}
+
+// NewFileIndex walks root once and indexes every regular file below it.
+// Unreadable directories are skipped and reported by Diagnostics.
+func NewFileIndex(root string) *FileIndex {
+	ix := &FileIndex{Root: root, byName: map[string][]int{}, byExt: map[string][]int{}, diags: NewDiagnostics("")}
+	_ = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			ix.diags.Errorf(path, "cannot list directory, its files are not scanned: %v", unwrapPathError(err))
+			return nil
+		}
+		if d.IsDir() {
+			if path != root && strings.HasPrefix(d.Name(), ".git") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		ix.add(path, d.Name())
+		return nil
+	})
+	return ix
+}
+
+func (ix *FileIndex) add(path, name string) {
+	var dirs []string
+	if rel, err := filepath.Rel(ix.Root, filepath.Dir(path)); err == nil && rel != "." {
+		dirs = strings.Split(filepath.ToSlash(rel), "/")
+	}
+	i := len(ix.files)
+	ix.files = append(ix.files, File{Path: path, Name: name, dirs: dirs})
+	lname := strings.ToLower(name)
+	ix.byName[lname] = append(ix.byName[lname], i)
+	if ext := filepath.Ext(lname); ext != "" {
+		ix.byExt[ext] = append(ix.byExt[ext], i)
+	}
+}
+
+// Diagnostics returns the problems met while walking the tree.
+func (ix *FileIndex) Diagnostics() []model.Diagnostic { return ix.diags.List() }
+
+// Len returns the number of indexed files.
+func (ix *FileIndex) Len() int { return len(ix.files) }
+
+// Named returns the files whose base name is one of names, compared
+// case-insensitively, in walk order.
+func (ix *FileIndex) Named(names ...string) []File {
+	var idx []int
+	for _, n := range names {
+		idx = append(idx, ix.byName[strings.ToLower(n)]...)
+	}
+	return ix.collect(idx)
+}
+
+// WithExt returns the files with one of the extensions (".map", ".so"),
+// compared case-insensitively, in walk order.
+func (ix *FileIndex) WithExt(exts ...string) []File {
+	var idx []int
+	for _, e := range exts {
+		idx = append(idx, ix.byExt[strings.ToLower(e)]...)
+	}
+	return ix.collect(idx)
+}
+
+// Select returns the files match accepts, in walk order. It is for lookups
+// that neither the name nor the extension key answers.
+func (ix *FileIndex) Select(match func(f File) bool) []File {
+	var out []File
+	for _, f := range ix.files {
+		if match(f) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func (ix *FileIndex) collect(idx []int) []File {
+	sort.Ints(idx)
+	out := make([]File, 0, len(idx))
+	for i, n := range idx {
+		if i > 0 && idx[i-1] == n {
+			continue
+		}
+		out = append(out, ix.files[n])
+	}
+	return out
+}
+
+// Sub returns the index of dir. When dir lies inside the indexed tree it is
+// carved out of this index; otherwise dir is walked.
+func (ix *FileIndex) Sub(dir string) *FileIndex {
+	rel, err := filepath.Rel(ix.Root, dir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return NewFileIndex(dir)
+	}
+	sub := &FileIndex{Root: dir, byName: map[string][]int{}, byExt: map[string][]int{}}
+	prefix := filepath.Clean(dir) + string(filepath.Separator)
+	for _, f := range ix.files {
+		if strings.HasPrefix(f.Path, prefix) {
+			sub.add(f.Path, f.Name)
+		}
+	}
+	return sub
+}
+
+// InDir reports whether a directory between the index root and the file has
+// a name skip accepts.
+func (f File) InDir(skip func(name string) bool) bool {
+	for _, d := range f.dirs {
+		if skip(d) {
+			return true
+		}
+	}
+	return false
+}
+
+// isNodeModules is the directory filter of the strategies that ignore
+// vendored JavaScript dependencies.
+func isNodeModules(name string) bool {
+	return name == "node_modules"
+}
@@ -0,0 +1,77 @@
+package strategies
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileIndex(t *testing.T) {
+	dir := t.TempDir()
+	for _, rel := range []string{
+		"CMakeLists.txt",
+		"src/main.cpp",
+		"src/cmakelists.txt",
+		"build/app.map",
+		"build/CMakeFiles/app.dir/link.txt",
+		"node_modules/pkg/CMakeLists.txt",
+		".git/config",
+		".github/workflows/CMakeLists.txt",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ix := NewFileIndex(dir)
+	if ix.Len() != 6 {
+		t.Errorf("Len() = %d, want 6 (.git* directories skipped)", ix.Len())
+	}
+
+	rels := func(files []File) []string {
+		var out []string
+		for _, f := range files {
+			rel, _ := filepath.Rel(dir, f.Path)
+			out = append(out, filepath.ToSlash(rel))
+		}
+		return out
+	}
+	check := func(what string, got, want []string) {
+		t.Helper()
+		if len(got) != len(want) {
+			t.Errorf("%s = %v, want %v", what, got, want)
+			return
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("%s = %v, want %v", what, got, want)
+				return
+			}
+		}
+	}
+
+	check("Named", rels(ix.Named("CMakeLists.txt")),
+		[]string{"CMakeLists.txt", "node_modules/pkg/CMakeLists.txt", "src/cmakelists.txt"})
+	check("WithExt", rels(ix.WithExt(".MAP", ".cpp")), []string{"build/app.map", "src/main.cpp"})
+
+	var outside []File
+	for _, f := range ix.Named("CMakeLists.txt") {
+		if !f.InDir(isNodeModules) {
+			outside = append(outside, f)
+		}
+	}
+	check("InDir", rels(outside), []string{"CMakeLists.txt", "src/cmakelists.txt"})
+
+	sub := ix.Sub(filepath.Join(dir, "build"))
+	if sub.Root != filepath.Join(dir, "build") {
+		t.Errorf("Sub root = %q", sub.Root)
+	}
+	check("Sub", rels(sub.Named("link.txt")), []string{"build/CMakeFiles/app.dir/link.txt"})
+	if files := sub.Named("link.txt"); len(files) == 1 && files[0].InDir(func(name string) bool { return name == "build" }) {
+		t.Error("Sub kept the directories above its root")
+	}
+}
@@ -16,8 +16,9 @@
 package strategies
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
@@ -58,9 +59,9 @@ type LddStrategy struct{}
 func (s *LddStrategy) Name() string { return "ldd" }
 
 // Scan implements the Strategy interface.
-func (s *LddStrategy) Scan(projectRoot string, verbose bool) ([]*model.Component, error) {
-	result := s.ScanWithEdges(projectRoot, verbose)
-	return result.Components, nil
+func (s *LddStrategy) Scan(ctx context.Context, ix *FileIndex, diags *Diagnostics, log *slog.Logger) ([]*model.Component, error) {
+	result := s.ScanWithEdges(ctx, ix, diags, log)
+	return result.Components, ctx.Err()
 }
 
 // LddScanResult holds components and dependency edges from ldd output.
@@ -71,7 +72,8 @@ type LddScanResult struct {
 }
 
 // ScanWithEdges returns both components and the dependency edges.
-func (s *LddStrategy) ScanWithEdges(projectRoot string, verbose bool) *LddScanResult {
+func (s *LddStrategy) ScanWithEdges(ctx context.Context, ix *FileIndex, diags *Diagnostics, log *slog.Logger) *LddScanResult {
+	projectRoot := ix.Root
 	result := &LddScanResult{
 		Edges: map[string][]string{},
 	}
@@ -96,37 +98,32 @@ func (s *LddStrategy) ScanWithEdges(projectRoot string, verbose bool) *LddScanRe
 	}
 
 	if lddPath == "" {
-		if verbose {
-			fmt.Println("  [ldd] No ldd-results.json found — skipping (use --ldd inside Docker to generate)")
-		}
+		log.Debug("no ldd-results.json found, skipping (use --ldd inside Docker to generate)")
 		return result
 	}
 
 	data, err := os.ReadFile(lddPath)
 	if err != nil {
-		if verbose {
-			fmt.Printf("  [ldd] Cannot read %s: %v\n", lddPath, err)
-		}
+		diags.ReadError(lddPath, err)
+		log.Debug("cannot read ldd results", "file", lddPath, "err", err)
 		return result
 	}
 
-	if verbose {
-		fmt.Printf("  [ldd] Parsing %s\n", lddPath)
-	}
+	log.Debug("parsing", "file", lddPath)
 
 	var lddFile lddResultsFile
 	if err := json.Unmarshal(data, &lddFile); err != nil {
-		if verbose {
-			fmt.Printf("  [ldd] JSON parse error: %v\n", err)
-		}
+		diags.ParseError(lddPath, data, err)
+		log.Debug("cannot parse ldd results", "file", lddPath, "err", err)
 		return result
 	}
 
 	seen := map[string]*model.Component{}
+	content := string(data)
 
 	for _, entry := range lddFile.Results {
 		// Map the parent .so to a package
-		parentPkg := libNameToPackage(filepath.Base(entry.Library))
+		matched, parentPkg := matchLibName(filepath.Base(entry.Library))
 		if parentPkg == nil {
 			continue
 		}
@@ -145,6 +142,9 @@ func (s *LddStrategy) ScanWithEdges(projectRoot string, verbose bool) *LddScanRe
 			}
 			seen[parentPkg.Name] = c
 		}
+		addArtifact(seen[parentPkg.Name], entry.Library)
+		seen[parentPkg.Name].AddEvidence(evidence(s.Name(), lddPath,
+			lineOf(content, `"`+entry.Library+`"`), entry.Library, matched))
 
 		for _, dep := range entry.Deps {
 			// Skip system/libc libraries
@@ -152,11 +152,11 @@ func (s *LddStrategy) ScanWithEdges(projectRoot string, verbose bool) *LddScanRe
 				continue
 			}
 
-			childPkg := libNameToPackage(dep.Name)
+			matched, childPkg := matchLibName(dep.Name)
 			if childPkg == nil {
 				// Try matching by path
 				if dep.Path != "" {
-					childPkg = libNameToPackage(filepath.Base(dep.Path))
+					matched, childPkg = matchLibName(filepath.Base(dep.Path))
 				}
 			}
 			if childPkg == nil || childPkg.Name == parentPkg.Name {
@@ -181,13 +181,16 @@ func (s *LddStrategy) ScanWithEdges(projectRoot string, verbose bool) *LddScanRe
 				}
 				seen[childPkg.Name] = c
 			}
+			if dep.Path != "" {
+				addArtifact(seen[childPkg.Name], dep.Path)
+			}
+			seen[childPkg.Name].AddEvidence(evidence(s.Name(), lddPath,
+				lineOf(content, `"`+dep.Name+`"`), entry.Library+" needs "+dep.Name, matched))
 
 			// Record the edge: parent depends on child
 			result.Edges[parentPkg.Name] = appendUnique(result.Edges[parentPkg.Name], childPkg.Name)
 
-			if verbose {
-				fmt.Printf("  [ldd] edge: %s → %s\n", parentPkg.Name, childPkg.Name)
-			}
+			log.Debug("edge", "from", parentPkg.Name, "to", childPkg.Name)
 		}
 	}
 
@@ -195,9 +198,7 @@ func (s *LddStrategy) ScanWithEdges(projectRoot string, verbose bool) *LddScanRe
 		result.Components = append(result.Components, c)
 	}
 
-	if verbose {
-		fmt.Printf("  [ldd] Found %d component(s) from ldd output\n", len(result.Components))
-	}
+	log.Debug("components from ldd output", "components", len(result.Components))
 
 	return result
 }
@@ -246,7 +247,8 @@ func (s *CMakeConfigureStrategy) Name() string { return "cmake-configure" }
 // Scan implements the Strategy interface.
 // It delegates to the existing CompileCommandsStrategy and BuildLogsStrategy,
 // but pointed at the cmake build directory set by the entrypoint.
-func (s *CMakeConfigureStrategy) Scan(projectRoot string, verbose bool) ([]*model.Component, error) {
+func (s *CMakeConfigureStrategy) Scan(ctx context.Context, ix *FileIndex, diags *Diagnostics, log *slog.Logger) ([]*model.Component, error) {
+	projectRoot := ix.Root
 	// Find the cmake build directory
 	buildDir := os.Getenv("SBOM_EXTRA_BUILD_DIR")
 	if buildDir == "" {
@@ -268,27 +270,21 @@ func (s *CMakeConfigureStrategy) Scan(projectRoot string, verbose bool) ([]*mode
 	}
 
 	if buildDir == "" {
-		if verbose {
-			fmt.Println("  [cmake-configure] No cmake build directory found — skipping")
-			fmt.Println("  [cmake-configure] Use --cmake-configure inside Docker to auto-generate")
-		}
+		log.Debug("no cmake build directory found, skipping (use --cmake-configure inside Docker to generate one)")
 		return nil, nil
 	}
 
-	if verbose {
-		fmt.Printf("  [cmake-configure] Using cmake build dir: %s\n", buildDir)
-	}
+	log.Debug("using cmake build directory", "dir", buildDir)
 
 	seen := map[string]*model.Component{}
+	buildIx := ix.Sub(buildDir)
 
 	// 1. Parse compile_commands.json from the build dir
 	ccPath := filepath.Join(buildDir, "compile_commands.json")
 	if _, err := os.Stat(ccPath); err == nil {
-		if verbose {
-			fmt.Printf("  [cmake-configure] Parsing compile_commands.json from %s\n", buildDir)
-		}
+		log.Debug("parsing compile_commands.json", "dir", buildDir)
 		ccStrat := &CompileCommandsStrategy{}
-		comps, err := ccStrat.Scan(buildDir, verbose)
+		comps, err := ccStrat.Scan(ctx, buildIx, diags, log)
 		if err == nil {
 			for _, c := range comps {
 				c.DetectionSource = s.Name()
@@ -309,23 +305,20 @@ func (s *CMakeConfigureStrategy) Scan(projectRoot string, verbose bool) ([]*mode
 	//   /usr/lib/x86_64-linux-gnu/libssl.so.3
 	//   -lz -lpthread
 	linkTxtCount := 0
-	_ = filepath.WalkDir(buildDir, func(path string, d os.DirEntry, err error) error {
-		if err != nil || d.IsDir() {
-			return nil
+	for _, f := range buildIx.Named("link.txt") {
+		if err := ctx.Err(); err != nil {
+			return nil, err
 		}
-		if d.Name() != "link.txt" {
-			return nil
+		if f.Name != "link.txt" {
+			continue
 		}
 		linkTxtCount++
-		if verbose {
-			fmt.Printf("  [cmake-configure] Parsing link.txt: %s\n", path)
-		}
-		s.parseLinkTxt(path, projectRoot, seen, verbose)
-		return nil
-	})
+		log.Debug("parsing link.txt", "file", f.Path)
+		s.parseLinkTxt(f.Path, projectRoot, seen, diags, log)
+	}
 
-	if verbose && linkTxtCount > 0 {
-		fmt.Printf("  [cmake-configure] Parsed %d link.txt file(s) (MAP equivalent)\n", linkTxtCount)
+	if linkTxtCount > 0 {
+		log.Debug("parsed link.txt files (MAP equivalent)", "files", linkTxtCount)
 	}
 
 	result := make([]*model.Component, 0, len(seen))
@@ -340,10 +333,12 @@ func (s *CMakeConfigureStrategy) Scan(projectRoot string, verbose bool) ([]*mode
 func (s *CMakeConfigureStrategy) parseLinkTxt(
 	path, projectRoot string,
 	seen map[string]*model.Component,
-	verbose bool,
+	diags *Diagnostics,
+	log *slog.Logger,
 ) {
 	data, err := os.ReadFile(path)
 	if err != nil {
+		diags.ReadError(path, err)
 		return
 	}
 
@@ -365,9 +360,11 @@ func (s *CMakeConfigureStrategy) parseLinkTxt(
 		case strings.HasPrefix(token, "-l") && len(token) > 2:
 			libName := token[2:]
 			// Try to match the lib name directly
+			matched := libName
 			fp := fingerprints.MatchLibrary(libName)
 			if fp == nil {
-				fp = fingerprints.MatchLibrary("lib" + libName)
+				matched = "lib" + libName
+				fp = fingerprints.MatchLibrary(matched)
 			}
 			if fp != nil {
 				key := strings.ToLower(fp.Name)
@@ -380,12 +377,11 @@ func (s *CMakeConfigureStrategy) parseLinkTxt(
 						Description:     fp.Description,
 						LinkLibraries:   []string{libName},
 					}
-					if verbose {
-						fmt.Printf("  [cmake-configure] -l flag: %s → %s\n", libName, fp.Name)
-					}
+					log.Debug("-l flag", "lib", libName, "component", fp.Name)
 				} else {
 					seen[key].LinkLibraries = appendUnique(seen[key].LinkLibraries, libName)
 				}
+				seen[key].AddEvidence(evidence(s.Name(), path, lineOf(content, token), token, matched))
 			}
 			continue
 
@@ -413,9 +409,11 @@ func (s *CMakeConfigureStrategy) parseLinkTxt(
 			continue
 		}
 
+		matched := libPath
 		fp := fingerprints.MatchLibrary(libPath)
 		if fp == nil {
-			fp = fingerprints.MatchLibrary(filepath.Base(libPath))
+			matched = filepath.Base(libPath)
+			fp = fingerprints.MatchLibrary(matched)
 		}
 		if fp == nil {
 			continue
@@ -438,12 +436,12 @@ func (s *CMakeConfigureStrategy) parseLinkTxt(
 				Description:     fp.Description,
 				LinkLibraries:   []string{filepath.Base(libPath)},
 			}
-			if verbose {
-				fmt.Printf("  [cmake-configure] link.txt lib: %s → %s@%s\n", filepath.Base(libPath), fp.Name, ver)
-			}
+			log.Debug("link.txt library", "lib", filepath.Base(libPath), "component", fp.Name, "version", ver)
 		} else {
 			seen[key].LinkLibraries = appendUnique(seen[key].LinkLibraries, filepath.Base(libPath))
 		}
+		addArtifact(seen[key], libPath)
+		seen[key].AddEvidence(evidence(s.Name(), path, lineOf(content, libPath), libPath, matched))
 	}
 }
 
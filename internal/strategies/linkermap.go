@@ -2,7 +2,8 @@ package strategies
 
 import (
 	"bufio"
-	"fmt"
+	"context"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -62,50 +63,36 @@ var reSatisfyRef = regexp.MustCompile(`^\s*([^\s(]+(?:\.(?:so|a|lib)(?:\.\d+)*)?
 // Captures the library path (everything up to the opening paren of the object member).
 var reSatisfyChildLine = regexp.MustCompile(`(?i)^([A-Za-z]:[\\\/][^\s(]+\.(?:lib|a|so(?:\.\d+)*))\(`)
 
-func (s *LinkerMapStrategy) Scan(projectRoot string, verbose bool) ([]*model.Component, error) {
-	r := s.ScanWithEdges(projectRoot, verbose)
-	return r.Components, nil
+func (s *LinkerMapStrategy) Scan(ctx context.Context, ix *FileIndex, diags *Diagnostics, log *slog.Logger) ([]*model.Component, error) {
+	r := s.ScanWithEdges(ctx, ix, diags, log)
+	return r.Components, ctx.Err()
 }
 
 // ScanWithEdges returns both components and the dependency edges.
-func (s *LinkerMapStrategy) ScanWithEdges(projectRoot string, verbose bool) *LinkerMapResult {
+func (s *LinkerMapStrategy) ScanWithEdges(ctx context.Context, ix *FileIndex, diags *Diagnostics, log *slog.Logger) *LinkerMapResult {
+	projectRoot := ix.Root
 	result := &LinkerMapResult{
 		Edges: map[string][]string{},
 	}
 
 	var mapFiles []string
-	_ = filepath.WalkDir(projectRoot, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			return nil
-		}
-		if d.IsDir() {
-			name := d.Name()
-			if strings.HasPrefix(name, ".git") {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-		ext := strings.ToLower(filepath.Ext(d.Name()))
-		if ext == ".map" {
-			mapFiles = append(mapFiles, path)
-		}
-		return nil
-	})
+	for _, f := range ix.WithExt(".map") {
+		mapFiles = append(mapFiles, f.Path)
+	}
 
 	if len(mapFiles) == 0 {
-		if verbose {
-			fmt.Println("  [linker-map] No .map files found")
-		}
+		log.Debug("no .map files found")
 		return result
 	}
 
-	externalLibPaths := map[string]bool{}
+	externalLibPaths := sightings{}
 
 	for _, mf := range mapFiles {
-		if verbose {
-			fmt.Printf("  [linker-map] Parsing %s\n", mf)
+		if ctx.Err() != nil {
+			break
 		}
-		s.parseMapFile(mf, projectRoot, externalLibPaths, result.Edges, verbose)
+		log.Debug("parsing", "file", mf)
+		s.parseMapFile(mf, projectRoot, externalLibPaths, result.Edges, diags, log)
 	}
 
 	if len(externalLibPaths) == 0 {
@@ -113,10 +100,12 @@ func (s *LinkerMapStrategy) ScanWithEdges(projectRoot string, verbose bool) *Lin
 	}
 
 	seen := map[string]*model.Component{}
-	for libPath := range externalLibPaths {
+	for libPath, at := range externalLibPaths {
+		matched := libPath
 		fp := fingerprints.MatchLibrary(libPath)
 		if fp == nil {
-			fp = fingerprints.MatchLibrary(filepath.Base(libPath))
+			matched = filepath.Base(libPath)
+			fp = fingerprints.MatchLibrary(matched)
 		}
 		if fp == nil {
 			continue
@@ -133,6 +122,8 @@ func (s *LinkerMapStrategy) ScanWithEdges(projectRoot string, verbose bool) *Lin
 			seen[fp.Name] = c
 		}
 		c.LinkLibraries = appendUnique(c.LinkLibraries, filepath.Base(libPath))
+		c.AddEvidence(evidence(s.Name(), at.File, at.Line, libPath, matched))
+		addArtifact(c, filepath.FromSlash(libPath))
 		if v := extractVersionFromPath(libPath); v != "" && c.Version == "unknown" {
 			c.Version = v
 			c.PURL = fp.PURL + "@" + v
@@ -151,12 +142,14 @@ func (s *LinkerMapStrategy) ScanWithEdges(projectRoot string, verbose bool) *Lin
 
 func (s *LinkerMapStrategy) parseMapFile(
 	path, projectRoot string,
-	externalLibPaths map[string]bool,
+	externalLibPaths sightings,
 	edges map[string][]string,
-	verbose bool,
+	diags *Diagnostics,
+	log *slog.Logger,
 ) {
 	f, err := os.Open(path)
 	if err != nil {
+		diags.ReadError(path, err)
 		return
 	}
 	defer f.Close()
@@ -170,9 +163,11 @@ func (s *LinkerMapStrategy) parseMapFile(
 	// We remember the child path from line 1 and pair it with the parent on line 2.
 	pendingSatisfyChild := ""
 
+	lineNo := 0
 	scanner := bufio.NewScanner(f)
 	for scanner.Scan() {
 		line := scanner.Text()
+		lineNo++
 
 		// Detect the GNU "Archive member included to satisfy reference" section header
 		if strings.Contains(line, "Archive member included") && strings.Contains(line, "satisfy") {
@@ -196,7 +191,7 @@ func (s *LinkerMapStrategy) parseMapFile(
 					pendingSatisfyChild = filepath.ToSlash(m[1])
 					// Also record the child as an external lib path
 					if isExternalLibPath(pendingSatisfyChild, projectRoot) {
-						externalLibPaths[pendingSatisfyChild] = true
+						externalLibPaths.add(pendingSatisfyChild, path, lineNo)
 					}
 					continue
 				}
@@ -215,7 +210,7 @@ func (s *LinkerMapStrategy) parseMapFile(
 					// Parent is also an external library
 					parentPath = filepath.ToSlash(pm[1])
 					if isExternalLibPath(parentPath, projectRoot) {
-						externalLibPaths[parentPath] = true
+						externalLibPaths.add(parentPath, path, lineNo)
 					}
 				}
 				// else: parent is a local object file — we still record the child
@@ -225,10 +220,7 @@ func (s *LinkerMapStrategy) parseMapFile(
 				if parentPath != "" {
 					parentPkg := libNameToPackage(filepath.Base(parentPath))
 					if childPkg != nil && parentPkg != nil && childPkg.Name != parentPkg.Name {
-						if verbose {
-							fmt.Printf("  [linker-map] edge: %s → %s (satisfy reference)\n",
-								parentPkg.Name, childPkg.Name)
-						}
+						log.Debug("edge (satisfy reference)", "from", parentPkg.Name, "to", childPkg.Name)
 						edges[parentPkg.Name] = appendUnique(edges[parentPkg.Name], childPkg.Name)
 					}
 				}
@@ -242,19 +234,16 @@ func (s *LinkerMapStrategy) parseMapFile(
 				parentPath := strings.TrimSpace(m[2])
 
 				if isExternalLibPath(childPath, projectRoot) {
-					externalLibPaths[filepath.ToSlash(childPath)] = true
+					externalLibPaths.add(filepath.ToSlash(childPath), path, lineNo)
 				}
 				if isExternalLibPath(parentPath, projectRoot) {
-					externalLibPaths[filepath.ToSlash(parentPath)] = true
+					externalLibPaths.add(filepath.ToSlash(parentPath), path, lineNo)
 				}
 
 				childPkg := libNameToPackage(filepath.Base(childPath))
 				parentPkg := libNameToPackage(filepath.Base(parentPath))
 				if childPkg != nil && parentPkg != nil && childPkg.Name != parentPkg.Name {
-					if verbose {
-						fmt.Printf("  [linker-map] edge: %s → %s (satisfy reference)\n",
-							parentPkg.Name, childPkg.Name)
-					}
+					log.Debug("edge (satisfy reference)", "from", parentPkg.Name, "to", childPkg.Name)
 					edges[parentPkg.Name] = appendUnique(edges[parentPkg.Name], childPkg.Name)
 				}
 				continue
@@ -271,16 +260,19 @@ func (s *LinkerMapStrategy) parseMapFile(
 		if m := reMapLibEntry.FindStringSubmatch(line); m != nil {
 			libPath := m[1]
 			if isExternalLibPath(libPath, projectRoot) {
-				externalLibPaths[filepath.ToSlash(libPath)] = true
+				externalLibPaths.add(filepath.ToSlash(libPath), path, lineNo)
 			}
 		}
 		for _, m := range reMSVCLibLine.FindAllStringSubmatch(line, -1) {
 			libPath := m[1]
 			if isExternalLibPath(libPath, projectRoot) {
-				externalLibPaths[filepath.ToSlash(libPath)] = true
+				externalLibPaths.add(filepath.ToSlash(libPath), path, lineNo)
 			}
 		}
 	}
+	if err := scanner.Err(); err != nil {
+		diags.Warnf(path, "stopped reading early: %v", err)
+	}
 }
 
 // isExternalLibPath returns true if the given library path is outside the project root.
@@ -1,11 +1,13 @@
 package strategies
 
 import (
+	"context"
 	"path/filepath"
 	"runtime"
 	"strings"
 	"testing"
 
+	"github.com/StinkyLord/cpp-sbom-builder/internal/logging"
 	"github.com/StinkyLord/cpp-sbom-builder/internal/model"
 )
 
@@ -24,7 +26,7 @@ func sampleCppProjectDir() string {
 func TestLinkerMap_FindsMapFile(t *testing.T) {
 	dir := sampleCppProjectDir()
 	strat := &LinkerMapStrategy{}
-	result := strat.ScanWithEdges(dir, true)
+	result := strat.ScanWithEdges(context.Background(), NewFileIndex(dir), nil, logging.Discard())
 
 	// The map file contains libgcc.a, libc_nano.a, libnosys.a — at minimum
 	// the strategy must find *something* (non-zero components or at least
@@ -45,7 +47,7 @@ func TestLinkerMap_FindsMapFile(t *testing.T) {
 func TestLinkerMap_DetectsLibgcc(t *testing.T) {
 	dir := sampleCppProjectDir()
 	strat := &LinkerMapStrategy{}
-	result := strat.ScanWithEdges(dir, false)
+	result := strat.ScanWithEdges(context.Background(), NewFileIndex(dir), nil, logging.Discard())
 
 	byName := map[string]bool{}
 	for _, c := range result.Components {
@@ -63,7 +65,7 @@ func TestLinkerMap_DetectsLibgcc(t *testing.T) {
 func TestLinkerMap_DetectsLibcNano(t *testing.T) {
 	dir := sampleCppProjectDir()
 	strat := &LinkerMapStrategy{}
-	result := strat.ScanWithEdges(dir, false)
+	result := strat.ScanWithEdges(context.Background(), NewFileIndex(dir), nil, logging.Discard())
 
 	byName := map[string]bool{}
 	for _, c := range result.Components {
@@ -81,7 +83,7 @@ func TestLinkerMap_DetectsLibcNano(t *testing.T) {
 func TestLinkerMap_DetectsLibnosys(t *testing.T) {
 	dir := sampleCppProjectDir()
 	strat := &LinkerMapStrategy{}
-	result := strat.ScanWithEdges(dir, false)
+	result := strat.ScanWithEdges(context.Background(), NewFileIndex(dir), nil, logging.Discard())
 
 	byName := map[string]bool{}
 	for _, c := range result.Components {
@@ -105,7 +107,7 @@ func TestLinkerMap_DetectsLibnosys(t *testing.T) {
 func TestLinkerMap_SatisfySection_ParsesTwoLineFormat(t *testing.T) {
 	dir := sampleCppProjectDir()
 	strat := &LinkerMapStrategy{}
-	result := strat.ScanWithEdges(dir, true)
+	result := strat.ScanWithEdges(context.Background(), NewFileIndex(dir), nil, logging.Discard())
 
 	// The satisfy section in o1.map has entries like:
 	//   libgcc.a pulled in by build/vddcheck.o
@@ -185,7 +187,7 @@ func TestLinkerMap_RegexMatchesSatisfySectionLines(t *testing.T) {
 func TestLinkerMap_DetectionSource(t *testing.T) {
 	dir := sampleCppProjectDir()
 	strat := &LinkerMapStrategy{}
-	result := strat.ScanWithEdges(dir, false)
+	result := strat.ScanWithEdges(context.Background(), NewFileIndex(dir), nil, logging.Discard())
 
 	for _, c := range result.Components {
 		if c.DetectionSource != "linker-map" {
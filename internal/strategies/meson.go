@@ -1,7 +1,8 @@
 package strategies
 
 import (
-	"fmt"
+	"context"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -23,47 +24,53 @@ var reMesonDependency = regexp.MustCompile(`(?i)dependency\s*\(\s*['"]([A-Za-z0-
 // reMesonVersion matches version: '>=1.2.3' or version: '1.2.3' in dependency calls
 var reMesonVersion = regexp.MustCompile(`version\s*:\s*['"][>=<]*\s*([\d][^\s'"]+)['"]`)
 
+// reMesonProject matches the opening of a project() call
+var reMesonProject = regexp.MustCompile(`(?m)^\s*project\s*\(`)
+
 // reMesonSubproject matches subproject('foo') calls
 var reMesonSubproject = regexp.MustCompile(`(?i)subproject\s*\(\s*['"]([A-Za-z0-9_\-\.]+)['"]`)
 
 // reMesonWrapVersion matches version = x.y.z in .wrap files
 var reMesonWrapVersion = regexp.MustCompile(`(?i)^version\s*=\s*(.+)$`)
 
-// reMesonWrapSource matches source_url or url in .wrap files
-var reMesonWrapSource = regexp.MustCompile(`(?i)^(?:source_url|url)\s*=\s*(.+)$`)
+// reMesonWrapSource matches source_url ([wrap-file]) or url ([wrap-git] and
+// friends) in .wrap files
+var reMesonWrapSource = regexp.MustCompile(`(?i)^(source_url|url)\s*=\s*(.+)$`)
+
+// reMesonWrapDirectory matches directory = name in .wrap files
+var reMesonWrapDirectory = regexp.MustCompile(`(?i)^directory\s*=\s*(.+)$`)
+
+// reMesonProjectLicense matches the license: keyword of a project() call,
+// either a single string or a list of strings
+var reMesonProjectLicense = regexp.MustCompile(`license\s*:\s*(\[[^\]]*\]|'[^']*'|"[^"]*")`)
 
-func (s *MesonStrategy) Scan(projectRoot string, verbose bool) ([]*model.Component, error) {
+// reMesonQuoted matches a single- or double-quoted meson string literal
+var reMesonQuoted = regexp.MustCompile(`'([^']*)'|"([^"]*)"`)
+
+func (s *MesonStrategy) Scan(ctx context.Context, ix *FileIndex, diags *Diagnostics, log *slog.Logger) ([]*model.Component, error) {
 	seen := map[string]*model.Component{}
 
-	_ = filepath.WalkDir(projectRoot, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			return nil
-		}
-		if d.IsDir() {
-			name := d.Name()
-			if strings.HasPrefix(name, ".git") {
-				return filepath.SkipDir
-			}
-			return nil
+	files := ix.Select(func(f File) bool {
+		lname := strings.ToLower(f.Name)
+		return lname == "meson.build" || strings.HasSuffix(lname, ".wrap")
+	})
+	for _, f := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
 		}
-
-		lname := strings.ToLower(d.Name())
+		path := f.Path
+		lname := strings.ToLower(f.Name)
 		switch {
 		case lname == "meson.build":
-			if verbose {
-				fmt.Printf("  [meson] Parsing meson.build: %s\n", path)
-			}
-			parseMesonBuild(path, seen)
+			log.Debug("parsing meson.build", "file", path)
+			parseMesonBuild(path, seen, diags)
 
 		case strings.HasSuffix(lname, ".wrap"):
 			// Meson wrap files in subprojects/
-			if verbose {
-				fmt.Printf("  [meson] Parsing wrap file: %s\n", path)
-			}
-			parseMesonWrap(path, seen)
+			log.Debug("parsing wrap file", "file", path)
+			parseMesonWrap(path, seen, diags)
 		}
-		return nil
-	})
+	}
 
 	result := make([]*model.Component, 0, len(seen))
 	for _, c := range seen {
@@ -72,9 +79,10 @@ func (s *MesonStrategy) Scan(projectRoot string, verbose bool) ([]*model.Compone
 	return result, nil
 }
 
-func parseMesonBuild(path string, seen map[string]*model.Component) {
+func parseMesonBuild(path string, seen map[string]*model.Component, diags *Diagnostics) {
 	data, err := os.ReadFile(path)
 	if err != nil {
+		diags.ReadError(path, err)
 		return
 	}
 	content := string(data)
@@ -89,6 +97,7 @@ func parseMesonBuild(path string, seen map[string]*model.Component) {
 			continue
 		}
 
+		matched := depName
 		fp := fingerprints.MatchLibrary(depName)
 		if fp == nil {
 			fp = &fingerprints.LibraryFingerprint{
@@ -96,6 +105,7 @@ func parseMesonBuild(path string, seen map[string]*model.Component) {
 				PURL:        "pkg:generic/" + strings.ToLower(depName),
 				Description: "Detected via meson dependency()",
 			}
+			matched = ""
 		}
 
 		c, ok := seen[fp.Name]
@@ -109,6 +119,7 @@ func parseMesonBuild(path string, seen map[string]*model.Component) {
 			}
 			seen[fp.Name] = c
 		}
+		c.AddEvidence(evidence("meson", path, lineAt(content, loc[0]), content[loc[0]:loc[1]], matched))
 
 		// Look for version constraint in the next 200 chars after the match
 		end := loc[1] + 200
@@ -126,11 +137,12 @@ func parseMesonBuild(path string, seen map[string]*model.Component) {
 	}
 
 	// Also detect subproject() calls
-	for _, m := range reMesonSubproject.FindAllStringSubmatch(content, -1) {
-		subName := m[1]
+	for _, loc := range reMesonSubproject.FindAllStringSubmatchIndex(content, -1) {
+		subName := content[loc[2]:loc[3]]
 		if isMesonBuiltin(subName) {
 			continue
 		}
+		matched := subName
 		fp := fingerprints.MatchLibrary(subName)
 		if fp == nil {
 			fp = &fingerprints.LibraryFingerprint{
@@ -138,22 +150,27 @@ func parseMesonBuild(path string, seen map[string]*model.Component) {
 				PURL:        "pkg:generic/" + strings.ToLower(subName),
 				Description: "Detected via meson subproject()",
 			}
+			matched = ""
 		}
-		if _, ok := seen[fp.Name]; !ok {
-			seen[fp.Name] = &model.Component{
+		c, ok := seen[fp.Name]
+		if !ok {
+			c = &model.Component{
 				Name:            fp.Name,
 				Version:         "unknown",
 				PURL:            fp.PURL,
 				DetectionSource: "meson",
 				Description:     fp.Description,
 			}
+			seen[fp.Name] = c
 		}
+		c.AddEvidence(evidence("meson", path, lineAt(content, loc[0]), content[loc[0]:loc[1]], matched))
 	}
 }
 
-func parseMesonWrap(path string, seen map[string]*model.Component) {
+func parseMesonWrap(path string, seen map[string]*model.Component, diags *Diagnostics) {
 	data, err := os.ReadFile(path)
 	if err != nil {
+		diags.ReadError(path, err)
 		return
 	}
 
@@ -162,13 +179,28 @@ func parseMesonWrap(path string, seen map[string]*model.Component) {
 	wrapName = strings.ToLower(wrapName)
 
 	var version string
+	directory := wrapName
+	var refs []model.ExternalReference
 	for _, line := range strings.Split(string(data), "\n") {
 		line = strings.TrimSpace(line)
 		if m := reMesonWrapVersion.FindStringSubmatch(line); m != nil {
 			version = strings.TrimSpace(m[1])
 		}
+		if m := reMesonWrapDirectory.FindStringSubmatch(line); m != nil {
+			directory = strings.TrimSpace(m[1])
+		}
+		if m := reMesonWrapSource.FindStringSubmatch(line); m != nil {
+			// [wrap-file] downloads a source archive; the other wrap types
+			// clone a repository.
+			refType := "vcs"
+			if strings.EqualFold(m[1], "source_url") {
+				refType = "distribution"
+			}
+			refs = append(refs, model.ExternalReference{Type: refType, URL: strings.TrimSpace(m[2])})
+		}
 	}
 
+	matched := wrapName
 	fp := fingerprints.MatchLibrary(wrapName)
 	if fp == nil {
 		fp = &fingerprints.LibraryFingerprint{
@@ -176,6 +208,7 @@ func parseMesonWrap(path string, seen map[string]*model.Component) {
 			PURL:        "pkg:generic/" + wrapName,
 			Description: "Detected via meson wrap file",
 		}
+		matched = ""
 	}
 
 	c, ok := seen[fp.Name]
@@ -193,6 +226,55 @@ func parseMesonWrap(path string, seen map[string]*model.Component) {
 		c.Version = version
 		c.PURL = fp.PURL + "@" + version
 	}
+	c.ExternalReferences = append(c.ExternalReferences, refs...)
+	c.AddEvidence(evidence("meson", path, 0, filepath.Base(path), matched))
+
+	// Wraps carry no license; an already fetched subproject declares it in
+	// its own project() call.
+	if len(c.Licenses) == 0 {
+		subBuild := filepath.Join(filepath.Dir(path), directory, "meson.build")
+		if sub, err := os.ReadFile(subBuild); err == nil {
+			c.Licenses = mesonProjectLicenses(string(sub))
+		}
+	}
+}
+
+// mesonProjectLicenses returns the license: values of the project() call in a
+// meson.build file.
+func mesonProjectLicenses(content string) []string {
+	loc := reMesonProject.FindStringIndex(content)
+	if loc == nil {
+		return nil
+	}
+	m := reMesonProjectLicense.FindStringSubmatch(mesonCallArgs(content[loc[1]:]))
+	if m == nil {
+		return nil
+	}
+	var licenses []string
+	for _, q := range reMesonQuoted.FindAllStringSubmatch(m[1], -1) {
+		if l := strings.TrimSpace(q[1] + q[2]); l != "" {
+			licenses = append(licenses, l)
+		}
+	}
+	return licenses
+}
+
+// mesonCallArgs returns the argument text of a call whose opening parenthesis
+// has already been consumed, up to the matching closing parenthesis.
+func mesonCallArgs(content string) string {
+	depth := 1
+	for i, r := range content {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return content[:i]
+			}
+		}
+	}
+	return content
 }
 
 var mesonBuiltins = map[string]bool{
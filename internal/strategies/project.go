@@ -0,0 +1,141 @@
+package strategies
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/StinkyLord/cpp-sbom-builder/internal/model"
+)
+
+// DetectProject derives a component describing the scanned project itself
+// from the build files at the project root. It looks, in order, at the
+// project() call in CMakeLists.txt, the project() call in meson.build, and the
+// name/version attributes of conanfile.py, and returns nil if none of them
+// names the project. The PURL is left to the caller, which may still
+// override the name and version.
+//
+// Only the root directory is consulted: nested CMakeLists.txt and meson.build
+// files describe sub-projects, not the project being scanned.
+func DetectProject(projectRoot string) *model.Component {
+	detectors := []func(string) *model.Component{
+		detectCMakeProject,
+		detectMesonProject,
+		detectConanfileProject,
+	}
+	for _, detect := range detectors {
+		if c := detect(projectRoot); c != nil {
+			if c.Version == "" {
+				c.Version = "unknown"
+			}
+			return c
+		}
+	}
+	return nil
+}
+
+// reCMakeProject matches the project name in project(Name ...)
+var reCMakeProject = regexp.MustCompile(`(?im)^\s*project\s*\(\s*([A-Za-z0-9_\-\.+]+)`)
+
+// reCMakeProjectDescription matches DESCRIPTION "..." in a project() call
+var reCMakeProjectDescription = regexp.MustCompile(`(?i)DESCRIPTION\s+"([^"]*)"`)
+
+// reCMakeProjectHomepage matches HOMEPAGE_URL "..." in a project() call
+var reCMakeProjectHomepage = regexp.MustCompile(`(?i)HOMEPAGE_URL\s+"?([^\s")]+)"?`)
+
+func detectCMakeProject(projectRoot string) *model.Component {
+	data, err := os.ReadFile(filepath.Join(projectRoot, "CMakeLists.txt"))
+	if err != nil {
+		return nil
+	}
+	content := string(data)
+
+	loc := reCMakeProject.FindStringSubmatchIndex(content)
+	if loc == nil {
+		return nil
+	}
+	call := content[loc[0]:]
+	if end := strings.Index(call, ")"); end != -1 {
+		call = call[:end+1]
+	}
+
+	c := &model.Component{
+		Name:            content[loc[2]:loc[3]],
+		DetectionSource: "cmake",
+	}
+	if m := reCMakeProjectVersion.FindStringSubmatch(call); m != nil {
+		c.Version = m[1]
+	}
+	if m := reCMakeProjectDescription.FindStringSubmatch(call); m != nil {
+		c.Description = m[1]
+	}
+	if m := reCMakeProjectHomepage.FindStringSubmatch(call); m != nil {
+		c.Homepage = m[1]
+	}
+	return c
+}
+
+// reMesonProjectName matches the first positional argument of project()
+var reMesonProjectName = regexp.MustCompile(`^\s*['"]([^'"]+)['"]`)
+
+// reMesonProjectVersion matches version: '1.2.3' in a project() call
+var reMesonProjectVersion = regexp.MustCompile(`version\s*:\s*['"]([^'"]+)['"]`)
+
+func detectMesonProject(projectRoot string) *model.Component {
+	data, err := os.ReadFile(filepath.Join(projectRoot, "meson.build"))
+	if err != nil {
+		return nil
+	}
+	content := string(data)
+
+	loc := reMesonProject.FindStringIndex(content)
+	if loc == nil {
+		return nil
+	}
+	args := mesonCallArgs(content[loc[1]:])
+	m := reMesonProjectName.FindStringSubmatch(args)
+	if m == nil {
+		return nil
+	}
+
+	c := &model.Component{
+		Name:            m[1],
+		DetectionSource: "meson",
+		Licenses:        mesonProjectLicenses(content),
+	}
+	if vm := reMesonProjectVersion.FindStringSubmatch(args); vm != nil {
+		c.Version = vm[1]
+	}
+	return c
+}
+
+// reConanfileAttr matches class attributes such as name = "foo" in conanfile.py
+var reConanfileAttr = regexp.MustCompile(`(?m)^\s+(name|version|description|homepage|license)\s*=\s*["']([^"']+)["']`)
+
+func detectConanfileProject(projectRoot string) *model.Component {
+	data, err := os.ReadFile(filepath.Join(projectRoot, "conanfile.py"))
+	if err != nil {
+		return nil
+	}
+
+	c := &model.Component{DetectionSource: "conan"}
+	for _, m := range reConanfileAttr.FindAllStringSubmatch(string(data), -1) {
+		switch m[1] {
+		case "name":
+			c.Name = m[2]
+		case "version":
+			c.Version = m[2]
+		case "description":
+			c.Description = m[2]
+		case "homepage":
+			c.Homepage = m[2]
+		case "license":
+			c.Licenses = []string{m[2]}
+		}
+	}
+	if c.Name == "" {
+		return nil
+	}
+	return c
+}
@@ -1,10 +1,14 @@
 package strategies
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"runtime"
 	"testing"
+
+	"github.com/StinkyLord/cpp-sbom-builder/internal/logging"
+	"github.com/StinkyLord/cpp-sbom-builder/internal/model"
 )
 
 // testdataDir returns the absolute path to testdata/strategies.
@@ -36,7 +40,7 @@ type componentLike struct {
 func TestConanfileTxt_RequiresSection(t *testing.T) {
 	dir := testdataDir()
 	strat := &ConanStrategy{}
-	result := strat.ScanWithGraph(dir, false)
+	result := strat.ScanWithGraph(context.Background(), NewFileIndex(dir), nil, logging.Discard())
 
 	byName := map[string]bool{}
 	for _, c := range result.Components {
@@ -54,7 +58,7 @@ func TestConanfileTxt_RequiresSection(t *testing.T) {
 func TestConanfileTxt_BuildRequiresSection(t *testing.T) {
 	dir := testdataDir()
 	strat := &ConanStrategy{}
-	result := strat.ScanWithGraph(dir, false)
+	result := strat.ScanWithGraph(context.Background(), NewFileIndex(dir), nil, logging.Discard())
 
 	byName := map[string]bool{}
 	for _, c := range result.Components {
@@ -72,7 +76,7 @@ func TestConanfileTxt_BuildRequiresSection(t *testing.T) {
 func TestConanfileTxt_DirectNames(t *testing.T) {
 	dir := testdataDir()
 	strat := &ConanStrategy{}
-	result := strat.ScanWithGraph(dir, false)
+	result := strat.ScanWithGraph(context.Background(), NewFileIndex(dir), nil, logging.Discard())
 
 	// Everything in [requires] and [build_requires] is direct
 	for _, want := range []string{"boost", "openssl", "zlib", "nlohmann_json", "cmake", "ninja"} {
@@ -85,7 +89,7 @@ func TestConanfileTxt_DirectNames(t *testing.T) {
 func TestConanfileTxt_Channel(t *testing.T) {
 	// Parse conanfile.txt directly (not the whole dir) to avoid merging with other files
 	txtPath := filepath.Join(testdataDir(), "conanfile.txt")
-	comps, _ := parseConanfileTxtWithDirect(txtPath)
+	comps, _ := parseConanfileTxtWithDirect(txtPath, nil)
 
 	// openssl/3.1.4@conan/stable — channel should be captured
 	for _, c := range comps {
@@ -108,7 +112,7 @@ func TestConanfileTxt_Channel(t *testing.T) {
 func TestConanfileTxt_Revision(t *testing.T) {
 	// Parse conanfile.txt directly to avoid merging with conan.lock (which has no revision for zlib)
 	txtPath := filepath.Join(testdataDir(), "conanfile.txt")
-	comps, _ := parseConanfileTxtWithDirect(txtPath)
+	comps, _ := parseConanfileTxtWithDirect(txtPath, nil)
 
 	// zlib/1.2.13#abc123def456 — revision should be captured
 	for _, c := range comps {
@@ -129,7 +133,7 @@ func TestConanfileTxt_Revision(t *testing.T) {
 func TestConanfilePy_SelfRequires(t *testing.T) {
 	dir := testdataDir()
 	strat := &ConanStrategy{}
-	result := strat.ScanWithGraph(dir, false)
+	result := strat.ScanWithGraph(context.Background(), NewFileIndex(dir), nil, logging.Discard())
 
 	byName := map[string]bool{}
 	for _, c := range result.Components {
@@ -147,7 +151,7 @@ func TestConanfilePy_SelfRequires(t *testing.T) {
 func TestConanfilePy_BuildRequires(t *testing.T) {
 	dir := testdataDir()
 	strat := &ConanStrategy{}
-	result := strat.ScanWithGraph(dir, false)
+	result := strat.ScanWithGraph(context.Background(), NewFileIndex(dir), nil, logging.Discard())
 
 	byName := map[string]bool{}
 	for _, c := range result.Components {
@@ -163,7 +167,7 @@ func TestConanfilePy_BuildRequires(t *testing.T) {
 func TestConanfilePy_PythonRequires(t *testing.T) {
 	dir := testdataDir()
 	strat := &ConanStrategy{}
-	result := strat.ScanWithGraph(dir, false)
+	result := strat.ScanWithGraph(context.Background(), NewFileIndex(dir), nil, logging.Discard())
 
 	byName := map[string]bool{}
 	for _, c := range result.Components {
@@ -179,7 +183,7 @@ func TestConanfilePy_PythonRequires(t *testing.T) {
 func TestConanfilePy_ListSyntax(t *testing.T) {
 	dir := testdataDir()
 	strat := &ConanStrategy{}
-	result := strat.ScanWithGraph(dir, false)
+	result := strat.ScanWithGraph(context.Background(), NewFileIndex(dir), nil, logging.Discard())
 
 	byName := map[string]bool{}
 	for _, c := range result.Components {
@@ -197,7 +201,7 @@ func TestConanfilePy_ListSyntax(t *testing.T) {
 func TestConanfilePy_RevisionInSelfRequires(t *testing.T) {
 	dir := testdataDir()
 	strat := &ConanStrategy{}
-	result := strat.ScanWithGraph(dir, false)
+	result := strat.ScanWithGraph(context.Background(), NewFileIndex(dir), nil, logging.Discard())
 
 	// openssl/3.1.4@conan/stable#deadbeef1234
 	for _, c := range result.Components {
@@ -221,7 +225,7 @@ func TestConanfilePy_RevisionInSelfRequires(t *testing.T) {
 func TestConanLockV1_Components(t *testing.T) {
 	dir := testdataDir()
 	strat := &ConanStrategy{}
-	result := strat.ScanWithGraph(dir, false)
+	result := strat.ScanWithGraph(context.Background(), NewFileIndex(dir), nil, logging.Discard())
 
 	byName := map[string]bool{}
 	for _, c := range result.Components {
@@ -239,7 +243,7 @@ func TestConanLockV1_Components(t *testing.T) {
 func TestConanLockV1_DirectNames(t *testing.T) {
 	// Parse only the lock file directly to test graph edges
 	lockPath := filepath.Join(testdataDir(), "conan.lock")
-	result := parseConanLockWithGraph(lockPath)
+	result := parseConanLockWithGraph(lockPath, nil)
 
 	// Node "0" requires nodes "1" (boost) and "2" (openssl) → both are direct
 	if !result.DirectNames["boost"] {
@@ -256,7 +260,7 @@ func TestConanLockV1_DirectNames(t *testing.T) {
 
 func TestConanLockV1_Edges(t *testing.T) {
 	lockPath := filepath.Join(testdataDir(), "conan.lock")
-	result := parseConanLockWithGraph(lockPath)
+	result := parseConanLockWithGraph(lockPath, nil)
 
 	// boost → zlib
 	boostDeps := result.Edges["boost"]
@@ -285,7 +289,7 @@ func TestConanLockV1_Edges(t *testing.T) {
 
 func TestConanLockV1_Revision(t *testing.T) {
 	lockPath := filepath.Join(testdataDir(), "conan.lock")
-	result := parseConanLockWithGraph(lockPath)
+	result := parseConanLockWithGraph(lockPath, nil)
 
 	// boost/1.82.0#rev001 — revision should be captured
 	for _, c := range result.Components {
@@ -306,7 +310,7 @@ func TestConanLockV1_Revision(t *testing.T) {
 func TestHeaderScan_DetectsThirdParty(t *testing.T) {
 	dir := testdataDir()
 	strat := &HeadersStrategy{}
-	comps, err := strat.Scan(dir, false)
+	comps, err := strat.Scan(context.Background(), NewFileIndex(dir), nil, logging.Discard())
 	if err != nil {
 		t.Fatalf("HeadersStrategy.Scan failed: %v", err)
 	}
@@ -327,7 +331,7 @@ func TestHeaderScan_DetectsThirdParty(t *testing.T) {
 func TestHeaderScan_IgnoresStdlib(t *testing.T) {
 	dir := testdataDir()
 	strat := &HeadersStrategy{}
-	comps, err := strat.Scan(dir, false)
+	comps, err := strat.Scan(context.Background(), NewFileIndex(dir), nil, logging.Discard())
 	if err != nil {
 		t.Fatalf("HeadersStrategy.Scan failed: %v", err)
 	}
@@ -348,7 +352,7 @@ func TestHeaderScan_IgnoresStdlib(t *testing.T) {
 func TestHeaderScan_IgnoresInternalHeaders(t *testing.T) {
 	dir := testdataDir()
 	strat := &HeadersStrategy{}
-	comps, err := strat.Scan(dir, false)
+	comps, err := strat.Scan(context.Background(), NewFileIndex(dir), nil, logging.Discard())
 	if err != nil {
 		t.Fatalf("HeadersStrategy.Scan failed: %v", err)
 	}
@@ -367,7 +371,7 @@ func TestHeaderScan_IgnoresInternalHeaders(t *testing.T) {
 func TestHeaderScan_DetectionSource(t *testing.T) {
 	dir := testdataDir()
 	strat := &HeadersStrategy{}
-	comps, err := strat.Scan(dir, false)
+	comps, err := strat.Scan(context.Background(), NewFileIndex(dir), nil, logging.Discard())
 	if err != nil {
 		t.Fatalf("HeadersStrategy.Scan failed: %v", err)
 	}
@@ -386,7 +390,7 @@ func TestHeaderScan_DetectionSource(t *testing.T) {
 func TestCompileCommands_DetectsExternalIncludes(t *testing.T) {
 	dir := testdataDir()
 	strat := &CompileCommandsStrategy{}
-	comps, err := strat.Scan(dir, false)
+	comps, err := strat.Scan(context.Background(), NewFileIndex(dir), nil, logging.Discard())
 	if err != nil {
 		t.Fatalf("CompileCommandsStrategy.Scan failed: %v", err)
 	}
@@ -409,7 +413,7 @@ func TestCompileCommands_DetectsExternalIncludes(t *testing.T) {
 func TestCompileCommands_ExtractsVersionFromPath(t *testing.T) {
 	dir := testdataDir()
 	strat := &CompileCommandsStrategy{}
-	comps, err := strat.Scan(dir, false)
+	comps, err := strat.Scan(context.Background(), NewFileIndex(dir), nil, logging.Discard())
 	if err != nil {
 		t.Fatalf("CompileCommandsStrategy.Scan failed: %v", err)
 	}
@@ -431,7 +435,7 @@ func TestCompileCommands_ExtractsVersionFromPath(t *testing.T) {
 func TestCompileCommands_IgnoresInternalPaths(t *testing.T) {
 	dir := testdataDir()
 	strat := &CompileCommandsStrategy{}
-	comps, err := strat.Scan(dir, false)
+	comps, err := strat.Scan(context.Background(), NewFileIndex(dir), nil, logging.Discard())
 	if err != nil {
 		t.Fatalf("CompileCommandsStrategy.Scan failed: %v", err)
 	}
@@ -573,7 +577,10 @@ func TestConanGraph_ParsesComponents(t *testing.T) {
 		t.Fatalf("cannot read graph.json: %v", err)
 	}
 
-	result := parseConanGraphJSON(data)
+	result, err := parseConanGraphJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	byName := map[string]string{}
 	for _, c := range result.Components {
@@ -607,7 +614,10 @@ func TestConanGraph_DirectNames(t *testing.T) {
 		t.Fatalf("cannot read graph.json: %v", err)
 	}
 
-	result := parseConanGraphJSON(data)
+	result, err := parseConanGraphJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	// Node "0" (consumer) has direct=true edges to boost and zlib
 	if !result.DirectNames["boost"] {
@@ -625,7 +635,10 @@ func TestConanGraph_Edges(t *testing.T) {
 		t.Fatalf("cannot read graph.json: %v", err)
 	}
 
-	result := parseConanGraphJSON(data)
+	result, err := parseConanGraphJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	// boost → zlib (runtime edge, not build)
 	boostDeps := result.Edges["boost"]
@@ -647,7 +660,10 @@ func TestConanGraph_PURL(t *testing.T) {
 		t.Fatalf("cannot read graph.json: %v", err)
 	}
 
-	result := parseConanGraphJSON(data)
+	result, err := parseConanGraphJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	for _, c := range result.Components {
 		if c.Name == "boost" {
@@ -671,7 +687,10 @@ func TestConanGraph_Revision(t *testing.T) {
 		t.Fatalf("cannot read graph.json: %v", err)
 	}
 
-	result := parseConanGraphJSON(data)
+	result, err := parseConanGraphJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	for _, c := range result.Components {
 		if c.Name == "boost" {
@@ -691,7 +710,10 @@ func TestConanGraph_DetectionSource(t *testing.T) {
 		t.Fatalf("cannot read graph.json: %v", err)
 	}
 
-	result := parseConanGraphJSON(data)
+	result, err := parseConanGraphJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	for _, c := range result.Components {
 		if c.DetectionSource != "conan-graph" {
@@ -700,21 +722,245 @@ func TestConanGraph_DetectionSource(t *testing.T) {
 	}
 }
 
+func TestConanGraph_LicenseAndHomepage(t *testing.T) {
+	graphPath := filepath.Join(testdataDir(), "graph.json")
+	data, err := os.ReadFile(graphPath)
+	if err != nil {
+		t.Fatalf("cannot read graph.json: %v", err)
+	}
+
+	result, err := parseConanGraphJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range result.Components {
+		if c.Name == "boost" {
+			if len(c.Licenses) != 1 || c.Licenses[0] != "BSL-1.0" {
+				t.Errorf("boost licenses = %v, want [BSL-1.0]", c.Licenses)
+			}
+			if c.Homepage != "https://www.boost.org" {
+				t.Errorf("boost homepage = %q, want https://www.boost.org", c.Homepage)
+			}
+			if c.Description == c.Homepage {
+				t.Error("boost description should not fall back to the homepage")
+			}
+			return
+		}
+	}
+	t.Error("boost not found in conan-graph components")
+}
+
+func TestConanLicenses_List(t *testing.T) {
+	got := conanLicenses([]any{"MIT", " Apache-2.0 ", "MIT", 42})
+	if len(got) != 2 || got[0] != "MIT" || got[1] != "Apache-2.0" {
+		t.Errorf("conanLicenses = %v, want [MIT Apache-2.0]", got)
+	}
+	if got := conanLicenses(nil); got != nil {
+		t.Errorf("conanLicenses(nil) = %v, want nil", got)
+	}
+}
+
+// ============================================================
+// vcpkg / meson: package metadata
+// ============================================================
+
+func TestVcpkg_PortManifestMetadata(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "vcpkg.json"), `{"name": "app", "dependencies": ["fmt"]}`)
+	writeTestFile(t, filepath.Join(dir, "ports", "fmt", "vcpkg.json"), `{
+		"name": "fmt",
+		"version": "10.2.1",
+		"description": ["Formatting library", "for C++"],
+		"homepage": "https://github.com/fmtlib/fmt",
+		"license": "MIT"
+	}`)
+
+	comps, _ := (&VcpkgStrategy{}).Scan(context.Background(), NewFileIndex(dir), nil, logging.Discard())
+	for _, c := range comps {
+		if c.Name != "fmt" {
+			continue
+		}
+		if len(c.Licenses) != 1 || c.Licenses[0] != "MIT" {
+			t.Errorf("fmt licenses = %v, want [MIT]", c.Licenses)
+		}
+		if c.Homepage != "https://github.com/fmtlib/fmt" {
+			t.Errorf("fmt homepage = %q", c.Homepage)
+		}
+		return
+	}
+	t.Error("vcpkg: expected 'fmt' component")
+}
+
+func TestMeson_WrapMetadata(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "subprojects", "mylib.wrap"), `[wrap-file]
+directory = mylib-1.3
+source_url = https://example.com/mylib-1.3.tar.gz
+version = 1.3
+`)
+	writeTestFile(t, filepath.Join(dir, "subprojects", "mylib-1.3", "meson.build"),
+		"project('mylib', 'c',\n  version : '1.3',\n  license : ['MIT', 'Zlib'])\n")
+
+	comps, _ := (&MesonStrategy{}).Scan(context.Background(), NewFileIndex(dir), nil, logging.Discard())
+	for _, c := range comps {
+		if c.Name != "mylib" {
+			continue
+		}
+		if len(c.Licenses) != 2 || c.Licenses[0] != "MIT" || c.Licenses[1] != "Zlib" {
+			t.Errorf("mylib licenses = %v, want [MIT Zlib]", c.Licenses)
+		}
+		if len(c.ExternalReferences) != 1 || c.ExternalReferences[0].Type != "distribution" ||
+			c.ExternalReferences[0].URL != "https://example.com/mylib-1.3.tar.gz" {
+			t.Errorf("mylib external references = %+v", c.ExternalReferences)
+		}
+		return
+	}
+	t.Error("meson: expected 'mylib' component")
+}
+
+// ============================================================
+// Artifacts: hashes of file-backed components
+// ============================================================
+
+func TestLdd_RecordsArtifacts(t *testing.T) {
+	dir := t.TempDir()
+	libDir := t.TempDir()
+	sslPath := filepath.Join(libDir, "libssl.so.3")
+	curlPath := filepath.Join(libDir, "libcurl.so.4")
+	writeTestFile(t, sslPath, "abc")
+	writeTestFile(t, curlPath, "curl")
+	t.Setenv("SBOM_LDD_RESULTS", "")
+	writeTestFile(t, filepath.Join(dir, "ldd-results.json"), `{"results": [{
+		"library": "`+filepath.ToSlash(sslPath)+`",
+		"deps": [{"name": "libcurl.so.4", "path": "`+filepath.ToSlash(curlPath)+`"}]
+	}]}`)
+
+	result := (&LddStrategy{}).ScanWithEdges(context.Background(), NewFileIndex(dir), nil, logging.Discard())
+
+	byName := map[string][]string{}
+	for _, c := range result.Components {
+		for _, a := range c.Artifacts {
+			byName[c.Name] = append(byName[c.Name], a.Path)
+		}
+	}
+	if len(byName) != 2 {
+		t.Fatalf("expected artifacts on 2 components, got %v", byName)
+	}
+
+	for _, c := range result.Components {
+		if len(c.Artifacts) != 1 || filepath.Base(c.Artifacts[0].Path) != "libssl.so.3" {
+			continue
+		}
+		a := c.Artifacts[0]
+		if a.Size != 3 {
+			t.Errorf("libssl size = %d, want 3", a.Size)
+		}
+		if len(a.Hashes) != 2 || a.Hashes[0].Algorithm != "SHA-256" || a.Hashes[1].Algorithm != "SHA-512" {
+			t.Fatalf("libssl hashes = %+v, want SHA-256 and SHA-512", a.Hashes)
+		}
+		if want := "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"; a.Hashes[0].Value != want {
+			t.Errorf("libssl SHA-256 = %s, want %s", a.Hashes[0].Value, want)
+		}
+		return
+	}
+	t.Error("no component carries the libssl.so.3 artifact")
+}
+
+func TestAddArtifact_SkipsMissingFiles(t *testing.T) {
+	c := &model.Component{Name: "openssl"}
+	addArtifact(c, filepath.Join(t.TempDir(), "missing.so"))
+	addArtifact(c, t.TempDir())
+	if len(c.Artifacts) != 0 {
+		t.Errorf("expected no artifacts for missing files and directories, got %+v", c.Artifacts)
+	}
+}
+
 func TestConanGraph_PassiveMode_FindsExistingFile(t *testing.T) {
 	// The testdata/strategies directory has a graph.json — passive mode should find it
 	dir := testdataDir()
 	strat := &ConanGraphStrategy{RunConan: false}
-	result := strat.ScanWithGraph(dir, false)
+	result := strat.ScanWithGraph(context.Background(), NewFileIndex(dir), nil, logging.Discard())
 
 	if len(result.Components) == 0 {
 		t.Error("conan-graph passive mode: expected components from graph.json, got none")
 	}
 }
 
+// TestConanGraph_RunConan_RemovesTempFiles runs a stand-in conan that prints
+// a graph and verifies the temp file it was written to is gone afterwards.
+func TestConanGraph_RunConan_RemovesTempFiles(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stand-in conan is a shell script")
+	}
+	bin := t.TempDir()
+	script := "#!/bin/sh\ncat '" + filepath.Join(testdataDir(), "graph.json") + "'\n"
+	if err := os.WriteFile(filepath.Join(bin, "conan"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "conanfile.txt"), "[requires]\nzlib/1.2.13\n")
+	strat := &ConanGraphStrategy{RunConan: true}
+	result := strat.ScanWithGraph(context.Background(), NewFileIndex(dir), NewDiagnostics("conan-graph"), logging.Discard())
+	if len(result.Components) == 0 {
+		t.Error("expected components from the conan graph, got none")
+	}
+	if left, _ := os.ReadDir(tmp); len(left) != 0 {
+		t.Errorf("temp files left behind: %v", left)
+	}
+}
+
+// TestConan_Diagnostics verifies that a conan.lock that fails to parse is
+// reported, where a project without dependencies reports nothing.
+func TestConan_Diagnostics(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "conan.lock"), "{\n  \"version\": \"0.5\",\n  \"requires\": [\n")
+	diags := NewDiagnostics("conan")
+	comps, err := (&ConanStrategy{}).Scan(context.Background(), NewFileIndex(dir), diags, logging.Discard())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(comps) != 0 {
+		t.Errorf("expected no components, got %d", len(comps))
+	}
+	list := diags.List()
+	if len(list) != 1 {
+		t.Fatalf("got %d diagnostic(s), want 1: %v", len(list), list)
+	}
+	d := list[0]
+	if d.Severity != model.SeverityError || d.Strategy != "conan" || d.Line != 4 || !containsStr(d.Message, "invalid JSON") {
+		t.Errorf("diagnostic = %s", d)
+	}
+
+	empty := t.TempDir()
+	writeTestFile(t, filepath.Join(empty, "conan.lock"), `{"version": "0.5", "requires": []}`)
+	diags = NewDiagnostics("conan")
+	if _, err := (&ConanStrategy{}).Scan(context.Background(), NewFileIndex(empty), diags, logging.Discard()); err != nil {
+		t.Fatal(err)
+	}
+	if list := diags.List(); len(list) != 0 {
+		t.Errorf("empty lock: got diagnostics %v", list)
+	}
+}
+
 // ============================================================
 // helpers
 // ============================================================
 
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func keys(m map[string]bool) []string {
 	result := make([]string, 0, len(m))
 	for k := range m {
@@ -734,3 +980,131 @@ func containsStr(s, sub string) bool {
 			return false
 		}())
 }
+
+// ============================================================
+// DetectProject: the scanned project itself
+// ============================================================
+
+func TestDetectProject_CMake(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "CMakeLists.txt"), `cmake_minimum_required(VERSION 3.20)
+project(MyApp
+  VERSION 2.4.1
+  DESCRIPTION "An example application"
+  HOMEPAGE_URL "https://example.com/myapp"
+  LANGUAGES CXX)
+add_subdirectory(vendor)
+`)
+	writeTestFile(t, filepath.Join(dir, "vendor", "CMakeLists.txt"), "project(Vendored VERSION 9.9)\n")
+
+	p := DetectProject(dir)
+	if p == nil {
+		t.Fatal("expected a project component")
+	}
+	if p.Name != "MyApp" || p.Version != "2.4.1" || p.DetectionSource != "cmake" {
+		t.Errorf("project = %s@%s (%s), want MyApp@2.4.1 (cmake)", p.Name, p.Version, p.DetectionSource)
+	}
+	if p.Description != "An example application" || p.Homepage != "https://example.com/myapp" {
+		t.Errorf("description/homepage = %q / %q", p.Description, p.Homepage)
+	}
+}
+
+func TestDetectProject_Meson(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "meson.build"),
+		"project('mytool', 'cpp',\n  version : '0.3.0',\n  license : 'Apache-2.0')\n")
+
+	p := DetectProject(dir)
+	if p == nil || p.Name != "mytool" || p.Version != "0.3.0" {
+		t.Fatalf("project = %+v, want mytool@0.3.0", p)
+	}
+	if len(p.Licenses) != 1 || p.Licenses[0] != "Apache-2.0" {
+		t.Errorf("licenses = %v, want [Apache-2.0]", p.Licenses)
+	}
+}
+
+func TestDetectProject_Conanfile(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "conanfile.py"), `from conan import ConanFile
+
+class MyLibConan(ConanFile):
+    name = "mylib"
+    version = "1.5.0"
+    license = "MIT"
+    requires = "zlib/1.2.13"
+`)
+
+	p := DetectProject(dir)
+	if p == nil || p.Name != "mylib" || p.Version != "1.5.0" || p.DetectionSource != "conan" {
+		t.Fatalf("project = %+v, want mylib@1.5.0 from conan", p)
+	}
+}
+
+func TestDetectProject_None(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "CMakeLists.txt"), "add_executable(app main.cpp)\n")
+	if p := DetectProject(dir); p != nil {
+		t.Errorf("expected no project, got %+v", p)
+	}
+}
+
+// ============================================================
+// Detection evidence
+// ============================================================
+
+func TestConanfileTxt_Evidence(t *testing.T) {
+	dir := t.TempDir()
+	data, err := os.ReadFile(filepath.Join(testdataDir(), "conanfile.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "conanfile.txt"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	strat := &ConanStrategy{}
+	result := strat.ScanWithGraph(context.Background(), NewFileIndex(dir), nil, logging.Discard())
+
+	for _, c := range result.Components {
+		if c.Name != "openssl" {
+			continue
+		}
+		want := model.Evidence{
+			Strategy: "conan",
+			File:     filepath.Join(dir, "conanfile.txt"),
+			Line:     3,
+			Text:     "openssl/3.1.4@conan/stable",
+		}
+		if len(c.Evidence) != 1 || c.Evidence[0] != want {
+			t.Errorf("evidence = %+v, want [%+v]", c.Evidence, want)
+		}
+		return
+	}
+	t.Error("openssl not found in conanfile.txt components")
+}
+
+func TestHeaderScan_Evidence(t *testing.T) {
+	dir := testdataDir()
+	strat := &HeadersStrategy{}
+	comps, err := strat.Scan(context.Background(), NewFileIndex(dir), nil, logging.Discard())
+	if err != nil {
+		t.Fatalf("HeadersStrategy.Scan failed: %v", err)
+	}
+
+	for _, c := range comps {
+		if c.Name != "openssl" {
+			continue
+		}
+		if len(c.Evidence) == 0 {
+			t.Fatal("openssl has no evidence")
+		}
+		e := c.Evidence[0]
+		if filepath.Base(e.File) != "main.cpp" || e.Line != 15 || e.Text != "#include <openssl/ssl.h>" {
+			t.Errorf("evidence = %+v, want main.cpp:15 #include <openssl/ssl.h>", e)
+		}
+		if e.Fingerprint != `openssl (path segment "openssl")` {
+			t.Errorf("Fingerprint = %q", e.Fingerprint)
+		}
+		return
+	}
+	t.Error("openssl not detected by header-scan")
+}
@@ -1,8 +1,9 @@
 package strategies
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
@@ -15,6 +16,10 @@ import (
 //   - vcpkg.json          (manifest mode)
 //   - vcpkg-lock.json     (lock file)
 //   - installed/vcpkg/status (classic mode installed packages)
+//
+// Port manifests (ports/<name>/vcpkg.json in overlay ports or a vendored
+// registry) are also vcpkg.json files; their license, homepage and
+// description are attached to the component of the same name.
 type VcpkgStrategy struct{}
 
 func (s *VcpkgStrategy) Name() string { return "vcpkg" }
@@ -26,6 +31,16 @@ type vcpkgManifest struct {
 	Dependencies []vcpkgDependency `json:"dependencies"`
 }
 
+// vcpkgPortMetadata holds the package metadata fields of a vcpkg.json.
+// "license" is an SPDX expression (or null); "description" is a string or
+// an array of lines.
+type vcpkgPortMetadata struct {
+	Name        string          `json:"name"`
+	License     *string         `json:"license"`
+	Homepage    string          `json:"homepage"`
+	Description json.RawMessage `json:"description"`
+}
+
 type vcpkgDependency struct {
 	Name    string `json:"name"`
 	Version string `json:"version"`
@@ -41,57 +56,53 @@ type vcpkgLockPackage struct {
 	Version string `json:"version"`
 }
 
-func (s *VcpkgStrategy) Scan(projectRoot string, verbose bool) ([]*model.Component, error) {
+func (s *VcpkgStrategy) Scan(ctx context.Context, ix *FileIndex, diags *Diagnostics, log *slog.Logger) ([]*model.Component, error) {
 	var components []*model.Component
+	ports := map[string]*vcpkgPortMetadata{}
 
-	_ = filepath.WalkDir(projectRoot, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			return nil
-		}
-		if d.IsDir() {
-			name := d.Name()
-			if strings.HasPrefix(name, ".git") {
-				return filepath.SkipDir
-			}
-			return nil
+	for _, f := range ix.Named("vcpkg.json", "vcpkg-lock.json", "status") {
+		if err := ctx.Err(); err != nil {
+			return nil, err
 		}
-
-		lname := strings.ToLower(d.Name())
-		switch lname {
+		path := f.Path
+		switch strings.ToLower(f.Name) {
 		case "vcpkg.json":
-			if verbose {
-				fmt.Printf("  [vcpkg] Parsing vcpkg.json: %s\n", path)
-			}
-			comps := parseVcpkgManifest(path)
+			log.Debug("parsing vcpkg.json", "file", path)
+			comps := parseVcpkgManifest(path, diags)
 			components = append(components, comps...)
+			if meta := parseVcpkgPortMetadata(path); meta != nil {
+				ports[strings.ToLower(meta.Name)] = meta
+			}
 
 		case "vcpkg-lock.json":
-			if verbose {
-				fmt.Printf("  [vcpkg] Parsing vcpkg-lock.json: %s\n", path)
-			}
-			comps := parseVcpkgLock(path)
+			log.Debug("parsing vcpkg-lock.json", "file", path)
+			comps := parseVcpkgLock(path, diags)
 			components = append(components, comps...)
 
 		case "status":
 			// vcpkg classic mode: installed/vcpkg/status
 			if strings.Contains(filepath.ToSlash(path), "vcpkg/status") ||
 				strings.Contains(filepath.ToSlash(path), "installed/vcpkg") {
-				if verbose {
-					fmt.Printf("  [vcpkg] Parsing vcpkg status: %s\n", path)
-				}
-				comps := parseVcpkgStatus(path)
+				log.Debug("parsing vcpkg status", "file", path)
+				comps := parseVcpkgStatus(path, diags)
 				components = append(components, comps...)
 			}
 		}
-		return nil
-	})
+	}
+
+	for _, c := range components {
+		if meta := ports[strings.ToLower(c.Name)]; meta != nil {
+			applyVcpkgPortMetadata(c, meta)
+		}
+	}
 
 	return components, nil
 }
 
-func parseVcpkgManifest(path string) []*model.Component {
+func parseVcpkgManifest(path string, diags *Diagnostics) []*model.Component {
 	data, err := os.ReadFile(path)
 	if err != nil {
+		diags.ReadError(path, err)
 		return nil
 	}
 
@@ -100,6 +111,7 @@ func parseVcpkgManifest(path string) []*model.Component {
 		Dependencies []json.RawMessage `json:"dependencies"`
 	}
 	if err := json.Unmarshal(data, &raw); err != nil {
+		diags.ParseError(path, data, err)
 		return nil
 	}
 
@@ -109,6 +121,7 @@ func parseVcpkgManifest(path string) []*model.Component {
 		var name string
 		if err := json.Unmarshal(dep, &name); err == nil {
 			c := makeVcpkgComponent(name, "unknown")
+			c.AddEvidence(evidence("vcpkg", path, lineOf(string(data), string(dep)), name, ""))
 			components = append(components, c)
 			continue
 		}
@@ -116,27 +129,65 @@ func parseVcpkgManifest(path string) []*model.Component {
 		var obj vcpkgDependency
 		if err := json.Unmarshal(dep, &obj); err == nil && obj.Name != "" {
 			c := makeVcpkgComponent(obj.Name, obj.Version)
+			c.AddEvidence(evidence("vcpkg", path, lineOf(string(data), `"`+obj.Name+`"`), obj.Name, ""))
 			components = append(components, c)
 		}
 	}
 	return components
 }
 
-func parseVcpkgLock(path string) []*model.Component {
+// parseVcpkgPortMetadata returns the name, license and homepage declared by a
+// vcpkg.json, or nil if it declares none of them.
+func parseVcpkgPortMetadata(path string) *vcpkgPortMetadata {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil
 	}
+	var meta vcpkgPortMetadata
+	if err := json.Unmarshal(data, &meta); err != nil || meta.Name == "" {
+		return nil
+	}
+	if meta.License == nil && meta.Homepage == "" && len(meta.Description) == 0 {
+		return nil
+	}
+	return &meta
+}
+
+func applyVcpkgPortMetadata(c *model.Component, meta *vcpkgPortMetadata) {
+	if meta.License != nil && *meta.License != "" && len(c.Licenses) == 0 {
+		c.Licenses = []string{*meta.License}
+	}
+	if c.Homepage == "" {
+		c.Homepage = meta.Homepage
+	}
+	if c.Description == "" && len(meta.Description) > 0 {
+		var line string
+		var lines []string
+		if err := json.Unmarshal(meta.Description, &line); err == nil {
+			c.Description = line
+		} else if err := json.Unmarshal(meta.Description, &lines); err == nil {
+			c.Description = strings.Join(lines, " ")
+		}
+	}
+}
+
+func parseVcpkgLock(path string, diags *Diagnostics) []*model.Component {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		diags.ReadError(path, err)
+		return nil
+	}
 
 	// vcpkg-lock.json has various formats across versions.
 	// Try the packages map format.
 	var lock vcpkgLock
 	if err := json.Unmarshal(data, &lock); err == nil && len(lock.Packages) > 0 {
 		var components []*model.Component
-		for name, pkg := range lock.Packages {
+		for key, pkg := range lock.Packages {
 			// Strip triplet suffix: "boost:x64-windows" -> "boost"
-			name = strings.SplitN(name, ":", 2)[0]
+			name := strings.SplitN(key, ":", 2)[0]
 			c := makeVcpkgComponent(name, pkg.Version)
+			c.AddEvidence(evidence("vcpkg", path, lineOf(string(data), `"`+key+`"`), key, ""))
 			components = append(components, c)
 		}
 		return components
@@ -152,12 +203,17 @@ func parseVcpkgLock(path string) []*model.Component {
 		for _, item := range arr {
 			if item.Name != "" {
 				c := makeVcpkgComponent(item.Name, item.Version)
+				c.AddEvidence(evidence("vcpkg", path, lineOf(string(data), `"`+item.Name+`"`), item.Name, ""))
 				components = append(components, c)
 			}
 		}
 		return components
 	}
 
+	var probe any
+	if err := json.Unmarshal(data, &probe); err != nil {
+		diags.ParseError(path, data, err)
+	}
 	return nil
 }
 
@@ -167,23 +223,29 @@ func parseVcpkgLock(path string) []*model.Component {
 //	Package: boost-system
 //	Version: 1.82.0
 //	Status: install ok installed
-func parseVcpkgStatus(path string) []*model.Component {
+func parseVcpkgStatus(path string, diags *Diagnostics) []*model.Component {
 	data, err := os.ReadFile(path)
 	if err != nil {
+		diags.ReadError(path, err)
 		return nil
 	}
 
 	var components []*model.Component
 	var curName, curVersion string
+	var curLine int
 	installed := false
+	add := func() {
+		c := makeVcpkgComponent(curName, curVersion)
+		c.AddEvidence(evidence("vcpkg", path, curLine, "Package: "+curName, ""))
+		components = append(components, c)
+	}
 
-	for _, line := range strings.Split(string(data), "\n") {
+	for i, line := range strings.Split(string(data), "\n") {
 		line = strings.TrimSpace(line)
 		if line == "" {
 			// End of a stanza
 			if installed && curName != "" {
-				c := makeVcpkgComponent(curName, curVersion)
-				components = append(components, c)
+				add()
 			}
 			curName = ""
 			curVersion = ""
@@ -194,6 +256,7 @@ func parseVcpkgStatus(path string) []*model.Component {
 			curName = strings.TrimSpace(strings.TrimPrefix(line, "Package:"))
 			// Strip triplet: boost-system:x64-windows -> boost-system
 			curName = strings.SplitN(curName, ":", 2)[0]
+			curLine = i + 1
 		} else if strings.HasPrefix(line, "Version:") {
 			curVersion = strings.TrimSpace(strings.TrimPrefix(line, "Version:"))
 		} else if strings.HasPrefix(line, "Status:") && strings.Contains(line, "installed") {
@@ -202,8 +265,7 @@ func parseVcpkgStatus(path string) []*model.Component {
 	}
 	// Handle last stanza
 	if installed && curName != "" {
-		c := makeVcpkgComponent(curName, curVersion)
-		components = append(components, c)
+		add()
 	}
 
 	return components
@@ -0,0 +1,26 @@
+#!/bin/sh
+# fetch.sh — download the official schemas the validate package embeds,
+# unchanged, into the directory given as the first argument (default: the
+# directory of this script). Run through `go generate ./internal/validate`.
+#
+# Each schema is fetched over https from the location of its canonical $id,
+# which is also the key the loader in validate.go serves it under. SPDX 2.3
+# is published under an $id that does not resolve, so it comes from the
+# tagged spdx-spec release.
+
+set -eu
+
+dir=${1:-$(dirname "$0")}
+
+fetch() {
+	echo "fetching $2"
+	curl -fsSL -o "$dir/$1" "$2"
+}
+
+fetch bom-1.4.schema.json  https://cyclonedx.org/schema/bom-1.4.schema.json
+fetch bom-1.5.schema.json  https://cyclonedx.org/schema/bom-1.5.schema.json
+fetch bom-1.6.schema.json  https://cyclonedx.org/schema/bom-1.6.schema.json
+fetch spdx.schema.json     https://cyclonedx.org/schema/spdx.schema.json
+fetch jsf-0.82.schema.json https://cyclonedx.org/schema/jsf-0.82.schema.json
+fetch spdx-2.3.schema.json https://raw.githubusercontent.com/spdx/spdx-spec/v2.3/schemas/spdx-schema.json
+fetch spdx-3.0.1.schema.json https://spdx.org/schema/3.0.1/spdx-json-schema.json
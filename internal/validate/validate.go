@@ -0,0 +1,207 @@
+// Package validate checks SBOM documents against the CycloneDX and SPDX JSON
+// schemas. The schemas are embedded in the binary, so validation works
+// offline.
+package validate
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+//go:generate sh schemas/fetch.sh schemas
+
+//go:embed schemas/*.json
+var schemaFS embed.FS
+
+// schemaFiles maps the canonical $id of every schema the validator loads to
+// its file in schemas/. The CycloneDX schemas $ref spdx.schema.json and
+// jsf-0.82.schema.json relative to their own $id, so those two resolve here
+// as well.
+var schemaFiles = map[string]string{
+	"http://cyclonedx.org/schema/bom-1.4.schema.json":     "bom-1.4.schema.json",
+	"http://cyclonedx.org/schema/bom-1.5.schema.json":     "bom-1.5.schema.json",
+	"http://cyclonedx.org/schema/bom-1.6.schema.json":     "bom-1.6.schema.json",
+	"http://cyclonedx.org/schema/spdx.schema.json":        "spdx.schema.json",
+	"http://cyclonedx.org/schema/jsf-0.82.schema.json":    "jsf-0.82.schema.json",
+	"http://spdx.org/rdf/terms/2.3":                       "spdx-2.3.schema.json",
+	"https://spdx.org/schema/3.0.1/spdx-json-schema.json": "spdx-3.0.1.schema.json",
+}
+
+var printer = message.NewPrinter(language.English)
+
+// Report is the outcome of validating one document.
+type Report struct {
+	// Format names the detected document format, e.g. "CycloneDX 1.5".
+	Format string
+
+	// Schema is the $id of the schema the document was checked against.
+	Schema string
+
+	Problems []Problem
+}
+
+// Problem is one schema violation.
+type Problem struct {
+	// Location is a JSON pointer to the offending value; empty for the
+	// document root.
+	Location string
+	Message  string
+}
+
+func (p Problem) String() string {
+	loc := p.Location
+	if loc == "" {
+		loc = "/"
+	}
+	return loc + ": " + p.Message
+}
+
+// Valid reports whether the document had no schema violations.
+func (r *Report) Valid() bool {
+	return len(r.Problems) == 0
+}
+
+// File validates the SBOM at path.
+func File(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	r, err := Validate(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return r, nil
+}
+
+// Validate detects the format of a JSON SBOM and checks it against the
+// matching schema: CycloneDX 1.4, 1.5 or 1.6, SPDX 2.3, or SPDX 3.0. An error
+// is returned only when the document cannot be checked at all; schema
+// violations are listed in the report.
+func Validate(data []byte) (*Report, error) {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("<")) {
+		return nil, errors.New("XML documents are not supported; validate the JSON output instead")
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	format, id, err := detect(doc)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := compile(id)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Report{Format: format, Schema: id}
+	var verr *jsonschema.ValidationError
+	if err := schema.Validate(doc); errors.As(err, &verr) {
+		r.Problems = problems(verr)
+	} else if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// detect picks the schema for a parsed document and returns its $id.
+func detect(doc any) (format, id string, err error) {
+	obj, ok := doc.(map[string]any)
+	if !ok {
+		return "", "", errors.New("unrecognized document: expected a CycloneDX or SPDX JSON object")
+	}
+
+	if bomFormat, _ := obj["bomFormat"].(string); bomFormat == "CycloneDX" {
+		version, _ := obj["specVersion"].(string)
+		id = "http://cyclonedx.org/schema/bom-" + version + ".schema.json"
+		if _, ok := schemaFiles[id]; !ok {
+			return "", "", fmt.Errorf("unsupported CycloneDX specVersion %q (supported: 1.4, 1.5, 1.6)", version)
+		}
+		return "CycloneDX " + version, id, nil
+	}
+
+	if version, ok := obj["spdxVersion"].(string); ok {
+		if version != "SPDX-2.3" {
+			return "", "", fmt.Errorf("unsupported spdxVersion %q (supported: SPDX-2.3)", version)
+		}
+		return "SPDX 2.3", "http://spdx.org/rdf/terms/2.3", nil
+	}
+
+	if context, _ := obj["@context"].(string); strings.HasPrefix(context, "https://spdx.org/rdf/3.0") {
+		return "SPDX 3.0", "https://spdx.org/schema/3.0.1/spdx-json-schema.json", nil
+	}
+
+	return "", "", errors.New("unrecognized document: expected a CycloneDX or SPDX JSON object")
+}
+
+// compile compiles the schema with the given $id. Schemas, and the ones they
+// reference, are loaded by their canonical $id from the embedded files, so
+// validation never goes to the network.
+func compile(id string) (*jsonschema.Schema, error) {
+	c := jsonschema.NewCompiler()
+	c.AssertFormat()
+	c.UseLoader(embeddedLoader{})
+
+	schema, err := c.Compile(id)
+	if err != nil {
+		return nil, fmt.Errorf("embedded schema %s: %w", schemaFiles[id], err)
+	}
+	return schema, nil
+}
+
+// embeddedLoader is a jsonschema.URLLoader that serves schemaFiles.
+type embeddedLoader struct{}
+
+func (embeddedLoader) Load(url string) (any, error) {
+	file, ok := schemaFiles[url]
+	if !ok {
+		return nil, fmt.Errorf("schema %s is not embedded", url)
+	}
+	data, err := schemaFS.ReadFile(path.Join("schemas", file))
+	if err != nil {
+		return nil, fmt.Errorf("schema %s is not embedded: %w", url, err)
+	}
+	return jsonschema.UnmarshalJSON(bytes.NewReader(data))
+}
+
+// problems flattens a validation error into its leaf violations, sorted by
+// location. Intermediate results such as "allOf failed" are dropped, as each
+// is explained by the leaves beneath it.
+func problems(verr *jsonschema.ValidationError) []Problem {
+	seen := map[Problem]bool{}
+	var out []Problem
+	var walk func(e *jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) > 0 {
+			for _, cause := range e.Causes {
+				walk(cause)
+			}
+			return
+		}
+		p := Problem{Message: e.ErrorKind.LocalizedString(printer)}
+		for _, tok := range e.InstanceLocation {
+			tok = strings.ReplaceAll(tok, "~", "~0")
+			p.Location += "/" + strings.ReplaceAll(tok, "/", "~1")
+		}
+		if !seen[p] {
+			seen[p] = true
+			out = append(out, p)
+		}
+	}
+	walk(verr)
+
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Location < out[j].Location })
+	return out
+}
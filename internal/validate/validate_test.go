@@ -0,0 +1,198 @@
+package validate
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/StinkyLord/cpp-sbom-builder/internal/model"
+	"github.com/StinkyLord/cpp-sbom-builder/internal/output"
+	"github.com/StinkyLord/cpp-sbom-builder/internal/scanner"
+)
+
+// makeResult builds a scan result exercising every part of the documents the
+// output package writes: a project, direct and transitive libraries,
+// licenses, a homepage, hashed artifacts, build invocations and a
+// vulnerability.
+func makeResult() *scanner.Result {
+	digest := strings.Repeat("ab", 32)
+	openssl := &model.Component{
+		Name:            "openssl",
+		Version:         "3.1.4",
+		PURL:            "pkg:conan/openssl@3.1.4",
+		DetectionSource: "conan",
+		Description:     "OpenSSL cryptography library",
+		Licenses:        []string{"Apache-2.0"},
+		Homepage:        "https://www.openssl.org",
+		IsDirect:        true,
+		Dependencies:    []string{"zlib"},
+		Artifacts: []model.Artifact{{
+			Path: "/usr/lib/libssl.so.3", Size: 3,
+			Hashes: []model.Hash{{Algorithm: "SHA-256", Value: digest}},
+		}},
+	}
+	zlib := &model.Component{
+		Name:            "zlib",
+		Version:         "1.2.13",
+		PURL:            "pkg:conan/zlib@1.2.13",
+		DetectionSource: "linker-map",
+		Licenses:        []string{"Zlib", "Custom zlib-style license"},
+	}
+	fmtlib := &model.Component{
+		Name:            "fmt",
+		Version:         "unknown",
+		PURL:            "pkg:github/fmtlib/fmt",
+		DetectionSource: "header-scan",
+		IsDirect:        true,
+	}
+	components := []*model.Component{openssl, zlib, fmtlib}
+	return &scanner.Result{
+		Project: &model.Component{
+			Name: "app", Version: "1.0.0", PURL: "pkg:generic/app@1.0.0",
+			DetectionSource: "cmake", Dependencies: []string{"fmt", "openssl"},
+		},
+		Components:     components,
+		DependencyTree: model.BuildDependencyTree(components),
+		StrategiesUsed: []string{"conan", "linker-map", "header-scan"},
+		BuildInvocations: []model.BuildInvocation{{
+			Kind:       "link",
+			Arguments:  []string{"/usr/bin/c++", "main.o", "-o", "app", "-lssl"},
+			Directory:  "/src/build",
+			Output:     "app",
+			SourceFile: "/src/build/CMakeFiles/app.dir/link.txt",
+		}},
+		Vulnerabilities: []model.Vulnerability{{
+			ID:         "CVE-2023-0286",
+			Aliases:    []string{"GHSA-x4qr-2fvf-3mr5"},
+			Source:     "OSV",
+			Summary:    "X.400 address type confusion in X.509 GeneralName",
+			Ratings:    []model.Rating{{Method: "CVSSv31", Vector: "CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:H/I:N/A:H"}, {Severity: "high"}},
+			References: []string{"https://www.openssl.org/news/secadv/20230207.txt"},
+			Published:  "2023-02-08T20:15:00Z",
+			Modified:   "2023-03-01T00:00:00Z",
+			Affects: []model.AffectedComponent{
+				{BOMRef: openssl.BOMRef(), Name: "openssl", Version: "3.1.4", Fixed: []string{"3.1.5"}},
+			},
+		}},
+	}
+}
+
+func TestValidate_ToolOutput(t *testing.T) {
+	dir := t.TempDir()
+	write := map[string]func(string) error{}
+	for _, v := range output.SupportedSpecVersions {
+		opts := output.CycloneDXOptions{ToolVersion: "test", SpecVersion: v}
+		write["CycloneDX "+v] = func(p string) error { return output.WriteCycloneDX(makeResult(), p, opts) }
+	}
+	write["SPDX 2.3"] = func(p string) error {
+		return output.WriteSPDX(makeResult(), p, output.SPDXOptions{ToolVersion: "test", DocumentName: "app"})
+	}
+	write["SPDX 3.0"] = func(p string) error {
+		return output.WriteSPDX3(makeResult(), p, output.SPDX3Options{ToolVersion: "test", DocumentName: "app"})
+	}
+
+	for format, fn := range write {
+		t.Run(format, func(t *testing.T) {
+			path := filepath.Join(dir, strings.ReplaceAll(format, " ", "-")+".json")
+			if err := fn(path); err != nil {
+				t.Fatalf("write failed: %v", err)
+			}
+			r, err := File(path)
+			if err != nil {
+				t.Fatalf("File failed: %v", err)
+			}
+			if r.Format != format {
+				t.Errorf("Format = %q, want %q", r.Format, format)
+			}
+			if !r.Valid() {
+				t.Errorf("tool output is invalid: %v", r.Problems)
+			}
+		})
+	}
+}
+
+func TestValidate_Problems(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sbom.json")
+	opts := output.CycloneDXOptions{ToolVersion: "test", SpecVersion: "1.5"}
+	if err := output.WriteCycloneDX(makeResult(), path, opts); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	doc["serialNumber"] = "not-a-urn"
+	comps := doc["components"].([]any)
+	comps[0].(map[string]any)["type"] = "lib"
+	delete(comps[1].(map[string]any), "name")
+	data, _ = json.Marshal(doc)
+
+	r, err := Validate(data)
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if r.Valid() {
+		t.Fatal("expected problems")
+	}
+	want := []string{"/components/0/type", "/components/1", "/serialNumber"}
+	var got []string
+	for _, p := range r.Problems {
+		got = append(got, p.Location)
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("problem locations = %v, want %v", r.Problems, want)
+	}
+}
+
+func TestValidate_Unsupported(t *testing.T) {
+	cases := map[string]string{
+		"xml":          `<?xml version="1.0"?><bom xmlns="http://cyclonedx.org/schema/bom/1.5"/>`,
+		"not json":     `{"bomFormat":`,
+		"unknown":      `{"name": "x"}`,
+		"old cdx":      `{"bomFormat": "CycloneDX", "specVersion": "1.2"}`,
+		"old spdx":     `{"spdxVersion": "SPDX-2.2"}`,
+		"bare array":   `[]`,
+		"deptree only": `[{"name": "zlib"}]`,
+	}
+	for name, doc := range cases {
+		if _, err := Validate([]byte(doc)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+// TestSchemaIDs verifies that every embedded schema is registered under the
+// $id it declares, so references between the schemas resolve.
+func TestSchemaIDs(t *testing.T) {
+	entries, err := schemaFS.ReadDir("schemas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	byFile := map[string]string{}
+	for id, file := range schemaFiles {
+		byFile[file] = id
+	}
+	for _, e := range entries {
+		id, ok := byFile[e.Name()]
+		if !ok {
+			t.Errorf("%s is embedded but not in schemaFiles", e.Name())
+			continue
+		}
+		data, err := schemaFS.ReadFile("schemas/" + e.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var doc struct {
+			ID string `json:"$id"`
+		}
+		if err := json.Unmarshal(data, &doc); err != nil {
+			t.Fatalf("%s: %v", e.Name(), err)
+		}
+		if doc.ID != id {
+			t.Errorf("%s declares $id %q, registered as %q", e.Name(), doc.ID, id)
+		}
+	}
+}
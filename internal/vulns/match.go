@@ -0,0 +1,244 @@
+package vulns
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/StinkyLord/cpp-sbom-builder/internal/model"
+)
+
+// ecosystemTypes maps OSV ecosystems to the purl type the scanner gives
+// packages of that ecosystem, for advisories that name a package without a
+// purl.
+var ecosystemTypes = map[string]string{
+	"ConanCenter": "conan",
+}
+
+// Match returns the advisories that affect components, one Vulnerability per
+// advisory, sorted by ID.
+//
+// A component is matched by its purl, ignoring version and qualifiers, or,
+// for pkg:github purls, by the repository of an advisory's GIT ranges. It is
+// affected when its version is listed in the advisory's versions or falls in
+// one of its SEMVER or ECOSYSTEM ranges. Components without a known version
+// are never matched.
+func (db *DB) Match(components []*model.Component) []model.Vulnerability {
+	hits := map[*advisory][]model.AffectedComponent{}
+	for _, c := range components {
+		if c.Version == "" || c.Version == "unknown" || c.PURL == "" {
+			continue
+		}
+		matched := map[*advisory]bool{}
+		for _, e := range db.byPackage[purlBase(c.PURL)] {
+			if matched[e.advisory] {
+				continue
+			}
+			if ok, fixed := versionAffected(e.affected, c.Version); ok {
+				matched[e.advisory] = true
+				hits[e.advisory] = append(hits[e.advisory], model.AffectedComponent{
+					BOMRef:  c.BOMRef(),
+					Name:    c.Name,
+					Version: c.Version,
+					Fixed:   fixed,
+				})
+			}
+		}
+	}
+
+	var out []model.Vulnerability
+	for a, affects := range hits {
+		sort.Slice(affects, func(i, j int) bool { return affects[i].BOMRef < affects[j].BOMRef })
+		out = append(out, toVulnerability(a, affects))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// packageKeys returns the purl bases of the packages an affected entry
+// names: its purl, its ecosystem and name, or, failing both, the pkg:github
+// purls of the repositories of its GIT ranges.
+func packageKeys(aff *affected) []string {
+	if aff.Package.PURL != "" {
+		return []string{purlBase(aff.Package.PURL)}
+	}
+	if t, ok := ecosystemTypes[aff.Package.Ecosystem]; ok && aff.Package.Name != "" {
+		return []string{"pkg:" + t + "/" + strings.ToLower(aff.Package.Name)}
+	}
+	var keys []string
+	for _, r := range aff.Ranges {
+		if r.Type != "GIT" {
+			continue
+		}
+		if repo, ok := strings.CutPrefix(repoPath(r.Repo), "github.com/"); ok {
+			if key := "pkg:github/" + repo; !contains(keys, key) {
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// purlBase returns the lower-cased purl without version, qualifiers and
+// subpath: "pkg:conan/openssl@3.1.4?channel=stable" → "pkg:conan/openssl".
+func purlBase(purl string) string {
+	purl, _, _ = strings.Cut(purl, "#")
+	purl, _, _ = strings.Cut(purl, "?")
+	purl, _, _ = strings.Cut(purl, "@")
+	return strings.ToLower(purl)
+}
+
+// repoPath normalizes a repository URL to host/owner/name.
+func repoPath(url string) string {
+	url = strings.ToLower(url)
+	for _, prefix := range []string{"https://", "http://", "git://", "ssh://git@", "git@"} {
+		url = strings.TrimPrefix(url, prefix)
+	}
+	url = strings.Replace(url, ":", "/", 1)
+	url = strings.TrimSuffix(url, "/")
+	return strings.TrimSuffix(url, ".git")
+}
+
+// versionAffected reports whether version is affected by aff, and returns
+// the versions that fix it, as far as the advisory's ranges say.
+func versionAffected(aff *affected, version string) (bool, []string) {
+	hit := false
+	for _, v := range aff.Versions {
+		if model.CompareVersions(v, version) == 0 {
+			hit = true
+			break
+		}
+	}
+
+	var fixed []string
+	for _, r := range aff.Ranges {
+		if r.Type != "SEMVER" && r.Type != "ECOSYSTEM" {
+			continue
+		}
+		if rangeAffects(r.Events, version) {
+			hit = true
+		}
+		for _, e := range r.Events {
+			if e.Fixed != "" && model.CompareVersions(e.Fixed, version) > 0 && !contains(fixed, e.Fixed) {
+				fixed = append(fixed, e.Fixed)
+			}
+		}
+	}
+	if !hit {
+		return false, nil
+	}
+	sort.Slice(fixed, func(i, j int) bool { return model.CompareVersions(fixed[i], fixed[j]) < 0 })
+	return true, fixed
+}
+
+// rangeAffects evaluates the events of an OSV range against version, as the
+// OSV schema prescribes: events are applied in version order, each one at or
+// below version setting whether it is affected.
+func rangeAffects(events []osvEvent, version string) bool {
+	sorted := make([]osvEvent, len(events))
+	copy(sorted, events)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return model.CompareVersions(eventVersion(sorted[i]), eventVersion(sorted[j])) < 0
+	})
+
+	affected := false
+	for _, e := range sorted {
+		switch {
+		case e.Introduced != "":
+			if e.Introduced == "0" || model.CompareVersions(version, e.Introduced) >= 0 {
+				affected = true
+			}
+		case e.Fixed != "":
+			if model.CompareVersions(version, e.Fixed) >= 0 {
+				affected = false
+			}
+		case e.LastAffected != "":
+			if model.CompareVersions(version, e.LastAffected) > 0 {
+				affected = false
+			}
+		case e.Limit != "":
+			if model.CompareVersions(version, e.Limit) >= 0 {
+				affected = false
+			}
+		}
+	}
+	return affected
+}
+
+func eventVersion(e osvEvent) string {
+	switch {
+	case e.Introduced != "":
+		return e.Introduced
+	case e.Fixed != "":
+		return e.Fixed
+	case e.LastAffected != "":
+		return e.LastAffected
+	}
+	return e.Limit
+}
+
+// toVulnerability converts an advisory and the components it affects.
+func toVulnerability(a *advisory, affects []model.AffectedComponent) model.Vulnerability {
+	v := model.Vulnerability{
+		ID:        a.ID,
+		Aliases:   a.Aliases,
+		Source:    "OSV",
+		Summary:   a.Summary,
+		Details:   a.Details,
+		Published: a.Published,
+		Modified:  a.Modified,
+		Affects:   affects,
+	}
+	for _, s := range a.Severity {
+		v.Ratings = append(v.Ratings, model.Rating{Method: ratingMethod(s.Type, s.Score), Vector: s.Score})
+	}
+	if sev := severityLevel(a.DatabaseSpecific.Severity); sev != "" {
+		v.Ratings = append(v.Ratings, model.Rating{Severity: sev})
+	}
+	for _, r := range a.References {
+		if r.URL != "" && !contains(v.References, r.URL) {
+			v.References = append(v.References, r.URL)
+		}
+	}
+	return v
+}
+
+// ratingMethod maps an OSV severity type to a CycloneDX rating method.
+func ratingMethod(osvType, score string) string {
+	switch osvType {
+	case "CVSS_V2":
+		return "CVSSv2"
+	case "CVSS_V3":
+		if strings.HasPrefix(score, "CVSS:3.1/") {
+			return "CVSSv31"
+		}
+		return "CVSSv3"
+	case "CVSS_V4":
+		return "CVSSv4"
+	}
+	return "other"
+}
+
+// severityLevel maps the severity label some databases (GitHub, among
+// others) attach to a CycloneDX severity.
+func severityLevel(label string) string {
+	switch strings.ToLower(label) {
+	case "critical":
+		return "critical"
+	case "high":
+		return "high"
+	case "moderate", "medium":
+		return "medium"
+	case "low":
+		return "low"
+	}
+	return ""
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+// Package vulns matches components against a local export of the OSV
+// vulnerability database (https://osv.dev), so known advisories can be
+// reported on machines without network access.
+package vulns
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// advisory is one OSV record, reduced to the fields matching and reporting
+// use. See https://ossf.github.io/osv-schema/.
+type advisory struct {
+	ID        string   `json:"id"`
+	Aliases   []string `json:"aliases"`
+	Summary   string   `json:"summary"`
+	Details   string   `json:"details"`
+	Published string   `json:"published"`
+	Modified  string   `json:"modified"`
+	Withdrawn string   `json:"withdrawn"`
+	Severity  []struct {
+		Type  string `json:"type"`
+		Score string `json:"score"`
+	} `json:"severity"`
+	Affected   []affected `json:"affected"`
+	References []struct {
+		Type string `json:"type"`
+		URL  string `json:"url"`
+	} `json:"references"`
+	DatabaseSpecific struct {
+		Severity string `json:"severity"`
+	} `json:"database_specific"`
+}
+
+type affected struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+		PURL      string `json:"purl"`
+	} `json:"package"`
+	Ranges   []osvRange `json:"ranges"`
+	Versions []string   `json:"versions"`
+}
+
+type osvRange struct {
+	Type   string     `json:"type"` // "SEMVER", "ECOSYSTEM" or "GIT"
+	Repo   string     `json:"repo"`
+	Events []osvEvent `json:"events"`
+}
+
+type osvEvent struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+	Limit        string `json:"limit,omitempty"`
+}
+
+// DB is a loaded OSV export.
+type DB struct {
+	advisories []*advisory
+	// byPackage indexes the affected entries of the advisories by the purl
+	// base of the package they name, so Match looks each component up once.
+	byPackage map[string][]packageEntry
+}
+
+// packageEntry is one affected entry of an advisory.
+type packageEntry struct {
+	advisory *advisory
+	affected *affected
+}
+
+// Len returns the number of advisories in the database.
+func (db *DB) Len() int { return len(db.advisories) }
+
+// Load reads an OSV export from path: a directory of advisory JSON files, a
+// zip archive of them as published at
+// https://osv-vulnerabilities.storage.googleapis.com/<ecosystem>/all.zip, or
+// a directory holding several such archives. Withdrawn advisories are
+// dropped.
+func Load(path string) (*DB, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open vulnerability database: %w", err)
+	}
+
+	db := &DB{}
+	if !info.IsDir() {
+		if err := db.loadZip(path); err != nil {
+			return nil, err
+		}
+		return db, nil
+	}
+
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(p)) {
+		case ".json":
+			data, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			return db.add(p, data)
+		case ".zip":
+			return db.loadZip(p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+func (db *DB) loadZip(path string) error {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return fmt.Errorf("cannot open vulnerability archive %s: %w", path, err)
+	}
+	defer zr.Close()
+
+	for _, f := range zr.File {
+		if !strings.EqualFold(filepath.Ext(f.Name), ".json") {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("%s: %s: %w", path, f.Name, err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %s: %w", path, f.Name, err)
+		}
+		if err := db.add(path+":"+f.Name, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// add parses one advisory. Records without an ID or affected packages are
+// not OSV advisories and are skipped.
+func (db *DB) add(name string, data []byte) error {
+	var a advisory
+	if err := json.Unmarshal(data, &a); err != nil {
+		return fmt.Errorf("%s: invalid OSV record: %w", name, err)
+	}
+	if a.ID == "" || len(a.Affected) == 0 || a.Withdrawn != "" {
+		return nil
+	}
+	db.advisories = append(db.advisories, &a)
+	if db.byPackage == nil {
+		db.byPackage = map[string][]packageEntry{}
+	}
+	for i := range a.Affected {
+		for _, key := range packageKeys(&a.Affected[i]) {
+			db.byPackage[key] = append(db.byPackage[key], packageEntry{&a, &a.Affected[i]})
+		}
+	}
+	return nil
+}
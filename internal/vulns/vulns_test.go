@@ -0,0 +1,179 @@
+package vulns
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/StinkyLord/cpp-sbom-builder/internal/model"
+)
+
+// Advisories in the shapes the OSV exports use: a purl with an ECOSYSTEM
+// range, an ecosystem name without a purl, and a GIT range with tagged
+// versions, as OSS-Fuzz and GitHub advisories for C/C++ libraries carry.
+var testAdvisories = map[string]string{
+	"CVE-2023-0286.json": `{
+		"id": "CVE-2023-0286",
+		"aliases": ["GHSA-x4qr-2fvf-3mr5"],
+		"summary": "X.400 address type confusion in X.509 GeneralName",
+		"published": "2023-02-08T20:15:00Z",
+		"modified": "2023-03-01T00:00:00Z",
+		"severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:H/I:N/A:H"}],
+		"affected": [{
+			"package": {"ecosystem": "ConanCenter", "name": "openssl", "purl": "pkg:conan/openssl"},
+			"ranges": [{"type": "ECOSYSTEM", "events": [
+				{"introduced": "3.0.0"}, {"fixed": "3.0.8"},
+				{"introduced": "1.1.1"}, {"fixed": "1.1.1t"}
+			]}]
+		}],
+		"references": [{"type": "ADVISORY", "url": "https://www.openssl.org/news/secadv/20230207.txt"}],
+		"database_specific": {"severity": "HIGH"}
+	}`,
+	"zlib.json": `{
+		"id": "OSV-2022-0001",
+		"affected": [{
+			"package": {"ecosystem": "ConanCenter", "name": "ZLIB"},
+			"ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"last_affected": "1.2.12"}]}]
+		}]
+	}`,
+	"fmt.json": `{
+		"id": "OSV-2021-0002",
+		"affected": [{
+			"package": {"ecosystem": "OSS-Fuzz", "name": "fmt"},
+			"ranges": [{"type": "GIT", "repo": "https://github.com/fmtlib/fmt.git", "events": [{"introduced": "abc"}, {"fixed": "def"}]}],
+			"versions": ["7.1.0", "v7.1.2"]
+		}]
+	}`,
+	"withdrawn.json": `{
+		"id": "OSV-2020-0003",
+		"withdrawn": "2020-06-01T00:00:00Z",
+		"affected": [{"package": {"ecosystem": "ConanCenter", "name": "openssl"}, "versions": ["3.0.5"]}]
+	}`,
+	"not-an-advisory.json": `{"name": "index"}`,
+}
+
+func writeAdvisoryDir(t *testing.T) string {
+	dir := t.TempDir()
+	for name, data := range testAdvisories {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func writeAdvisoryZip(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "all.zip")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for name, data := range testAdvisories {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(data))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	return path
+}
+
+func TestLoad_DirectoryAndZip(t *testing.T) {
+	for name, path := range map[string]string{"dir": writeAdvisoryDir(t), "zip": writeAdvisoryZip(t)} {
+		db, err := Load(path)
+		if err != nil {
+			t.Fatalf("%s: Load failed: %v", name, err)
+		}
+		// The withdrawn record and the non-advisory are dropped.
+		if db.Len() != 3 {
+			t.Errorf("%s: Len() = %d, want 3", name, db.Len())
+		}
+		// Each advisory is indexed under the package it names: by purl,
+		// by ecosystem and name, or by the GitHub repository of a GIT range.
+		for _, key := range []string{"pkg:conan/openssl", "pkg:conan/zlib", "pkg:github/fmtlib/fmt"} {
+			if len(db.byPackage[key]) != 1 {
+				t.Errorf("%s: %d advisories indexed under %s, want 1", name, len(db.byPackage[key]), key)
+			}
+		}
+		if len(db.byPackage) != 3 {
+			t.Errorf("%s: index has %d packages, want 3", name, len(db.byPackage))
+		}
+	}
+}
+
+func TestLoad_InvalidJSON(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "bad.json"), []byte(`{"id":`), 0644)
+	if _, err := Load(dir); err == nil || !strings.Contains(err.Error(), "bad.json") {
+		t.Errorf("Load error = %v, want one naming bad.json", err)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	db, err := Load(writeAdvisoryDir(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	comp := func(name, version, purl string) *model.Component {
+		return &model.Component{Name: name, Version: version, PURL: purl}
+	}
+	components := []*model.Component{
+		comp("openssl", "3.0.5", "pkg:conan/openssl@3.0.5?channel=stable"),
+		comp("openssl", "1.1.1s", "pkg:conan/openssl@1.1.1s"),
+		comp("zlib", "1.2.12", "pkg:conan/zlib@1.2.12"),
+		comp("fmt", "7.1.2", "pkg:github/fmtlib/fmt@7.1.2"),
+		// Not affected: fixed version, past last_affected, unknown version,
+		// other ecosystem.
+		comp("openssl", "3.0.8", "pkg:conan/openssl@3.0.8"),
+		comp("zlib", "1.2.13", "pkg:conan/zlib@1.2.13"),
+		comp("fmt", "unknown", "pkg:github/fmtlib/fmt"),
+		comp("openssl", "3.0.5", "pkg:generic/openssl@3.0.5"),
+	}
+
+	got := map[string][]string{}
+	var openssl model.Vulnerability
+	for _, v := range db.Match(components) {
+		for _, a := range v.Affects {
+			got[v.ID] = append(got[v.ID], a.BOMRef)
+		}
+		if v.ID == "CVE-2023-0286" {
+			openssl = v
+		}
+	}
+	want := map[string][]string{
+		"CVE-2023-0286": {"openssl@1.1.1s", "openssl@3.0.5"},
+		"OSV-2021-0002": {"fmt@7.1.2"},
+		"OSV-2022-0001": {"zlib@1.2.12"},
+	}
+	for id, refs := range want {
+		if strings.Join(got[id], " ") != strings.Join(refs, " ") {
+			t.Errorf("%s affects %v, want %v", id, got[id], refs)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("matched %v, want %v", got, want)
+	}
+
+	if len(openssl.Affects) == 2 {
+		if f := openssl.Affects[0].Fixed; len(f) != 2 || f[0] != "1.1.1t" {
+			t.Errorf("fixed versions for 1.1.1s = %v, want [1.1.1t 3.0.8]", f)
+		}
+		if f := openssl.Affects[1].Fixed; len(f) != 1 || f[0] != "3.0.8" {
+			t.Errorf("fixed versions for 3.0.5 = %v, want [3.0.8]", f)
+		}
+	}
+	wantRatings := []model.Rating{
+		{Method: "CVSSv31", Vector: "CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:H/I:N/A:H"},
+		{Severity: "high"},
+	}
+	if len(openssl.Ratings) != 2 || openssl.Ratings[0] != wantRatings[0] || openssl.Ratings[1] != wantRatings[1] {
+		t.Errorf("ratings = %+v, want %+v", openssl.Ratings, wantRatings)
+	}
+}